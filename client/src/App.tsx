@@ -16,6 +16,7 @@ import Chat from './pages/Chat'
 import Settings from "./pages/Settings"
 import OAuthCallback from './pages/OAuthCallback'
 import ForgotPassword from './pages/ForgotPassword'
+import VerifyEmail from './pages/VerifyEmail'
 
 const App = () => {
   const location = useLocation();
@@ -47,8 +48,8 @@ const App = () => {
     };
   }, [location.pathname]);
   
-  // Routes where we should NOT show navbar/footer (login/register only)
-  const authPages = ['/login', '/register'];
+  // Routes where we should NOT show navbar/footer (login/register/verify)
+  const authPages = ['/login', '/register', '/verify-email'];
   const isAuthPage = authPages.includes(location.pathname);
   
   // Routes that show sidebar (protected routes)
@@ -94,6 +95,7 @@ const App = () => {
           <Route path='/login' element={<Login />} />
           <Route path='/auth/callback' element={<OAuthCallback />} />
           <Route path='/forgot-password' element={<ForgotPassword />} />
+          <Route path='/verify-email' element={<VerifyEmail />} />
 
           <Route
             path='/dashboard'
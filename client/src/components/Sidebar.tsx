@@ -12,6 +12,7 @@ import {
 } from "react-icons/fi";
 import { useNavigate, useLocation } from "react-router-dom";
 import clsx from "clsx";
+import { clearSession } from "@/lib/auth";
 
 interface SidebarProps {
   collapsed?: boolean;
@@ -32,8 +33,7 @@ const Sidebar: React.FC<SidebarProps> = ({ collapsed: externalCollapsed, setColl
 
   const handleLogout = () => {
     if (window.confirm("Are you sure you want to log out?")) {
-      localStorage.removeItem("token");
-      window.dispatchEvent(new Event('auth-change'));
+      clearSession();
       navigate("/");
     }
   };
@@ -0,0 +1,155 @@
+import axios, { type AxiosError, type InternalAxiosRequestConfig } from "axios";
+
+export const API_URL = import.meta.env.VITE_API_URL || 'http://26.176.162.130:8080';
+
+const TOKEN_KEY = "token";
+const REFRESH_TOKEN_KEY = "refresh_token";
+
+// Kept for requests the interceptors below must not retry
+const originalFetch = window.fetch.bind(window);
+
+type SessionTokens = {
+  token: string;
+  refresh_token?: string;
+};
+
+// saveSession stores the tokens from a login, registration or refresh
+export function saveSession({ token, refresh_token }: SessionTokens): void {
+  localStorage.setItem(TOKEN_KEY, token);
+  if (refresh_token) {
+    localStorage.setItem(REFRESH_TOKEN_KEY, refresh_token);
+  }
+  window.dispatchEvent(new Event("auth-change"));
+}
+
+// clearSession forgets the tokens locally and revokes the session on the
+// server when there's still a token to do it with
+export function clearSession(): void {
+  const token = localStorage.getItem(TOKEN_KEY);
+  localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
+  window.dispatchEvent(new Event("auth-change"));
+
+  if (token) {
+    originalFetch(`${API_URL}/users/logout`, {
+      method: "POST",
+      headers: { Authorization: `Bearer ${token}` },
+    }).catch(() => {});
+  }
+}
+
+// Only one refresh runs at a time: refresh tokens rotate, so a second
+// request with the same one would be treated as reuse and end the session
+let refreshing: Promise<string | null> | null = null;
+
+function refreshAccessToken(): Promise<string | null> {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY);
+      if (!refreshToken) {
+        return null;
+      }
+      try {
+        const res = await originalFetch(`${API_URL}/auth/refresh`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ refresh_token: refreshToken }),
+        });
+        if (!res.ok) {
+          return null;
+        }
+        const data: SessionTokens = await res.json();
+        saveSession(data);
+        return data.token;
+      } catch {
+        return null;
+      }
+    })().finally(() => {
+      refreshing = null;
+    });
+  }
+  return refreshing;
+}
+
+function endExpiredSession(): void {
+  localStorage.removeItem(TOKEN_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
+  window.dispatchEvent(new Event("auth-change"));
+  if (window.location.pathname !== "/login") {
+    window.location.assign("/login");
+  }
+}
+
+function isApiRequest(url: string): boolean {
+  return url.startsWith(API_URL) && !url.startsWith(`${API_URL}/auth/refresh`);
+}
+
+// withCurrentToken swaps a bearer header for the latest stored token, since
+// pages read the token once and it may have been refreshed since
+function withCurrentToken(authorization: string | null | undefined): string | null {
+  const token = localStorage.getItem(TOKEN_KEY);
+  if (!authorization || !authorization.startsWith("Bearer ") || !token) {
+    return null;
+  }
+  return `Bearer ${token}`;
+}
+
+type RetriableConfig = InternalAxiosRequestConfig & { _retried?: boolean };
+
+// setupAuthRefresh makes every authenticated API call, through axios or
+// fetch, use the current access token and, when it has expired, rotate the
+// refresh token and retry once. A failed refresh sends the user to login.
+export function setupAuthRefresh(): void {
+  axios.interceptors.request.use((config) => {
+    const authorization = withCurrentToken(config.headers?.get?.("Authorization") as string | undefined);
+    if (authorization && config.url && isApiRequest(config.url)) {
+      config.headers.set("Authorization", authorization);
+    }
+    return config;
+  });
+
+  axios.interceptors.response.use(undefined, async (error: AxiosError) => {
+    const config = error.config as RetriableConfig | undefined;
+    const sentToken = config?.headers?.get?.("Authorization");
+    if (error.response?.status !== 401 || !config || config._retried || !sentToken || !config.url || !isApiRequest(config.url)) {
+      return Promise.reject(error);
+    }
+
+    const token = await refreshAccessToken();
+    if (!token) {
+      endExpiredSession();
+      return Promise.reject(error);
+    }
+
+    config._retried = true;
+    config.headers.set("Authorization", `Bearer ${token}`);
+    return axios.request(config);
+  });
+
+  window.fetch = async (input: RequestInfo | URL, init?: RequestInit): Promise<Response> => {
+    const url = input instanceof Request ? input.url : input.toString();
+    if (!isApiRequest(url)) {
+      return originalFetch(input, init);
+    }
+
+    const headers = new Headers(init?.headers ?? (input instanceof Request ? input.headers : undefined));
+    const authorization = withCurrentToken(headers.get("Authorization"));
+    if (authorization) {
+      headers.set("Authorization", authorization);
+    }
+
+    const res = await originalFetch(input, { ...init, headers });
+    if (res.status !== 401 || !authorization) {
+      return res;
+    }
+
+    const token = await refreshAccessToken();
+    if (!token) {
+      endExpiredSession();
+      return res;
+    }
+
+    headers.set("Authorization", `Bearer ${token}`);
+    return originalFetch(input, { ...init, headers });
+  };
+}
@@ -0,0 +1,83 @@
+// Server-Sent Events fallback for networks that block WebSockets. The server
+// names each event after its hub type, so every type the page handles has to
+// be listed here for EventSource to deliver it.
+const EVENT_TYPES = [
+  "new_message",
+  "message_edited",
+  "message_deleted",
+  "chat_deleted",
+  "user_status",
+  "typing",
+  "stop_typing",
+  "account_warning",
+  "report_resolved",
+  "error",
+];
+
+const RECONNECT_DELAY_MS = 3000;
+
+// openEventStream subscribes to the event stream under apiUrl and calls
+// onEvent with each parsed event. EventSource can't send the bearer token, so
+// every connection first fetches a one-time ticket. Returns a function that
+// closes the stream.
+export function openEventStream<T>(apiUrl: string, onEvent: (data: T) => void): () => void {
+  let source: EventSource | null = null;
+  let reconnectTimer: number | undefined;
+  let closed = false;
+
+  const handle = (event: MessageEvent) => {
+    try {
+      onEvent(JSON.parse(event.data) as T);
+    } catch (error) {
+      console.error("❌ Error parsing event:", error);
+    }
+  };
+
+  const scheduleReconnect = () => {
+    if (closed) return;
+    window.clearTimeout(reconnectTimer);
+    reconnectTimer = window.setTimeout(connect, RECONNECT_DELAY_MS);
+  };
+
+  async function connect() {
+    if (closed) return;
+
+    const token = localStorage.getItem("token");
+    if (!token) return;
+
+    try {
+      const response = await fetch(`${apiUrl}/events/ticket`, {
+        method: "POST",
+        headers: { Authorization: `Bearer ${token}` },
+      });
+      if (!response.ok) {
+        scheduleReconnect();
+        return;
+      }
+      const { ticket } = await response.json();
+      if (closed) return;
+
+      source = new EventSource(`${apiUrl}/events?ticket=${encodeURIComponent(ticket)}`);
+      EVENT_TYPES.forEach((type) => source?.addEventListener(type, handle as EventListener));
+      source.onopen = () => console.log("✅ Event stream connected");
+      source.onerror = () => {
+        // Tickets work once, so EventSource's own retry would be refused;
+        // reconnect with a fresh ticket instead
+        source?.close();
+        source = null;
+        scheduleReconnect();
+      };
+    } catch (error) {
+      console.error("❌ Event stream failed:", error);
+      scheduleReconnect();
+    }
+  }
+
+  connect();
+
+  return () => {
+    closed = true;
+    window.clearTimeout(reconnectTimer);
+    source?.close();
+  };
+}
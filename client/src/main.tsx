@@ -4,9 +4,12 @@ import './index.css'
 import App from './App.tsx'
 import { BrowserRouter } from "react-router-dom"
 import { GoogleOAuthProvider } from '@react-oauth/google'
+import { setupAuthRefresh } from './lib/auth'
 
 const GOOGLE_CLIENT_ID = import.meta.env.VITE_GOOGLE_CLIENT_ID || ''
 
+setupAuthRefresh()
+
 ReactDOM.createRoot(document.getElementById('root')!).render(
   <React.StrictMode>
     <GoogleOAuthProvider clientId={GOOGLE_CLIENT_ID}>
@@ -8,6 +8,7 @@ import {
   FiCornerUpLeft,
   FiCopy,
 } from "react-icons/fi";
+import { openEventStream } from "@/lib/events";
 
 interface Message {
   id: number;
@@ -65,6 +66,7 @@ const Chat: React.FC = () => {
   const [messages, setMessages] = useState<Message[]>([]);
   const [currentUser, setCurrentUser] = useState<User | null>(null);
   const [ws, setWs] = useState<WebSocket | null>(null);
+  const [usingEventStream, setUsingEventStream] = useState(false);
   const [isTyping, setIsTyping] = useState(false);
   const [typingUsers, setTypingUsers] = useState<{ [key: number]: boolean }>({});
   const [contextMenu, setContextMenu] = useState<ContextMenu | null>(null);
@@ -163,16 +165,20 @@ const Chat: React.FC = () => {
     if (!token) return;
 
     console.log("🔌 Creating WebSocket connection...");
-    const websocket = new WebSocket(`${WS_URL}?token=${token}`);
+    // Token goes in the subprotocol list so it never appears in URLs or access logs
+    const websocket = new WebSocket(WS_URL, ["access_token", token]);
     let isCleaningUp = false;
+    let opened = false;
+    let stopEventStream: (() => void) | null = null;
 
     websocket.onopen = () => {
+      opened = true;
       console.log("✅ WebSocket connected");
     };
 
-    websocket.onmessage = (event) => {
+    // Shared by the WebSocket and the Server-Sent Events fallback
+    const handleEvent = (data: any) => {
       try {
-        const data = JSON.parse(event.data);
         console.log("📨 Received:", data.type);
         
         if (data.type === "error") {
@@ -269,6 +275,14 @@ const Chat: React.FC = () => {
             setMessages([]);
           }
         }
+      } catch (error) {
+        console.error("❌ Error handling event:", error);
+      }
+    };
+
+    websocket.onmessage = (event) => {
+      try {
+        handleEvent(JSON.parse(event.data));
       } catch (error) {
         console.error("❌ Error parsing WebSocket message:", error);
       }
@@ -285,6 +299,15 @@ const Chat: React.FC = () => {
         console.log("✅ Intentional close");
         return;
       }
+
+      // Never connected: something between us and the server blocks
+      // WebSockets, so receive over Server-Sent Events and send over HTTP
+      if (!opened) {
+        console.log("⚠️ WebSocket unavailable, falling back to Server-Sent Events");
+        stopEventStream = openEventStream(API_URL, handleEvent);
+        setUsingEventStream(true);
+        return;
+      }
       
       // Reconnect on unexpected close
       if (event.code !== 1000 && event.code !== 1001) {
@@ -309,6 +332,7 @@ const Chat: React.FC = () => {
       if (reconnectTimeoutRef.current) {
         clearTimeout(reconnectTimeoutRef.current);
       }
+      stopEventStream?.();
       if (websocket.readyState === WebSocket.OPEN || websocket.readyState === WebSocket.CONNECTING) {
         websocket.close(1000, "Cleanup");
       }
@@ -480,8 +504,9 @@ const Chat: React.FC = () => {
   };
 
   const handleSendMessage = async () => {
-    if (!messageInput.trim() || !selectedChat || !ws || ws.readyState !== WebSocket.OPEN || !currentUser) {
-      if (!ws || ws.readyState !== WebSocket.OPEN) {
+    const wsOpen = !!ws && ws.readyState === WebSocket.OPEN;
+    if (!messageInput.trim() || !selectedChat || (!wsOpen && !usingEventStream) || !currentUser) {
+      if (!wsOpen && !usingEventStream) {
         console.error("❌ Cannot send: WebSocket not connected. State:", ws?.readyState);
         alert("Connection lost. Please refresh the page.");
       }
@@ -538,6 +563,33 @@ const Chat: React.FC = () => {
     setIsTyping(false);
     setReplyingTo(null);
 
+    if (!wsOpen || !ws) {
+      // Event stream fallback: the server echoes the message back as new_message
+      const token = localStorage.getItem("token");
+      try {
+        const response = await fetch(`${API_URL}/messages`, {
+          method: "POST",
+          headers: {
+            "Content-Type": "application/json",
+            Authorization: `Bearer ${token}`,
+          },
+          body: JSON.stringify({
+            receiver_id: messageData.receiver_id,
+            content: messageData.content,
+            reply_to_id: messageData.reply_to_id,
+          }),
+        });
+        if (!response.ok) {
+          throw new Error(`status ${response.status}`);
+        }
+      } catch (error) {
+        console.error("❌ Failed to send message:", error);
+        setMessages((prev) => prev.filter(m => m.id !== optimisticMessage.id));
+        alert("Failed to send message. Please check your connection and try again.");
+      }
+      return;
+    }
+
     try {
       ws.send(JSON.stringify({
         type: "stop_typing",
@@ -40,6 +40,7 @@ interface PostCardProps {
   comments?: Comment[];
   onToggleComments: (postId: number) => void;
   currentUserId: number | null;
+  canModerate: boolean;
   commentText: string;
   onCommentChange: (e: React.ChangeEvent<HTMLInputElement>) => void;
   onCommentSubmit: (postId: number) => void;
@@ -65,6 +66,7 @@ const PostCard = memo<PostCardProps>(({
   comments,
   onToggleComments,
   currentUserId,
+  canModerate,
   commentText,
   onCommentChange,
   onCommentSubmit,
@@ -202,14 +204,16 @@ const PostCard = memo<PostCardProps>(({
                         <span className="font-semibold text-sm text-gray-900">{comment.user?.username}</span>
                         <span className="text-xs text-gray-500">{formatTimeAgo(comment.created_at)}</span>
                       </div>
-                      {currentUserId === comment.user_id && (
+                      {(currentUserId === comment.user_id || canModerate) && (
                         <div className="flex items-center gap-2">
-                          <button 
-                            onClick={() => onEditComment(comment.id, comment.content)}
-                            className="text-blue-500 hover:text-blue-700"
-                          >
-                            <FiEdit2 className="w-4 h-4" />
-                          </button>
+                          {currentUserId === comment.user_id && (
+                            <button 
+                              onClick={() => onEditComment(comment.id, comment.content)}
+                              className="text-blue-500 hover:text-blue-700"
+                            >
+                              <FiEdit2 className="w-4 h-4" />
+                            </button>
+                          )}
                           <button 
                             onClick={() => onDeleteComment(comment.id, post.id)}
                             className="text-red-500 hover:text-red-700"
@@ -275,6 +279,7 @@ const Dashboard: React.FC = () => {
   const [comments, setComments] = useState<{ [key: number]: Comment[] }>({});
   const [showComments, setShowComments] = useState<{ [key: number]: boolean }>({});
   const [currentUserId, setCurrentUserId] = useState<number | null>(null);
+  const [canModerate, setCanModerate] = useState<boolean>(false);
   const [editingCommentId, setEditingCommentId] = useState<number | null>(null);
   const [editCommentText, setEditCommentText] = useState("");
   const [showShareModal, setShowShareModal] = useState<number | null>(null);
@@ -300,6 +305,7 @@ const Dashboard: React.FC = () => {
       });
       const data = await res.json();
       setCurrentUserId(data.user.id);
+      setCanModerate((data.user.permissions || []).includes("content:moderate"));
     } catch (err) {
       console.error("Failed to fetch current user:", err);
     }
@@ -662,6 +668,7 @@ const Dashboard: React.FC = () => {
                   comments={comments[post.id]}
                   onToggleComments={toggleComments}
                   currentUserId={currentUserId}
+                  canModerate={canModerate}
                   commentText={commentingOn === post.id ? commentText : ""}
                   onCommentChange={(e) => {
                     setCommentingOn(post.id);
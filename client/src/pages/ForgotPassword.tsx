@@ -79,8 +79,22 @@ const ForgotPassword: React.FC = () => {
       return;
     }
 
-    if (newPassword.length < 6) {
-      setError("Password must be at least 6 characters");
+    if (newPassword.length < 8) {
+      setError("Password must be at least 8 characters");
+      return;
+    }
+
+    if (newPassword.length > 72) {
+      setError("Password must not exceed 72 characters");
+      return;
+    }
+
+    const hasUpper = /[A-Z]/.test(newPassword);
+    const hasLower = /[a-z]/.test(newPassword);
+    const hasDigit = /[0-9]/.test(newPassword);
+
+    if (!hasUpper || !hasLower || !hasDigit) {
+      setError("Password must contain uppercase, lowercase, and numbers");
       return;
     }
 
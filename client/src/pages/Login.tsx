@@ -3,11 +3,12 @@ import { Button } from "@/components/ui/button";
 import { Card, CardContent, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
 import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
-import { useNavigate } from "react-router-dom";
+import { useLocation, useNavigate } from "react-router-dom";
 import { toast } from "react-toastify";
 import axios, { AxiosError } from "axios";
-import { useGoogleLogin } from '@react-oauth/google';
+import { GoogleLogin, type CredentialResponse } from '@react-oauth/google';
 import ReCAPTCHA from 'react-google-recaptcha';
+import { saveSession } from "@/lib/auth";
 
 type LoginRequest = {
   email: string;
@@ -19,10 +20,14 @@ type ApiError = {
   error: string;
 };
 
+// Accounts with 2FA get a challenge token instead of a session
 type LoginResponse = {
   message: string;
-  token: string;
-  user: { id: number };
+  token?: string;
+  refresh_token?: string;
+  two_factor_required?: boolean;
+  challenge_token?: string;
+  user?: { id: number };
 };
 
 const API_URL = import.meta.env.VITE_API_URL || 'http://26.176.162.130:8080';
@@ -30,6 +35,7 @@ const RECAPTCHA_SITE_KEY = import.meta.env.VITE_RECAPTCHA_SITE_KEY || '';
 
 const Login: React.FC = () => {
   const navigate = useNavigate();
+  const location = useLocation();
   const recaptchaRef = useRef<ReCAPTCHA>(null);
   
   const [formData, setFormData] = useState<LoginRequest>({
@@ -40,6 +46,11 @@ const Login: React.FC = () => {
   const [googleLoading, setGoogleLoading] = useState<boolean>(false);
   const [error, setError] = useState<string | null>(null);
   const [showForgot, setShowForgot] = useState<boolean>(false);
+  // Set by a password/Google login here, or by OAuthCallback for GitHub
+  const [challengeToken, setChallengeToken] = useState<string | null>(
+    (location.state as { challengeToken?: string } | null)?.challengeToken ?? null
+  );
+  const [twoFactorCode, setTwoFactorCode] = useState<string>("");
 
   useEffect(() => {
     const token = localStorage.getItem("token");
@@ -48,6 +59,52 @@ const Login: React.FC = () => {
     }
   }, [navigate]);
 
+  // finishLogin either starts the session or moves on to the 2FA step
+  const finishLogin = (data: LoginResponse, successMessage: string): void => {
+    if (data.two_factor_required && data.challenge_token) {
+      setChallengeToken(data.challenge_token);
+      setTwoFactorCode("");
+      return;
+    }
+    if (data.token) {
+      saveSession({ token: data.token, refresh_token: data.refresh_token });
+      toast.success(successMessage);
+      navigate("/dashboard");
+    }
+  };
+
+  const handleTwoFactorSubmit = async (e: React.FormEvent<HTMLFormElement>): Promise<void> => {
+    e.preventDefault();
+    setLoading(true);
+    setError(null);
+
+    try {
+      const response = await axios.post<LoginResponse>(
+        `${API_URL}/users/login/2fa`,
+        { challenge_token: challengeToken, code: twoFactorCode.trim() },
+        { headers: { "Content-Type": "application/json" } }
+      );
+      finishLogin(response.data, "Logged in successfully!");
+    } catch (err) {
+      const axiosErr = err as AxiosError<ApiError>;
+      const serverMsg = axiosErr.response?.data?.error || "Verification failed.";
+      // Expired or exhausted challenges need the password again
+      const lower = serverMsg.toLowerCase();
+      if (lower.includes("log in again") || lower.includes("expired")) {
+        setChallengeToken(null);
+      }
+      setError(serverMsg);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const cancelTwoFactor = (): void => {
+    setChallengeToken(null);
+    setTwoFactorCode("");
+    setError(null);
+  };
+
   const handleChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
     setError(null);
     setShowForgot(false);
@@ -79,11 +136,7 @@ const Login: React.FC = () => {
         { headers: { "Content-Type": "application/json" } }
       );
 
-      const { token } = response.data;
-      localStorage.setItem("token", token);
-      window.dispatchEvent(new Event('auth-change'));
-      toast.success("Logged in successfully!");
-      navigate("/dashboard");
+      finishLogin(response.data, "Logged in successfully!");
     } catch (err) {
       const axiosErr = err as AxiosError<ApiError>;
       const serverMsg =
@@ -92,8 +145,14 @@ const Login: React.FC = () => {
         axiosErr.message ||
         "Something went wrong.";
 
-      setError(serverMsg);
       const lower = serverMsg.toLowerCase();
+      if (lower.includes("verify your email")) {
+        toast.info("Please verify your email to continue");
+        navigate("/verify-email", { state: { email: formData.email } });
+        return;
+      }
+
+      setError(serverMsg);
       if (lower.includes("invalid password") || 
           lower.includes("incorrect password") || 
           lower.includes("wrong password")) {
@@ -104,40 +163,29 @@ const Login: React.FC = () => {
     }
   };
 
-  const googleLogin = useGoogleLogin({
-    onSuccess: async (tokenResponse) => {
-      setGoogleLoading(true);
-      try {
-        // Get user info from Google
-        const userInfoResponse = await axios.get(
-          'https://www.googleapis.com/oauth2/v2/userinfo',
-          { headers: { Authorization: `Bearer ${tokenResponse.access_token}` } }
-        );
-
-        // Send to backend
-        const response = await axios.post(
-          `${API_URL}/auth/google/login`,
-          { google_user: userInfoResponse.data },
-          { headers: { "Content-Type": "application/json" } }
-        );
-
-        const { token } = response.data;
-        localStorage.setItem("token", token);
-        window.dispatchEvent(new Event('auth-change'));
-        toast.success("Logged in with Google successfully!");
-        navigate("/dashboard");
-      } catch (error) {
-        console.error("Google login error:", error);
-        toast.error("Google login failed. Please try again.");
-      } finally {
-        setGoogleLoading(false);
-      }
-    },
-    onError: () => {
+  const handleGoogleCredential = async (credentialResponse: CredentialResponse) => {
+    if (!credentialResponse.credential) {
       toast.error("Google login failed");
+      return;
+    }
+
+    setGoogleLoading(true);
+    try {
+      // The backend verifies the Google ID token itself
+      const response = await axios.post<LoginResponse>(
+        `${API_URL}/auth/google/login`,
+        { id_token: credentialResponse.credential },
+        { headers: { "Content-Type": "application/json" } }
+      );
+
+      finishLogin(response.data, "Logged in with Google successfully!");
+    } catch (error) {
+      console.error("Google login error:", error);
+      toast.error("Google login failed. Please try again.");
+    } finally {
       setGoogleLoading(false);
-    },
-  });
+    }
+  };
 
   return (
     <div className="flex items-center justify-center min-h-screen w-full py-12 px-4">
@@ -152,36 +200,81 @@ const Login: React.FC = () => {
             </div>
           </div>
           <CardTitle className="text-3xl font-bold text-gray-900">
-            Welcome Back
+            {challengeToken ? "Two-Factor Check" : "Welcome Back"}
           </CardTitle>
-          <p className="text-gray-600 text-sm mt-2">Sign in to continue to SocApp</p>
+          <p className="text-gray-600 text-sm mt-2">
+            {challengeToken
+              ? "Enter the code from your authenticator app or a recovery code"
+              : "Sign in to continue to SocApp"}
+          </p>
         </CardHeader>
 
         <CardContent className="px-8">
+          {challengeToken ? (
+          <form onSubmit={handleTwoFactorSubmit}>
+            <div className="flex flex-col gap-5">
+              <div className="space-y-2">
+                <Label htmlFor="twoFactorCode" className="text-sm font-semibold text-gray-700">
+                  Verification code
+                </Label>
+                <Input
+                  id="twoFactorCode"
+                  type="text"
+                  autoComplete="one-time-code"
+                  placeholder="123456"
+                  required
+                  autoFocus
+                  onChange={(e) => {
+                    setError(null);
+                    setTwoFactorCode(e.target.value);
+                  }}
+                  value={twoFactorCode}
+                  className="h-12 px-4 rounded-xl border-2 border-gray-200 focus:border-orange-500 focus:ring-4 focus:ring-orange-100 transition-all duration-200 tracking-widest text-center text-lg"
+                />
+              </div>
+
+              {error && (
+                <div className="px-4 py-3 rounded-xl bg-red-50 border border-red-200">
+                  <p className="text-red-600 text-sm font-medium">{error}</p>
+                </div>
+              )}
+
+              <Button
+                type="submit"
+                className="h-12 mt-2 bg-gradient-to-r from-orange-500 to-orange-600 hover:from-orange-600 hover:to-orange-700 text-white font-semibold rounded-xl shadow-lg hover:shadow-xl transition-all duration-300 hover:scale-[1.02]"
+                disabled={loading || !twoFactorCode.trim()}
+              >
+                {loading ? "Verifying..." : "Verify"}
+              </Button>
+
+              <button
+                type="button"
+                onClick={cancelTwoFactor}
+                className="text-sm text-gray-500 hover:text-gray-700 font-medium transition-colors"
+              >
+                Back to sign in
+              </button>
+            </div>
+          </form>
+          ) : (
+          <>
           {/* Google Sign In Button */}
-          <Button
-            type="button"
-            onClick={() => googleLogin()}
-            disabled={googleLoading || loading}
-            className="w-full h-12 mb-6 bg-white hover:bg-gray-50 text-gray-700 border-2 border-gray-300 font-semibold rounded-xl shadow-sm hover:shadow-md transition-all duration-300 flex items-center justify-center gap-3"
-          >
+          <div className="flex justify-center h-12 mb-6">
             {googleLoading ? (
-              <svg className="animate-spin h-5 w-5" viewBox="0 0 24 24">
+              <svg className="animate-spin h-5 w-5 self-center" viewBox="0 0 24 24">
                 <circle className="opacity-25" cx="12" cy="12" r="10" stroke="currentColor" strokeWidth="4" fill="none" />
                 <path className="opacity-75" fill="currentColor" d="M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 014 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z" />
               </svg>
             ) : (
-              <>
-                <svg className="w-5 h-5" viewBox="0 0 24 24">
-                  <path fill="#4285F4" d="M22.56 12.25c0-.78-.07-1.53-.2-2.25H12v4.26h5.92c-.26 1.37-1.04 2.53-2.21 3.31v2.77h3.57c2.08-1.92 3.28-4.74 3.28-8.09z"/>
-                  <path fill="#34A853" d="M12 23c2.97 0 5.46-.98 7.28-2.66l-3.57-2.77c-.98.66-2.23 1.06-3.71 1.06-2.86 0-5.29-1.93-6.16-4.53H2.18v2.84C3.99 20.53 7.7 23 12 23z"/>
-                  <path fill="#FBBC05" d="M5.84 14.09c-.22-.66-.35-1.36-.35-2.09s.13-1.43.35-2.09V7.07H2.18C1.43 8.55 1 10.22 1 12s.43 3.45 1.18 4.93l2.85-2.22.81-.62z"/>
-                  <path fill="#EA4335" d="M12 5.38c1.62 0 3.06.56 4.21 1.64l3.15-3.15C17.45 2.09 14.97 1 12 1 7.7 1 3.99 3.47 2.18 7.07l3.66 2.84c.87-2.6 3.3-4.53 6.16-4.53z"/>
-                </svg>
-                Continue with Google
-              </>
+              <GoogleLogin
+                onSuccess={handleGoogleCredential}
+                onError={() => toast.error("Google login failed")}
+                text="continue_with"
+                shape="pill"
+                size="large"
+              />
             )}
-          </Button>
+          </div>
 
           <div className="relative my-6">
             <div className="absolute inset-0 flex items-center">
@@ -267,6 +360,8 @@ const Login: React.FC = () => {
               </Button>
             </div>
           </form>
+          </>
+          )}
         </CardContent>
 
         <CardFooter className="pb-8 px-8">
@@ -1,13 +1,26 @@
-import React, { useEffect } from 'react';
+import React, { useEffect, useRef } from 'react';
 import { useNavigate, useSearchParams } from 'react-router-dom';
 import { toast } from 'react-toastify';
+import axios, { AxiosError } from 'axios';
+import { API_URL, saveSession } from '@/lib/auth';
+
+type ExchangeResponse = {
+  token?: string;
+  refresh_token?: string;
+  two_factor_required?: boolean;
+  challenge_token?: string;
+  link_token?: string;
+  provider?: string;
+};
 
 const OAuthCallback: React.FC = () => {
   const [searchParams] = useSearchParams();
   const navigate = useNavigate();
+  // Codes work once, so don't redeem again when StrictMode re-runs the effect
+  const redeemed = useRef(false);
 
   useEffect(() => {
-    const token = searchParams.get('token');
+    const code = searchParams.get('code');
     const error = searchParams.get('error');
 
     if (error) {
@@ -16,15 +29,61 @@ const OAuthCallback: React.FC = () => {
       return;
     }
 
-    if (token) {
-      localStorage.setItem('token', token);
-      window.dispatchEvent(new Event('auth-change'));
-      toast.success('Successfully logged in with Google!');
-      navigate('/dashboard');
-    } else {
-      toast.error('No authentication token received');
+    if (!code) {
+      toast.error('No authentication code received');
       navigate('/login');
+      return;
+    }
+
+    if (redeemed.current) {
+      return;
     }
+    redeemed.current = true;
+
+    const finish = async () => {
+      try {
+        // The server hands over the login result for a one-time code so
+        // tokens never appear in the URL
+        const { data } = await axios.post<ExchangeResponse>(
+          `${API_URL}/auth/exchange`,
+          { code },
+          { headers: { 'Content-Type': 'application/json' } }
+        );
+
+        if (data.two_factor_required && data.challenge_token) {
+          navigate('/login', { replace: true, state: { challengeToken: data.challenge_token } });
+          return;
+        }
+
+        if (data.link_token) {
+          const token = localStorage.getItem('token');
+          await axios.post(
+            `${API_URL}/users/identities/confirm`,
+            { link_token: data.link_token },
+            { headers: { Authorization: `Bearer ${token}` } }
+          );
+          toast.success(`${data.provider ?? 'Account'} linked successfully!`);
+          navigate('/settings', { replace: true });
+          return;
+        }
+
+        if (data.token) {
+          saveSession({ token: data.token, refresh_token: data.refresh_token });
+          toast.success('Successfully logged in!');
+          navigate('/dashboard', { replace: true });
+          return;
+        }
+
+        toast.error('No authentication token received');
+        navigate('/login');
+      } catch (err) {
+        const axiosErr = err as AxiosError<{ error: string }>;
+        toast.error(axiosErr.response?.data?.error || 'Authentication failed');
+        navigate('/login');
+      }
+    };
+
+    finish();
   }, [searchParams, navigate]);
 
   return (
@@ -37,4 +96,4 @@ const OAuthCallback: React.FC = () => {
   );
 };
 
-export default OAuthCallback;
\ No newline at end of file
+export default OAuthCallback;
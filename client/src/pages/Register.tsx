@@ -6,8 +6,9 @@ import { Label } from "@/components/ui/label";
 import { useNavigate } from 'react-router-dom';
 import axios, { AxiosError } from 'axios';
 import { toast } from 'react-toastify';
-import { useGoogleLogin } from '@react-oauth/google';
+import { GoogleLogin, type CredentialResponse } from '@react-oauth/google';
 import ReCAPTCHA from 'react-google-recaptcha';
+import { saveSession } from "@/lib/auth";
 
 type RegisterRequest = {
   username: string;
@@ -17,8 +18,8 @@ type RegisterRequest = {
 };
 
 type RegisterResponse = {
-  error: string;
-  token: string;
+  message: string;
+  verification_required: boolean;
   user: { id: number };
 };
 
@@ -101,12 +102,10 @@ const Register: React.FC = () => {
         { ...formData, recaptcha_token: recaptchaToken },
         { headers: { "Content-Type": "application/json" } }
       );
-      
-      const { token } = response.data;
-      localStorage.setItem("token", token);
-      window.dispatchEvent(new Event('auth-change'));
-      toast.success("Account created successfully!");
-      navigate("/dashboard");
+
+      // New accounts get their tokens once the emailed code is confirmed
+      toast.success(response.data.message || "Account created! Check your email for a code.");
+      navigate("/verify-email", { state: { email: formData.email } });
     } catch (err) {
       const error = err as AxiosError<ApiError>;
       setError(error.response?.data?.error || "Something went wrong.");
@@ -115,40 +114,31 @@ const Register: React.FC = () => {
     }
   };
 
-  const googleLogin = useGoogleLogin({
-    onSuccess: async (tokenResponse) => {
-      setGoogleLoading(true);
-      try {
-        // Get user info from Google
-        const userInfoResponse = await axios.get(
-          'https://www.googleapis.com/oauth2/v2/userinfo',
-          { headers: { Authorization: `Bearer ${tokenResponse.access_token}` } }
-        );
+  const handleGoogleCredential = async (credentialResponse: CredentialResponse) => {
+    if (!credentialResponse.credential) {
+      toast.error("Google registration failed");
+      return;
+    }
 
-        // Send to backend
-        const response = await axios.post(
-          `${API_URL}/auth/google/register`,
-          { google_user: userInfoResponse.data },
-          { headers: { "Content-Type": "application/json" } }
-        );
+    setGoogleLoading(true);
+    try {
+      // The backend verifies the Google ID token itself
+      const response = await axios.post(
+        `${API_URL}/auth/google/register`,
+        { id_token: credentialResponse.credential },
+        { headers: { "Content-Type": "application/json" } }
+      );
 
-        const { token } = response.data;
-        localStorage.setItem("token", token);
-        window.dispatchEvent(new Event('auth-change'));
-        toast.success("Registered with Google successfully!");
-        navigate("/dashboard");
-      } catch (error) {
-        console.error("Google registration error:", error);
-        toast.error("Google registration failed. Please try again.");
-      } finally {
-        setGoogleLoading(false);
-      }
-    },
-    onError: () => {
-      toast.error("Google registration failed");
+      saveSession(response.data);
+      toast.success("Registered with Google successfully!");
+      navigate("/dashboard");
+    } catch (error) {
+      console.error("Google registration error:", error);
+      toast.error("Google registration failed. Please try again.");
+    } finally {
       setGoogleLoading(false);
-    },
-  });
+    }
+  };
 
   return (
     <div className='flex items-center justify-center min-h-screen w-full py-12 px-4'>
@@ -168,29 +158,22 @@ const Register: React.FC = () => {
 
         <CardContent className="px-8">
           {/* Google Sign Up Button */}
-          <Button
-            type="button"
-            onClick={() => googleLogin()}
-            disabled={googleLoading || loading}
-            className="w-full h-12 mb-6 bg-white hover:bg-gray-50 text-gray-700 border-2 border-gray-300 font-semibold rounded-xl shadow-sm hover:shadow-md transition-all duration-300 flex items-center justify-center gap-3"
-          >
+          <div className="flex justify-center h-12 mb-6">
             {googleLoading ? (
-              <svg className="animate-spin h-5 w-5" viewBox="0 0 24 24">
+              <svg className="animate-spin h-5 w-5 self-center" viewBox="0 0 24 24">
                 <circle className="opacity-25" cx="12" cy="12" r="10" stroke="currentColor" strokeWidth="4" fill="none" />
                 <path className="opacity-75" fill="currentColor" d="M4 12a8 8 0 018-8V0C5.373 0 0 5.373 0 12h4zm2 5.291A7.962 7.962 0 014 12H0c0 3.042 1.135 5.824 3 7.938l3-2.647z" />
               </svg>
             ) : (
-              <>
-                <svg className="w-5 h-5" viewBox="0 0 24 24">
-                  <path fill="#4285F4" d="M22.56 12.25c0-.78-.07-1.53-.2-2.25H12v4.26h5.92c-.26 1.37-1.04 2.53-2.21 3.31v2.77h3.57c2.08-1.92 3.28-4.74 3.28-8.09z"/>
-                  <path fill="#34A853" d="M12 23c2.97 0 5.46-.98 7.28-2.66l-3.57-2.77c-.98.66-2.23 1.06-3.71 1.06-2.86 0-5.29-1.93-6.16-4.53H2.18v2.84C3.99 20.53 7.7 23 12 23z"/>
-                  <path fill="#FBBC05" d="M5.84 14.09c-.22-.66-.35-1.36-.35-2.09s.13-1.43.35-2.09V7.07H2.18C1.43 8.55 1 10.22 1 12s.43 3.45 1.18 4.93l2.85-2.22.81-.62z"/>
-                  <path fill="#EA4335" d="M12 5.38c1.62 0 3.06.56 4.21 1.64l3.15-3.15C17.45 2.09 14.97 1 12 1 7.7 1 3.99 3.47 2.18 7.07l3.66 2.84c.87-2.6 3.3-4.53 6.16-4.53z"/>
-                </svg>
-                Continue with Google
-              </>
+              <GoogleLogin
+                onSuccess={handleGoogleCredential}
+                onError={() => toast.error("Google registration failed")}
+                text="continue_with"
+                shape="pill"
+                size="large"
+              />
             )}
-          </Button>
+          </div>
 
           <div className="relative my-6">
             <div className="absolute inset-0 flex items-center">
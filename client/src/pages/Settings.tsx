@@ -2,6 +2,7 @@ import React, { useEffect, useRef, useState } from "react";
 import axios from "axios";
 import { toast } from "react-toastify";
 import { useNavigate } from "react-router-dom";
+import { clearSession } from "@/lib/auth";
 import { FiEdit3, FiUser, FiMail, FiLock, FiCamera, FiUpload, FiTrash2, FiAlertTriangle, FiLogOut, FiShield } from "react-icons/fi";
 
 interface User {
@@ -231,8 +232,7 @@ const Settings: React.FC = () => {
 
   const handleLogout = () => {
     if (window.confirm("Are you sure you want to log out?")) {
-      localStorage.removeItem("token");
-      window.dispatchEvent(new Event('auth-change'));
+      clearSession();
       navigate("/");
       toast.success("Logged out successfully");
     }
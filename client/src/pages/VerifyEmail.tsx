@@ -0,0 +1,170 @@
+import React, { useState } from "react";
+import { Button } from "@/components/ui/button";
+import { Card, CardContent, CardFooter, CardHeader, CardTitle } from "@/components/ui/card";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { useLocation, useNavigate } from "react-router-dom";
+import { toast } from "react-toastify";
+import axios, { AxiosError } from "axios";
+import { API_URL, saveSession } from "@/lib/auth";
+
+type ApiError = {
+  error: string;
+};
+
+type VerifyResponse = {
+  message: string;
+  token: string;
+  refresh_token: string;
+  user: { id: number };
+};
+
+// Reached after registering, or after logging in to an account that was
+// never verified; the email comes along in the navigation state
+const VerifyEmail: React.FC = () => {
+  const navigate = useNavigate();
+  const location = useLocation();
+  const initialEmail = (location.state as { email?: string } | null)?.email ?? "";
+
+  const [email, setEmail] = useState<string>(initialEmail);
+  const [code, setCode] = useState<string>("");
+  const [loading, setLoading] = useState<boolean>(false);
+  const [resending, setResending] = useState<boolean>(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const handleSubmit = async (e: React.FormEvent<HTMLFormElement>): Promise<void> => {
+    e.preventDefault();
+    setLoading(true);
+    setError(null);
+
+    try {
+      const response = await axios.post<VerifyResponse>(
+        `${API_URL}/auth/verify-email`,
+        { email: email.trim(), code: code.trim() },
+        { headers: { "Content-Type": "application/json" } }
+      );
+
+      saveSession(response.data);
+      toast.success("Email verified, welcome to SocApp!");
+      navigate("/dashboard");
+    } catch (err) {
+      const axiosErr = err as AxiosError<ApiError>;
+      setError(axiosErr.response?.data?.error || "Verification failed. Please try again.");
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleResend = async (): Promise<void> => {
+    if (!email.trim()) {
+      setError("Enter your email to get a new code");
+      return;
+    }
+
+    setResending(true);
+    setError(null);
+    try {
+      const response = await axios.post(
+        `${API_URL}/auth/resend-verification`,
+        { email: email.trim() },
+        { headers: { "Content-Type": "application/json" } }
+      );
+      toast.info(response.data.message || "A new code is on its way");
+    } catch (err) {
+      const axiosErr = err as AxiosError<ApiError>;
+      setError(axiosErr.response?.data?.error || "Could not send a new code.");
+    } finally {
+      setResending(false);
+    }
+  };
+
+  return (
+    <div className="flex items-center justify-center min-h-screen w-full py-12 px-4">
+      <div className="fixed top-0 right-0 w-96 h-96 bg-orange-200 rounded-full mix-blend-multiply filter blur-3xl opacity-20 animate-blob animation-delay-2000" />
+      <div className="fixed bottom-0 left-0 w-96 h-96 bg-orange-300 rounded-full mix-blend-multiply filter blur-3xl opacity-20 animate-blob animation-delay-4000" />
+
+      <Card className="relative w-full max-w-md bg-white/90 backdrop-blur-xl shadow-2xl border-2 border-orange-100 rounded-3xl overflow-hidden z-10">
+        <CardHeader className="text-center pb-6 pt-8">
+          <div className="flex justify-center mb-4">
+            <div className="w-16 h-16 rounded-2xl bg-gradient-to-br from-orange-500 to-orange-600 flex items-center justify-center shadow-lg">
+              <span className="text-white text-3xl font-bold">S</span>
+            </div>
+          </div>
+          <CardTitle className="text-3xl font-bold text-gray-900">
+            Verify Your Email
+          </CardTitle>
+          <p className="text-gray-600 text-sm mt-2">Enter the 6-digit code we sent to your inbox</p>
+        </CardHeader>
+
+        <CardContent className="px-8">
+          <form onSubmit={handleSubmit}>
+            <div className="flex flex-col gap-5">
+              <div className="space-y-2">
+                <Label htmlFor="email" className="text-sm font-semibold text-gray-700">
+                  Email
+                </Label>
+                <Input
+                  id="email"
+                  type="email"
+                  placeholder="john@example.com"
+                  required
+                  onChange={(e) => setEmail(e.target.value)}
+                  value={email}
+                  className="h-12 px-4 rounded-xl border-2 border-gray-200 focus:border-orange-500 focus:ring-4 focus:ring-orange-100 transition-all duration-200"
+                />
+              </div>
+
+              <div className="space-y-2">
+                <Label htmlFor="code" className="text-sm font-semibold text-gray-700">
+                  Verification code
+                </Label>
+                <Input
+                  id="code"
+                  type="text"
+                  inputMode="numeric"
+                  autoComplete="one-time-code"
+                  placeholder="123456"
+                  maxLength={6}
+                  required
+                  onChange={(e) => setCode(e.target.value.replace(/\D/g, ""))}
+                  value={code}
+                  className="h-12 px-4 rounded-xl border-2 border-gray-200 focus:border-orange-500 focus:ring-4 focus:ring-orange-100 transition-all duration-200 tracking-widest text-center text-lg"
+                />
+              </div>
+
+              {error && (
+                <div className="px-4 py-3 rounded-xl bg-red-50 border border-red-200">
+                  <p className="text-red-600 text-sm font-medium">{error}</p>
+                </div>
+              )}
+
+              <Button
+                type="submit"
+                className="h-12 mt-2 bg-gradient-to-r from-orange-500 to-orange-600 hover:from-orange-600 hover:to-orange-700 text-white font-semibold rounded-xl shadow-lg hover:shadow-xl transition-all duration-300 hover:scale-[1.02]"
+                disabled={loading || code.length !== 6}
+              >
+                {loading ? "Verifying..." : "Verify Email"}
+              </Button>
+            </div>
+          </form>
+        </CardContent>
+
+        <CardFooter className="pb-8 px-8">
+          <p className="text-center text-sm text-gray-600 w-full">
+            Didn't get a code?{' '}
+            <button
+              type="button"
+              onClick={handleResend}
+              disabled={resending}
+              className="text-orange-500 hover:text-orange-600 font-semibold transition-colors disabled:opacity-50"
+            >
+              {resending ? "Sending..." : "Send a new one"}
+            </button>
+          </p>
+        </CardFooter>
+      </Card>
+    </div>
+  );
+};
+
+export default VerifyEmail;
@@ -33,7 +33,8 @@ func main() {
 	// Initialize configuration (JWT, etc.)
 	config.InitConfig()
 	config.InitCloudinary()
-	services.InitGoogleOAuth()
+	services.InitOIDCProviders()
+	services.InitWebAuthn()
 
 	// Initialize Gin
 	r := gin.Default()
@@ -55,6 +56,14 @@ func main() {
 	// Connect to the database
 	database.ConnectDB()
 
+	// First start with linked identities: copy existing provider logins after migrating
+	backfillIdentities := !database.DB.Migrator().HasTable(&models.UserIdentity{})
+	// Filter holds on posts and comments moved from hidden_at to their own column
+	backfillHeldPosts := database.DB.Migrator().HasTable(&models.Post{}) &&
+		!database.DB.Migrator().HasColumn(&models.Post{}, "HeldAt")
+	backfillHeldComments := database.DB.Migrator().HasTable(&models.Comment{}) &&
+		!database.DB.Migrator().HasColumn(&models.Comment{}, "HeldAt")
+
 	// IMPORTANT: Include Message model in AutoMigrate
 	if err := database.DB.AutoMigrate(
 		&models.User{},
@@ -65,17 +74,63 @@ func main() {
 		&models.Comment{},
 		&models.PostWithStats{},
 		&models.PasswordReset{}, // Added password reset model
+		&models.Session{},
+		&models.RecoveryCode{},
+		&models.EmailVerification{},
+		&models.LoginAttempt{},
+		&models.UserIdentity{},
+		&models.WebAuthnCredential{},
+		&models.PersonalAccessToken{},
+		&models.Report{},
+		&models.FilterRule{},
+		&models.AuditEvent{},
+		&models.UserBlock{},
+		&models.UserMute{},
+		&models.Follow{},
+		&models.CloseFriend{},
 	); err != nil {
 		fmt.Println("Migration error:", err)
 	} else {
 		fmt.Println("Database migrated successfully")
+
+		if err := services.EnforceAuditAppendOnly(); err != nil {
+			fmt.Println("Audit log trigger error:", err)
+		}
+
+		if err := services.BootstrapAdmins(); err != nil {
+			fmt.Println("Admin bootstrap error:", err)
+		}
+
+		if backfillIdentities {
+			if err := services.BackfillUserIdentities(); err != nil {
+				fmt.Println("Identity backfill error:", err)
+			} else {
+				fmt.Println("Linked identities backfilled successfully")
+			}
+		}
+
+		if backfillHeldPosts {
+			if err := services.BackfillHeldPosts(); err != nil {
+				fmt.Println("Held posts backfill error:", err)
+			}
+		}
+
+		if backfillHeldComments {
+			if err := services.BackfillHeldComments(); err != nil {
+				fmt.Println("Held comments backfill error:", err)
+			}
+		}
 	}
 
+	// Sweep expired sessions, codes and tokens in the background
+	services.StartCleanupJobs()
+
 	// Routes
 	routes.UserRoutes(r)
 	routes.ContactRoutes(r)
 	routes.PostRoutes(r)
 	routes.ChatRoutes(r)
+	routes.AdminRoutes(r)
 	routes.SetupPasswordResetRoutes(r) // Fixed: removed comma and used correct variable 'r'
 
 	r.GET("/", func(c *gin.Context) {
@@ -3,26 +3,103 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 )
 
-var JWTSecret []byte
+// Token lifetimes (ACCESS_TOKEN_TTL_MINUTES, REFRESH_TOKEN_TTL_DAYS)
+var (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// AllowedOrigins lists browser origins allowed to open WebSocket connections
+var AllowedOrigins []string
+
+// FrontendURL is where the server-side OAuth flow sends the browser back to (FRONTEND_URL)
+var FrontendURL string
+
+// AdminEmails are promoted to the admin role at startup once verified (ADMIN_EMAILS)
+var AdminEmails []string
+
+// Passkey relying party (WEBAUTHN_RP_ID, WEBAUTHN_RP_NAME, WEBAUTHN_RP_ORIGINS).
+// Passkeys are disabled unless the RP ID and at least one origin are set.
+var (
+	WebAuthnRPID      string
+	WebAuthnRPName    string
+	WebAuthnRPOrigins []string
+)
 
 // InitConfig initializes all configuration from environment variables
 func InitConfig() {
-	// Initialize JWT Secret
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		log.Fatal("JWT_SECRET environment variable not set")
+	// Initialize JWT keyring (JWT_KEYS and/or the legacy JWT_SECRET)
+	kr, err := loadKeyring(os.Getenv("JWT_SECRET"))
+	if err != nil {
+		log.Fatalf("❌ Failed to load JWT keys: %v", err)
+	}
+	keyring = kr
+	log.Printf("✅ Signing tokens with key %s (%s)", kr.SigningKey().ID, kr.SigningKey().Method.Alg())
+
+	if minutes, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL_MINUTES")); err == nil && minutes > 0 {
+		AccessTokenTTL = time.Duration(minutes) * time.Minute
+	}
+	if days, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_TTL_DAYS")); err == nil && days > 0 {
+		RefreshTokenTTL = time.Duration(days) * 24 * time.Hour
+	}
+
+	// Comma-separated list, e.g. "https://app.example.com,http://localhost:5173"
+	AllowedOrigins = nil
+	for _, origin := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			AllowedOrigins = append(AllowedOrigins, origin)
+		}
 	}
-	JWTSecret = []byte(secret)
+
+	// Comma-separated list of account emails
+	AdminEmails = nil
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			AdminEmails = append(AdminEmails, email)
+		}
+	}
+
+	FrontendURL = strings.TrimRight(strings.TrimSpace(os.Getenv("FRONTEND_URL")), "/")
+
+	// RP ID is the site's domain, e.g. "example.com"; origins are where the frontend is served
+	WebAuthnRPID = strings.TrimSpace(os.Getenv("WEBAUTHN_RP_ID"))
+	WebAuthnRPName = os.Getenv("WEBAUTHN_RP_NAME")
+	if WebAuthnRPName == "" {
+		WebAuthnRPName = "SocialApp"
+	}
+	WebAuthnRPOrigins = nil
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_RP_ORIGINS"), ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			WebAuthnRPOrigins = append(WebAuthnRPOrigins, origin)
+		}
+	}
+
+	providers, err := loadOIDCProviders()
+	if err != nil {
+		log.Fatalf("❌ Failed to load login providers: %v", err)
+	}
+	OIDCProviders = providers
+
 	log.Println("Configuration loaded successfully")
 }
 
-// GetJWT returns the JWT secret key
-func GetJWT() []byte {
-	return JWTSecret
+// IsOriginAllowed reports whether origin is in the ALLOWED_ORIGINS allowlist.
+// An entry of "*" allows any origin.
+func IsOriginAllowed(origin string) bool {
+	origin = strings.TrimRight(origin, "/")
+	for _, allowed := range AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
 }
 
 var Cloud *cloudinary.Cloudinary
@@ -0,0 +1,209 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// LegacyKeyID identifies the HS256 JWT_SECRET key. Tokens without a kid
+// header are verified against it.
+const LegacyKeyID = "hs256"
+
+// SigningKey is one entry of the JWT keyring
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	// Private is nil for verify-only keys (e.g. a retired key kept until
+	// the tokens it signed have expired)
+	Private interface{}
+	Public  interface{}
+}
+
+// CanSign reports whether the key has private material
+func (k *SigningKey) CanSign() bool {
+	return k.Private != nil
+}
+
+// Keyring holds every key accepted for verification and the one used for signing
+type Keyring struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+	order   []string
+}
+
+var keyring *Keyring
+
+// Keys returns the keyring loaded by InitConfig
+func Keys() *Keyring {
+	return keyring
+}
+
+// SigningKey returns the key used for newly issued tokens
+func (kr *Keyring) SigningKey() *SigningKey {
+	return kr.signing
+}
+
+// VerificationKey looks up a key by the kid header. An empty kid maps to the legacy HS256 key.
+func (kr *Keyring) VerificationKey(kid string) (*SigningKey, bool) {
+	if kid == "" {
+		kid = LegacyKeyID
+	}
+	key, ok := kr.keys[kid]
+	return key, ok
+}
+
+// loadKeyring builds the keyring from JWT_KEYS and JWT_SECRET.
+//
+// JWT_KEYS is a comma-separated list of kid:alg:path entries, where alg is
+// RS256 or EdDSA and path points at a PEM file. A private key can sign and
+// verify, a public key only verifies. The first signing-capable entry signs
+// new tokens unless JWT_SIGNING_KID says otherwise, e.g.
+//
+//	JWT_KEYS=2026-10:EdDSA:/keys/ed25519.pem,2026-04:RS256:/keys/rsa-old.pub.pem
+//
+// JWT_SECRET, when set, is kept as an HS256 key so existing tokens stay valid;
+// it only signs when no JWT_KEYS entry can.
+func loadKeyring(secret string) (*Keyring, error) {
+	kr := &Keyring{keys: make(map[string]*SigningKey)}
+
+	for _, entry := range strings.Split(os.Getenv("JWT_KEYS"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid JWT_KEYS entry %q, expected kid:alg:path", entry)
+		}
+
+		key, err := loadPEMKey(parts[0], parts[1], parts[2])
+		if err != nil {
+			return nil, err
+		}
+		if err := kr.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if secret != "" {
+		if err := kr.add(&SigningKey{
+			ID:      LegacyKeyID,
+			Method:  jwt.SigningMethodHS256,
+			Private: []byte(secret),
+			Public:  []byte(secret),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if kid := os.Getenv("JWT_SIGNING_KID"); kid != "" {
+		key, ok := kr.keys[kid]
+		if !ok || !key.CanSign() {
+			return nil, fmt.Errorf("JWT_SIGNING_KID %q is not a signing key in the keyring", kid)
+		}
+		kr.signing = key
+	} else {
+		for _, kid := range kr.order {
+			if kr.keys[kid].CanSign() {
+				kr.signing = kr.keys[kid]
+				break
+			}
+		}
+	}
+
+	if kr.signing == nil {
+		return nil, fmt.Errorf("no signing key configured, set JWT_KEYS or JWT_SECRET")
+	}
+
+	return kr, nil
+}
+
+func (kr *Keyring) add(key *SigningKey) error {
+	if _, exists := kr.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate JWT key id %q", key.ID)
+	}
+	kr.keys[key.ID] = key
+	kr.order = append(kr.order, key.ID)
+	return nil
+}
+
+// loadPEMKey reads a private or public RS256/EdDSA key from a PEM file
+func loadPEMKey(kid, alg, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key %q: %w", kid, err)
+	}
+
+	key := &SigningKey{ID: kid}
+
+	switch alg {
+	case "RS256":
+		key.Method = jwt.SigningMethodRS256
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.Private = private
+			key.Public = &private.PublicKey
+		} else if public, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			key.Public = public
+		} else {
+			return nil, fmt.Errorf("JWT key %q is not a valid RSA PEM key", kid)
+		}
+
+	case "EdDSA":
+		key.Method = jwt.SigningMethodEdDSA
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			key.Private = private
+			key.Public = private.(crypto.Signer).Public()
+		} else if public, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			key.Public = public
+		} else {
+			return nil, fmt.Errorf("JWT key %q is not a valid Ed25519 PEM key", kid)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q for JWT key %q (use RS256 or EdDSA)", alg, kid)
+	}
+
+	log.Printf("✅ Loaded JWT key %s (%s, signing: %v)", kid, alg, key.CanSign())
+	return key, nil
+}
+
+// JWKS returns the public keys as a JSON Web Key Set. Symmetric keys are never published.
+func (kr *Keyring) JWKS() map[string]interface{} {
+	keys := []map[string]interface{}{}
+
+	for _, kid := range kr.order {
+		key := kr.keys[kid]
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]interface{}{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": key.Method.Alg(),
+				"kid": key.ID,
+				"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]interface{}{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"use": "sig",
+				"alg": key.Method.Alg(),
+				"kid": key.ID,
+				"x":   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
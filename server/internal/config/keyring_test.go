@@ -0,0 +1,181 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeyFiles are the paths of the PEM files writeTestKeys creates
+type testKeyFiles struct {
+	edPrivate, rsaPrivate, rsaPublic string
+}
+
+// writeTestKeys writes an Ed25519 private key, an RSA private key and the
+// RSA public key as PEM files
+func writeTestKeys(t *testing.T) testKeyFiles {
+	t.Helper()
+	dir := t.TempDir()
+
+	write := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPublicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return testKeyFiles{
+		edPrivate:  write("ed25519.pem", "PRIVATE KEY", edDER),
+		rsaPrivate: write("rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+		rsaPublic:  write("rsa.pub.pem", "PUBLIC KEY", rsaPublicDER),
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	files := writeTestKeys(t)
+
+	tests := []struct {
+		name        string
+		keys        string
+		signingKID  string
+		secret      string
+		wantErr     bool
+		wantSigning string
+		wantJWKS    []string // kids published, in order
+	}{
+		{name: "legacy secret only", secret: "s3cret", wantSigning: LegacyKeyID, wantJWKS: []string{}},
+		{name: "nothing configured", wantErr: true},
+		{
+			name:        "first private key signs",
+			keys:        "new:EdDSA:" + files.edPrivate + ",old:RS256:" + files.rsaPublic,
+			secret:      "s3cret",
+			wantSigning: "new",
+			wantJWKS:    []string{"new", "old"},
+		},
+		{
+			name:        "public keys only fall back to the secret",
+			keys:        "old:RS256:" + files.rsaPublic,
+			secret:      "s3cret",
+			wantSigning: LegacyKeyID,
+			wantJWKS:    []string{"old"},
+		},
+		{
+			name:        "signing kid picks the key",
+			keys:        "ed:EdDSA:" + files.edPrivate + ",rsa:RS256:" + files.rsaPrivate,
+			signingKID:  "rsa",
+			wantSigning: "rsa",
+			wantJWKS:    []string{"ed", "rsa"},
+		},
+		{name: "public keys without a secret", keys: "old:RS256:" + files.rsaPublic, wantErr: true},
+		{name: "signing kid is verify-only", keys: "old:RS256:" + files.rsaPublic, secret: "s3cret", signingKID: "old", wantErr: true},
+		{name: "unknown signing kid", secret: "s3cret", signingKID: "missing", wantErr: true},
+		{name: "malformed entry", keys: "ed:" + files.edPrivate, wantErr: true},
+		{name: "unsupported alg", keys: "ed:HS512:" + files.edPrivate, wantErr: true},
+		{name: "alg doesn't match the key", keys: "ed:RS256:" + files.edPrivate, wantErr: true},
+		{name: "duplicate kid", keys: "k:EdDSA:" + files.edPrivate + ",k:RS256:" + files.rsaPrivate, wantErr: true},
+		{name: "secret clashes with legacy kid", keys: LegacyKeyID + ":EdDSA:" + files.edPrivate, secret: "s3cret", wantErr: true},
+		{name: "missing file", keys: "ed:EdDSA:" + filepath.Join(t.TempDir(), "nope.pem"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("JWT_KEYS", tt.keys)
+			t.Setenv("JWT_SIGNING_KID", tt.signingKID)
+
+			kr, err := loadKeyring(tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := kr.SigningKey().ID; got != tt.wantSigning {
+				t.Fatalf("signing key = %q, want %q", got, tt.wantSigning)
+			}
+
+			published := kr.JWKS()["keys"].([]map[string]interface{})
+			if len(published) != len(tt.wantJWKS) {
+				t.Fatalf("JWKS has %d keys, want %v", len(published), tt.wantJWKS)
+			}
+			for i, key := range published {
+				if key["kid"] != tt.wantJWKS[i] {
+					t.Fatalf("JWKS key %d = %v, want %q", i, key["kid"], tt.wantJWKS[i])
+				}
+				if key["kty"] == "oct" || key["k"] != nil {
+					t.Fatalf("JWKS publishes a symmetric key: %v", key)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyringSignAndVerify(t *testing.T) {
+	files := writeTestKeys(t)
+	t.Setenv("JWT_KEYS", "ed:EdDSA:"+files.edPrivate+",rsa:RS256:"+files.rsaPrivate)
+	t.Setenv("JWT_SIGNING_KID", "")
+
+	kr, err := loadKeyring("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		kid  string
+	}{
+		{name: "EdDSA", kid: "ed"},
+		{name: "RS256", kid: "rsa"},
+		{name: "legacy HS256 without kid", kid: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := kr.VerificationKey(tt.kid)
+			if !ok {
+				t.Fatalf("no verification key for kid %q", tt.kid)
+			}
+
+			signed, err := jwt.NewWithClaims(key.Method, jwt.MapClaims{"sub": "1"}).SignedString(key.Private)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := jwt.Parse(signed, func(*jwt.Token) (interface{}, error) { return key.Public, nil },
+				jwt.WithValidMethods([]string{key.Method.Alg()}))
+			if err != nil || !parsed.Valid {
+				t.Fatalf("verify %s token: %v", tt.name, err)
+			}
+		})
+	}
+
+	if _, ok := kr.VerificationKey("unknown"); ok {
+		t.Fatal("an unknown kid resolved to a key")
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// OIDCProviderConfig describes one external login provider.
+//
+// OpenID Connect providers only need Issuer; endpoints are read from the
+// issuer's discovery document. Plain OAuth2 providers (GitHub) set Type and
+// get their endpoints from a built-in preset instead.
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`
+	DisplayName  string   `json:"display_name"`
+	Type         string   `json:"type"` // "oidc" (default) or "github"
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"`
+	// TrustEmail treats the provider's email as verified even without an
+	// email_verified claim (e.g. a company IdP that manages addresses itself)
+	TrustEmail bool `json:"trust_email"`
+}
+
+// OIDCProviders holds the configured login providers, in configuration order
+var OIDCProviders []OIDCProviderConfig
+
+// Provider names are stored in users.provider, which is 20 characters wide
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,20}$`)
+
+// loadOIDCProviders reads providers from OIDC_CONFIG_FILE (a JSON file with a
+// "providers" array) and from OIDC_PROVIDERS, a comma-separated list of names
+// each configured by OIDC_<NAME>_* variables, e.g.
+//
+//	OIDC_PROVIDERS=keycloak
+//	OIDC_KEYCLOAK_ISSUER=https://sso.example.com/realms/staff
+//	OIDC_KEYCLOAK_CLIENT_ID=socialapp
+//	OIDC_KEYCLOAK_CLIENT_SECRET=...
+//	OIDC_KEYCLOAK_REDIRECT_URL=https://api.example.com/auth/oidc/keycloak/callback
+//
+// The existing GOOGLE_CLIENT_ID/GOOGLE_CLIENT_SECRET/GOOGLE_REDIRECT_URL
+// settings register a "google" provider.
+func loadOIDCProviders() ([]OIDCProviderConfig, error) {
+	var providers []OIDCProviderConfig
+
+	if path := os.Getenv("OIDC_CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OIDC_CONFIG_FILE: %w", err)
+		}
+		var file struct {
+			Providers []OIDCProviderConfig `json:"providers"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse OIDC_CONFIG_FILE: %w", err)
+		}
+		providers = append(providers, file.Providers...)
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			DisplayName:  os.Getenv(prefix + "DISPLAY_NAME"),
+			Type:         os.Getenv(prefix + "TYPE"),
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			TrustEmail:   os.Getenv(prefix+"TRUST_EMAIL") == "true",
+		}
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			provider.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+		providers = append(providers, provider)
+	}
+
+	// Only when the server-side flow is fully configured; the client-side
+	// ID token flow needs just the client ID
+	if clientID := os.Getenv("GOOGLE_CLIENT_ID"); clientID != "" &&
+		os.Getenv("GOOGLE_CLIENT_SECRET") != "" && os.Getenv("GOOGLE_REDIRECT_URL") != "" {
+		providers = append(providers, OIDCProviderConfig{
+			Name:         "google",
+			DisplayName:  "Google",
+			Issuer:       "https://accounts.google.com",
+			ClientID:     clientID,
+			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),
+		})
+	}
+
+	seen := make(map[string]bool)
+	for i := range providers {
+		p := &providers[i]
+		if p.Type == "" {
+			p.Type = "oidc"
+		}
+		if p.DisplayName == "" {
+			p.DisplayName = p.Name
+		}
+
+		switch {
+		case !providerNamePattern.MatchString(p.Name):
+			return nil, fmt.Errorf("invalid OIDC provider name %q (use up to 20 of a-z, 0-9, - and _)", p.Name)
+		case p.Name == "local":
+			return nil, fmt.Errorf("OIDC provider name %q is reserved for password accounts", p.Name)
+		case seen[p.Name]:
+			return nil, fmt.Errorf("duplicate OIDC provider %q", p.Name)
+		case p.Type != "oidc" && p.Type != "github":
+			return nil, fmt.Errorf("OIDC provider %q has unsupported type %q", p.Name, p.Type)
+		case p.Type == "oidc" && p.Issuer == "":
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer", p.Name)
+		case p.ClientID == "" || p.RedirectURL == "":
+			return nil, fmt.Errorf("OIDC provider %q needs a client_id and redirect_url", p.Name)
+		}
+		seen[p.Name] = true
+
+		log.Printf("✅ Login provider %s configured (%s)", p.Name, p.Type)
+	}
+
+	return providers, nil
+}
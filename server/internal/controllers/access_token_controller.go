@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAccessTokenRequest - ExpiresInDays defaults to 30 when omitted
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+// GetAccessTokens - List the current user's personal access tokens and the scopes they can have
+func GetAccessTokens(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := services.ListAccessTokens(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens, "scopes": services.ListTokenScopes()})
+}
+
+// CreateAccessToken - Issue a personal access token for scripts and bots
+func CreateAccessToken(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and scopes are required"})
+		return
+	}
+
+	token, pat, err := services.CreateAccessToken(userID, req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditTokenCreated, userID, map[string]interface{}{"token_id": pat.ID, "scopes": pat.Scopes})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Access token created. Copy it now, it won't be shown again",
+		"token":        token,
+		"access_token": pat,
+	})
+}
+
+// RevokeAccessToken - Delete a personal access token
+func RevokeAccessToken(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid token ID"})
+		return
+	}
+
+	if err := services.RevokeAccessToken(userID, uint(tokenID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditTokenRevoked, userID, map[string]interface{}{"token_id": tokenID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access token revoked successfully"})
+}
@@ -0,0 +1,299 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetRoles - List the roles and the permissions each grants
+func GetRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"roles": models.RolePermissions})
+}
+
+// SetUserRole - Make a user a moderator or admin, or demote them
+func SetUserRole(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role is required"})
+		return
+	}
+
+	user, err := services.SetUserRole(actorID, uint(targetID), req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditAdminRoleChanged, user.ID, map[string]interface{}{"role": user.Role})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Role updated successfully",
+		"user":    gin.H{"id": user.ID, "username": user.Username, "role": user.Role},
+	})
+}
+
+// AdminListUsers - Search accounts by username or email, optionally by status
+func AdminListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "0"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 0 {
+		page = 0
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	users, total, err := services.ListUsers(c.Query("q"), c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users":     users,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// AdminGetUser - Account details, activity counts and recent logins
+func AdminGetUser(c *gin.Context) {
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	details, err := services.GetUserDetails(targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": details})
+}
+
+// AdminSuspendUser - Block a user from logging in for a number of hours
+func AdminSuspendUser(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		DurationHours int    `json:"duration_hours" binding:"required"`
+		Reason        string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "duration_hours and reason are required"})
+		return
+	}
+
+	user, err := services.SuspendUser(actorID, targetID, time.Duration(req.DurationHours)*time.Hour, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditAdminSuspended, targetID, map[string]interface{}{"duration_hours": req.DurationHours, "reason": req.Reason})
+
+	Hub.DisconnectUser(targetID, "account_suspended")
+	c.JSON(http.StatusOK, gin.H{"message": "User suspended successfully", "user": user})
+}
+
+// AdminBanUser - Permanently block a user from logging in
+func AdminBanUser(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	user, err := services.BanUser(actorID, targetID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditAdminBanned, targetID, map[string]interface{}{"reason": req.Reason})
+
+	Hub.DisconnectUser(targetID, "account_banned")
+	c.JSON(http.StatusOK, gin.H{"message": "User banned successfully", "user": user})
+}
+
+// AdminReinstateUser - Lift a suspension or ban
+func AdminReinstateUser(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := services.ReinstateUser(actorID, targetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditAdminReinstated, targetID, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "User reinstated successfully", "user": user})
+}
+
+// AdminShadowBanUser - Hide a user's posts, comments and messages from everyone else
+func AdminShadowBanUser(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	user, err := services.ShadowBanUser(actorID, targetID, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditShadowBanned, targetID, map[string]interface{}{"reason": req.Reason})
+
+	Hub.SetShadowBanned(targetID, true)
+	c.JSON(http.StatusOK, gin.H{"message": "User shadow-banned successfully", "user": user})
+}
+
+// AdminLiftShadowBan - Make a shadow-banned user's content visible again
+func AdminLiftShadowBan(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := services.LiftShadowBan(actorID, targetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditShadowBanLifted, targetID, nil)
+
+	Hub.SetShadowBanned(targetID, false)
+	c.JSON(http.StatusOK, gin.H{"message": "Shadow-ban lifted successfully", "user": user})
+}
+
+// AdminForceLogout - Revoke every session of a user and close their connections
+func AdminForceLogout(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.ForceLogout(actorID, targetID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditAdminForcedLogout, targetID, nil)
+
+	Hub.DisconnectUser(targetID, "logged_out")
+	c.JSON(http.StatusOK, gin.H{"message": "User logged out everywhere"})
+}
+
+// AdminForcePasswordReset - Log a user out and email them a password reset code
+func AdminForcePasswordReset(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.ForcePasswordReset(actorID, targetID, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditAdminPasswordReset, targetID, nil)
+
+	Hub.DisconnectUser(targetID, "password_reset")
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset email sent"})
+}
+
+// parseTargetUserID reads the :id param, answering 400 when it isn't a valid ID
+func parseTargetUserID(c *gin.Context) (uint, bool) {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return 0, false
+	}
+	return uint(targetID), true
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// auditInfo describes the current request for the audit log
+func auditInfo(c *gin.Context) services.AuditInfo {
+	actorID, _ := getUserIDFromContext(c)
+	return services.AuditInfo{
+		ActorID:   actorID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// recordAudit appends an event about targetUserID's account made by the current request
+func recordAudit(c *gin.Context, action string, targetUserID uint, details map[string]interface{}) {
+	services.RecordAudit(action, auditInfo(c), targetUserID, details)
+}
+
+// GetAuditLog - Search the audit log by action, actor, target, IP and time range
+func GetAuditLog(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "0"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+
+	if page < 0 {
+		page = 0
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	filter := services.AuditFilter{
+		Action:    c.Query("action"),
+		IPAddress: c.Query("ip"),
+	}
+
+	if actorID, err := strconv.ParseUint(c.DefaultQuery("actor_id", "0"), 10, 32); err == nil {
+		filter.ActorID = uint(actorID)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid actor_id"})
+		return
+	}
+	if targetID, err := strconv.ParseUint(c.DefaultQuery("target_user_id", "0"), 10, 32); err == nil {
+		filter.TargetUserID = uint(targetID)
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid target_user_id"})
+		return
+	}
+
+	// Time range in RFC 3339, e.g. 2024-05-01T00:00:00Z
+	for param, dest := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param + ", use RFC 3339"})
+			return
+		}
+		*dest = t
+	}
+
+	events, total, err := services.ListAuditEvents(filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":    events,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetSecurityEvents - The current user's logins, password, email, 2FA and session changes
+func GetSecurityEvents(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "0"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 0 {
+		page = 0
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	events, hasMore, err := services.ListSecurityEvents(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events, "page": page, "has_more": hasMore})
+}
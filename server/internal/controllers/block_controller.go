@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// BlockUser - Block a user: neither of you sees the other's posts or can message the other
+func BlockUser(c *gin.Context) {
+	changeRelation(c, services.BlockUser, "User blocked successfully")
+}
+
+// UnblockUser - Remove a block
+func UnblockUser(c *gin.Context) {
+	changeRelation(c, services.UnblockUser, "User unblocked successfully")
+}
+
+// MuteUser - Hide a user's posts from your feed without them knowing
+func MuteUser(c *gin.Context) {
+	changeRelation(c, services.MuteUser, "User muted successfully")
+}
+
+// UnmuteUser - Show a muted user's posts in your feed again
+func UnmuteUser(c *gin.Context) {
+	changeRelation(c, services.UnmuteUser, "User unmuted successfully")
+}
+
+// changeRelation applies a block, mute or close friends change between the current user and the :id user
+func changeRelation(c *gin.Context, change func(userID, targetID uint) error, message string) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := change(userID, uint(targetID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// GetBlockedUsers - List the users the current user has blocked
+func GetBlockedUsers(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := services.ListBlockedUsers(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// GetMutedUsers - List the users the current user has muted
+func GetMutedUsers(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := services.ListMutedUsers(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
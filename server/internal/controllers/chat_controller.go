@@ -3,26 +3,38 @@ package controllers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Bauka07/SocialApp/internal/config"
 	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/middleware"
 	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
 	ws "github.com/Bauka07/SocialApp/internal/websocket"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// Clients can authenticate with Sec-WebSocket-Protocol: access_token, <jwt>
+	wsAuthSubprotocol = "access_token"
+	// How long a client has to send the {"type":"auth"} frame after connecting
+	wsAuthTimeout  = 10 * time.Second
+	wsAuthMaxFrame = 8 * 1024
+)
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
+	Subprotocols:    []string{wsAuthSubprotocol},
+	CheckOrigin:     checkWebSocketOrigin,
 }
 
 var Hub = ws.NewHub()
@@ -31,24 +43,48 @@ func init() {
 	go Hub.Run()
 }
 
-func WebSocketHandler(c *gin.Context) {
-	token := c.Query("token")
-	if token == "" {
-		log.Println("❌ WebSocket: No token provided")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token required"})
-		return
+// checkWebSocketOrigin enforces the ALLOWED_ORIGINS allowlist. Without an
+// allowlist only browser connections from the same host (any port, so the
+// dev server still works) are accepted.
+func checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// Non-browser clients don't send Origin
+		return true
 	}
 
-	log.Printf("🔑 WebSocket: Validating token: %s...", token[:min(20, len(token))])
+	if len(config.AllowedOrigins) == 0 {
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		return strings.EqualFold(u.Hostname(), host)
+	}
 
-	userID, err := validateTokenAndGetUserID(token)
-	if err != nil {
-		log.Printf("❌ WebSocket: Token validation failed: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token: " + err.Error()})
-		return
+	if !config.IsOriginAllowed(origin) {
+		log.Printf("❌ WebSocket: Origin not allowed: %s", origin)
+		return false
 	}
+	return true
+}
 
-	log.Printf("✅ WebSocket: Token valid for user ID: %d", userID)
+func WebSocketHandler(c *gin.Context) {
+	var userID uint
+
+	// Preferred: token passed as a subprotocol during the handshake
+	if token := tokenFromSubprotocols(websocket.Subprotocols(c.Request)); token != "" {
+		id, err := validateWebSocketToken(token)
+		if err != nil {
+			log.Printf("❌ WebSocket: Token validation failed: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+		userID = id
+	}
 
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -56,7 +92,19 @@ func WebSocketHandler(c *gin.Context) {
 		return
 	}
 
-	log.Printf("✅ WebSocket: Connection upgraded for user %d", userID)
+	// Fallback: the first frame must be {"type":"auth","token":"..."}
+	if userID == 0 {
+		userID, err = authenticateFirstFrame(conn)
+		if err != nil {
+			log.Printf("❌ WebSocket: Authentication failed: %v", err)
+			closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "authentication failed")
+			conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+			conn.Close()
+			return
+		}
+	}
+
+	log.Printf("✅ WebSocket: Connection authenticated for user %d", userID)
 
 	client := ws.NewClient(Hub, conn, userID)
 	Hub.Register(client)
@@ -67,11 +115,47 @@ func WebSocketHandler(c *gin.Context) {
 	go client.ReadPump()
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// tokenFromSubprotocols returns the value following the access_token subprotocol
+func tokenFromSubprotocols(protocols []string) string {
+	for i, p := range protocols {
+		if p == wsAuthSubprotocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
 	}
-	return b
+	return ""
+}
+
+// authenticateFirstFrame waits for an auth frame and validates its token
+func authenticateFirstFrame(conn *websocket.Conn) (uint, error) {
+	conn.SetReadLimit(wsAuthMaxFrame)
+	conn.SetReadDeadline(time.Now().Add(wsAuthTimeout))
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return 0, fmt.Errorf("no auth frame received: %w", err)
+	}
+
+	var frame struct {
+		Type  string `json:"type"`
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &frame); err != nil || frame.Type != "auth" || frame.Token == "" {
+		return 0, errors.New("first frame must be an auth frame")
+	}
+
+	userID, err := validateWebSocketToken(frame.Token)
+	if err != nil {
+		return 0, err
+	}
+
+	conn.SetReadDeadline(time.Time{})
+	conn.SetWriteDeadline(time.Now().Add(wsAuthTimeout))
+	if err := conn.WriteJSON(gin.H{"type": "authenticated", "user_id": userID}); err != nil {
+		return 0, fmt.Errorf("failed to acknowledge auth: %w", err)
+	}
+	conn.SetWriteDeadline(time.Time{})
+
+	return userID, nil
 }
 
 func getUserIDFromContext(c *gin.Context) (uint, error) {
@@ -105,6 +189,7 @@ func GetChats(c *gin.Context) {
 
 	var messages []models.Message
 	if err := database.DB.
+		Scopes(services.VisibleMessages(userID)).
 		Preload("ReplyTo").
 		Where("sender_id = ? OR receiver_id = ?", userID, userID).
 		Order("created_at DESC").
@@ -119,7 +204,7 @@ func GetChats(c *gin.Context) {
 		if msg.SenderID == userID && msg.DeletedForSender {
 			continue
 		}
-		if msg.ReceiverID == userID && msg.DeletedForReceiver {
+		if msg.ReceiverID == userID && (msg.DeletedForReceiver || msg.HiddenAt != nil) {
 			continue
 		}
 
@@ -143,14 +228,16 @@ func GetChats(c *gin.Context) {
 			continue
 		}
 
-		isOnline := Hub.IsUserOnline(partnerID)
+		// Blocked users don't see each other's presence
+		isOnline := Hub.IsUserOnline(partnerID) && !services.IsBlockedBetween(userID, partnerID)
 
 		unreadCount := int64(0)
 		if msg.ReceiverID == userID && !msg.IsRead {
 			unreadCount = 1
 		}
 		database.DB.Model(&models.Message{}).
-			Where("receiver_id = ? AND sender_id = ? AND is_read = ? AND deleted_for_receiver = ?",
+			Scopes(services.VisibleMessages(userID)).
+			Where("receiver_id = ? AND sender_id = ? AND is_read = ? AND deleted_for_receiver = ? AND hidden_at IS NULL",
 				userID, partnerID, false, false).
 			Count(&unreadCount)
 
@@ -224,6 +311,7 @@ func SearchUsers(c *gin.Context) {
 
 	var users []models.User
 	if err := database.DB.Select("id, username, email, image_url").
+		Scopes(services.VisibleUsers(currentUserID)).
 		Where("username LIKE ? AND id != ?", "%"+query+"%", currentUserID).
 		Limit(20).
 		Find(&users).Error; err != nil {
@@ -248,65 +336,13 @@ type UserResponse struct {
 	Online   bool   `json:"online"`
 }
 
-func validateTokenAndGetUserID(tokenString string) (uint, error) {
-	log.Printf("🔍 Validating token...")
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			log.Printf("❌ Invalid signing method: %v", token.Method)
-			return nil, errors.New("invalid signing method")
-		}
-		log.Printf("✅ Signing method valid")
-		return []byte(config.JWTSecret), nil
-	})
-
+// validateWebSocketToken parses a JWT with the same rules as AuthCheck
+func validateWebSocketToken(tokenString string) (uint, error) {
+	claims, err := middleware.ParseToken(tokenString)
 	if err != nil {
-		log.Printf("❌ Token parse error: %v", err)
 		return 0, err
 	}
-
-	log.Printf("✅ Token parsed successfully")
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		log.Printf("✅ Token is valid, extracting claims...")
-
-		if exp, ok := claims["exp"].(float64); ok {
-			expTime := time.Unix(int64(exp), 0)
-			now := time.Now()
-			log.Printf("Token expiration: %v (now: %v)", expTime, now)
-
-			if now.After(expTime) {
-				log.Printf("❌ Token expired")
-				return 0, errors.New("token expired")
-			}
-			log.Printf("✅ Token not expired")
-		} else {
-			log.Printf("⚠️ No expiration claim found")
-		}
-
-		if userIDStr, ok := claims["userID"].(string); ok {
-			if id, err := strconv.ParseUint(userIDStr, 10, 32); err == nil {
-				log.Printf("✅ User ID extracted from 'userID' (string): %d", uint(id))
-				return uint(id), nil
-			}
-		}
-
-		if userIDFloat, ok := claims["userID"].(float64); ok {
-			log.Printf("✅ User ID extracted from 'userID' (float): %d", uint(userIDFloat))
-			return uint(userIDFloat), nil
-		}
-
-		if userIDFloat, ok := claims["user_id"].(float64); ok {
-			log.Printf("✅ User ID extracted from 'user_id' (float): %d", uint(userIDFloat))
-			return uint(userIDFloat), nil
-		}
-
-		log.Printf("❌ userID/user_id claim not found or invalid type. Claims: %+v", claims)
-	} else {
-		log.Printf("❌ Token invalid or claims extraction failed")
-	}
-
-	return 0, errors.New("invalid token claims")
+	return claims.UserIDUint()
 }
 
 func GetMessages(c *gin.Context) {
@@ -331,6 +367,7 @@ func GetMessages(c *gin.Context) {
 
 	var messages []models.Message
 	if err := database.DB.Preload("ReplyTo").
+		Scopes(services.VisibleMessages(userID)).
 		Where(
 			"(sender_id = ? AND receiver_id = ?) OR (sender_id = ? AND receiver_id = ?)",
 			userID, otherUserID, otherUserID, userID,
@@ -345,7 +382,7 @@ func GetMessages(c *gin.Context) {
 		if msg.SenderID == userID && msg.DeletedForSender {
 			continue
 		}
-		if msg.ReceiverID == userID && msg.DeletedForReceiver {
+		if msg.ReceiverID == userID && (msg.DeletedForReceiver || msg.HiddenAt != nil) {
 			continue
 		}
 		filteredMessages = append(filteredMessages, msg)
@@ -359,14 +396,16 @@ func GetMessages(c *gin.Context) {
 	// Check count first before updating
 	var unreadCount int64
 	database.DB.Model(&models.Message{}).
-		Where("sender_id = ? AND receiver_id = ? AND is_read = ? AND deleted_for_receiver = ?",
+		Scopes(services.VisibleMessages(userID)).
+		Where("sender_id = ? AND receiver_id = ? AND is_read = ? AND deleted_for_receiver = ? AND hidden_at IS NULL",
 			otherUserID, userID, false, false).
 		Count(&unreadCount)
 
 	if unreadCount > 0 {
 		// Only update if there are unread messages
 		result := database.DB.Model(&models.Message{}).
-			Where("sender_id = ? AND receiver_id = ? AND is_read = ?", otherUserID, userID, false).
+			Scopes(services.VisibleMessages(userID)).
+			Where("sender_id = ? AND receiver_id = ? AND is_read = ? AND hidden_at IS NULL", otherUserID, userID, false).
 			Update("is_read", true)
 
 		if result.Error != nil {
@@ -379,57 +418,62 @@ func GetMessages(c *gin.Context) {
 	c.JSON(http.StatusOK, filteredMessages)
 }
 
-func EditMessage(c *gin.Context) {
+// SendMessage - REST equivalent of the WebSocket send_message event
+func SendMessage(c *gin.Context) {
 	userID, err := getUserIDFromContext(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	messageIDStr := c.Param("message_id")
-	messageID, err := strconv.ParseUint(messageIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
-		return
-	}
-
 	var req struct {
-		Content string `json:"content" binding:"required"`
+		ReceiverID uint   `json:"receiver_id" binding:"required"`
+		Content    string `json:"content" binding:"required"`
+		ReplyToID  *uint  `json:"reply_to_id"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Content is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "receiver_id and content are required"})
 		return
 	}
 
-	var message models.Message
-	if err := database.DB.First(&message, messageID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+	message, err := services.SendMessage(Hub, userID, req.ReceiverID, req.Content, req.ReplyToID)
+	if err != nil {
+		respondMessageError(c, err)
 		return
 	}
 
-	if message.SenderID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own messages"})
+	c.JSON(http.StatusCreated, message)
+}
+
+func EditMessage(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	message.Content = req.Content
-	if err := database.DB.Save(&message).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update message"})
+	messageIDStr := c.Param("message_id")
+	messageID, err := strconv.ParseUint(messageIDStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
 		return
 	}
 
-	notification := map[string]interface{}{
-		"type":    "message_edited",
-		"message": message,
+	var req struct {
+		Content string `json:"content" binding:"required"`
 	}
-	notificationJSON, err := json.Marshal(notification)
-	if err != nil {
-		log.Printf("Error marshaling notification: %v", err)
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Content is required"})
+		return
 	}
 
-	Hub.SendToUser(message.SenderID, notificationJSON)
-	Hub.SendToUser(message.ReceiverID, notificationJSON)
+	message, err := services.EditMessage(Hub, userID, uint(messageID), req.Content)
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
 
 	c.JSON(http.StatusOK, message)
 }
@@ -457,47 +501,24 @@ func DeleteMessage(c *gin.Context) {
 		return
 	}
 
-	var message models.Message
-	if err := database.DB.First(&message, messageID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+	if err := services.DeleteMessage(Hub, userID, uint(messageID), req.DeleteFor); err != nil {
+		respondMessageError(c, err)
 		return
 	}
 
-	if message.SenderID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own messages"})
-		return
-	}
-
-	if req.DeleteFor == "all" {
-		message.DeletedForSender = true
-		message.DeletedForReceiver = true
-
-		if err := database.DB.Save(&message).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
-			return
-		}
-
-		notification := map[string]interface{}{
-			"type":       "message_deleted",
-			"message_id": message.ID,
-		}
-		notificationJSON, err := json.Marshal(notification)
-		if err != nil {
-			log.Printf("Error marshaling notification: %v", err)
-		} else {
-			Hub.SendToUser(message.SenderID, notificationJSON)
-			Hub.SendToUser(message.ReceiverID, notificationJSON)
-		}
-	} else {
-		message.DeletedForSender = true
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
+}
 
-		if err := database.DB.Save(&message).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message"})
-			return
-		}
+// respondMessageError maps message service errors to HTTP statuses
+func respondMessageError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+	case errors.Is(err, services.ErrMessageForbidden), errors.Is(err, services.ErrUserBlocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
 }
 
 // FIXED: DeleteChat now properly handles deletion and prevents infinite loops
@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestCheckWebSocketOrigin(t *testing.T) {
+	previous := config.AllowedOrigins
+	t.Cleanup(func() { config.AllowedOrigins = previous })
+
+	tests := []struct {
+		name    string
+		allowed []string
+		host    string
+		origin  string
+		want    bool
+	}{
+		{name: "no origin header", host: "api.example.com", want: true},
+		{name: "same host", host: "api.example.com", origin: "https://api.example.com", want: true},
+		{name: "same host on another port", host: "localhost:8080", origin: "http://localhost:5173", want: true},
+		{name: "same host in another case", host: "API.example.com", origin: "https://api.EXAMPLE.com", want: true},
+		{name: "other host", host: "api.example.com", origin: "https://evil.example.net"},
+		{name: "host as a subdomain", host: "example.com", origin: "https://example.com.evil.net"},
+		{name: "malformed origin", host: "api.example.com", origin: "://api.example.com"},
+		{name: "allowlisted", allowed: []string{"https://app.example.com"}, host: "api.example.com", origin: "https://app.example.com", want: true},
+		{name: "allowlisted with trailing slash", allowed: []string{"https://app.example.com"}, host: "api.example.com", origin: "https://app.example.com/", want: true},
+		{name: "allowlist ignores the same host", allowed: []string{"https://app.example.com"}, host: "api.example.com", origin: "https://api.example.com"},
+		{name: "allowlist checks the scheme", allowed: []string{"https://app.example.com"}, host: "api.example.com", origin: "http://app.example.com"},
+		{name: "wildcard", allowed: []string{"*"}, host: "api.example.com", origin: "https://anywhere.example.org", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.AllowedOrigins = tt.allowed
+
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			r.Host = tt.host
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+
+			if got := checkWebSocketOrigin(r); got != tt.want {
+				t.Fatalf("checkWebSocketOrigin(%q from %q) = %v, want %v", tt.origin, tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenFromSubprotocols(t *testing.T) {
+	tests := []struct {
+		name      string
+		protocols []string
+		want      string
+	}{
+		{name: "none"},
+		{name: "token follows access_token", protocols: []string{"access_token", "abc"}, want: "abc"},
+		{name: "other protocols first", protocols: []string{"chat", "access_token", "abc"}, want: "abc"},
+		{name: "access_token without a value", protocols: []string{"access_token"}},
+		{name: "value without access_token", protocols: []string{"abc"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenFromSubprotocols(tt.protocols); got != tt.want {
+				t.Fatalf("tokenFromSubprotocols(%v) = %q, want %q", tt.protocols, got, tt.want)
+			}
+		})
+	}
+}
+
+// useTestSecret loads a keyring with only the legacy HS256 secret
+func useTestSecret(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_SIGNING_KID", "")
+	t.Setenv("JWT_SECRET", "s3cret")
+	config.InitConfig()
+}
+
+// firstFrameResult is what authenticateFirstFrame returned on the server side
+type firstFrameResult struct {
+	userID uint
+	err    error
+}
+
+// dialFirstFrame opens a WebSocket to a server that runs authenticateFirstFrame,
+// sends frame (if any) and returns the server's result and the client connection
+func dialFirstFrame(t *testing.T, frame string) (firstFrameResult, *websocket.Conn) {
+	t.Helper()
+
+	results := make(chan firstFrameResult, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			results <- firstFrameResult{err: err}
+			return
+		}
+		defer conn.Close()
+		userID, err := authenticateFirstFrame(conn)
+		results <- firstFrameResult{userID: userID, err: err}
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if frame != "" {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			t.Fatalf("send first frame: %v", err)
+		}
+	}
+
+	select {
+	case result := <-results:
+		return result, conn
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not finish authenticating")
+		return firstFrameResult{}, nil
+	}
+}
+
+func TestAuthenticateFirstFrame(t *testing.T) {
+	newTestDB(t)
+	useTestSecret(t)
+
+	user, session := createTestUser(t, "alice")
+	token, err := middleware.CreateToken(*user, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := middleware.CreatePurposeToken(*user, middleware.PurposeTwoFactor, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revokedUser, revokedSession := createTestUser(t, "bob")
+	revokedToken, err := middleware.CreateToken(*revokedUser, revokedSession.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(revokedSession).Update("revoked_at", time.Now())
+
+	bannedUser, bannedSession := createTestUser(t, "carol")
+	bannedToken, err := middleware.CreateToken(*bannedUser, bannedSession.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(bannedUser).Update("banned_at", time.Now())
+
+	tests := []struct {
+		name   string
+		frame  string
+		wantID uint
+	}{
+		{name: "valid token", frame: `{"type":"auth","token":"` + token + `"}`, wantID: user.ID},
+		{name: "not json", frame: "hello"},
+		{name: "not an auth frame", frame: `{"type":"message","token":"` + token + `"}`},
+		{name: "empty token", frame: `{"type":"auth","token":""}`},
+		{name: "garbage token", frame: `{"type":"auth","token":"not-a-jwt"}`},
+		{name: "purpose token", frame: `{"type":"auth","token":"` + challenge + `"}`},
+		{name: "revoked session", frame: `{"type":"auth","token":"` + revokedToken + `"}`},
+		{name: "banned account", frame: `{"type":"auth","token":"` + bannedToken + `"}`},
+		{name: "oversized frame", frame: `{"type":"auth","token":"` + strings.Repeat("a", wsAuthMaxFrame) + `"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, conn := dialFirstFrame(t, tt.frame)
+
+			if tt.wantID == 0 {
+				if result.err == nil {
+					t.Fatalf("authenticated as user %d, want an error", result.userID)
+				}
+				return
+			}
+			if result.err != nil || result.userID != tt.wantID {
+				t.Fatalf("got user %d, error %v; want user %d", result.userID, result.err, tt.wantID)
+			}
+
+			var ack struct {
+				Type   string `json:"type"`
+				UserID uint   `json:"user_id"`
+			}
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			if err := conn.ReadJSON(&ack); err != nil {
+				t.Fatalf("read acknowledgement: %v", err)
+			}
+			if ack.Type != "authenticated" || ack.UserID != tt.wantID {
+				t.Fatalf("acknowledgement = %+v", ack)
+			}
+		})
+	}
+}
+
+func TestWebSocketHandlerRejectsBadSubprotocolToken(t *testing.T) {
+	newTestDB(t)
+	useTestSecret(t)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ws", WebSocketHandler)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	dialer := websocket.Dialer{Subprotocols: []string{wsAuthSubprotocol, "not-a-jwt"}}
+	conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err == nil {
+		conn.Close()
+		t.Fatal("handshake with an invalid token should fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("handshake response = %v, want 401", resp)
+	}
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AddCloseFriend - Let a user see the current user's close friends posts
+func AddCloseFriend(c *gin.Context) {
+	changeRelation(c, services.AddCloseFriend, "Close friend added successfully")
+}
+
+// RemoveCloseFriend - Take a user off the current user's close friends list
+func RemoveCloseFriend(c *gin.Context) {
+	changeRelation(c, services.RemoveCloseFriend, "Close friend removed successfully")
+}
+
+// GetCloseFriends - List the current user's close friends
+func GetCloseFriends(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := services.ListCloseFriends(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type VerifyEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Code  string `json:"code" binding:"required,len=6"`
+}
+
+// VerifyEmail confirms a new account's email and logs the user in
+func VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	email := strings.TrimSpace(req.Email)
+	code := strings.TrimSpace(req.Code)
+
+	user, err := services.VerifySignupEmail(email, code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := services.StartSession(*user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	services.RecordAudit(models.AuditEmailVerified, services.AuditInfo{
+		ActorID:   user.ID,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}, user.ID, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Email verified successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          gin.H{"id": user.ID},
+	})
+}
+
+// ResendVerification sends a new signup verification code
+func ResendVerification(c *gin.Context) {
+	var req ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	if err := services.ResendSignupVerification(strings.TrimSpace(req.Email), c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please try again later"})
+			return
+		}
+		log.Printf("❌ Error resending verification: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process request"})
+		return
+	}
+
+	// Same answer whether or not the account exists
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If an unverified account exists with this email, you will receive a new code",
+	})
+}
+
+// ConfirmEmailChange applies a pending email change
+func ConfirmEmailChange(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		Code string `json:"code" binding:"required,len=6"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	user, err := services.ConfirmEmailChange(userID, strings.TrimSpace(req.Code))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditEmailChanged, userID, map[string]interface{}{"email": user.Email})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "email updated successfully",
+		"user":    user,
+	})
+}
+
+// CancelEmailChange drops a pending email change
+func CancelEmailChange(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := services.CancelEmailChange(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditEmailChangeCancelled, userID, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "email change cancelled"})
+}
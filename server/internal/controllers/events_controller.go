@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/services"
+	ws "github.com/Bauka07/SocialApp/internal/websocket"
+	"github.com/gin-gonic/gin"
+)
+
+// Proxies tend to drop idle connections, so send a comment line periodically
+const sseKeepAliveInterval = 25 * time.Second
+
+// CreateEventTicket - Issue a one-time ticket for opening the event stream
+func CreateEventTicket(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	accessTokenID := c.GetUint("accessTokenID")
+	ticket, err := services.IssueEventTicket(userID, getSessionIDFromContext(c), accessTokenID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue event ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket})
+}
+
+// StreamEvents - Server-Sent Events fallback for clients that can't use WebSockets.
+// Streams the same hub events (new_message, message_edited, ...) as /ws.
+// Authenticated by ?ticket= from CreateEventTicket, since EventSource can't
+// send an Authorization header.
+func StreamEvents(c *gin.Context) {
+	userID, err := services.RedeemEventTicket(c.Query("ticket"))
+	if err != nil {
+		var restricted *middleware.AccountRestrictedError
+		if errors.As(err, &restricted) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "account_restricted": true})
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	client := ws.NewSSEClient(userID)
+	Hub.Register(client)
+	defer Hub.Unregister(client)
+
+	log.Printf("✅ SSE: Stream opened for user %d", userID)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case message, ok := <-client.Events():
+			if !ok {
+				return false
+			}
+
+			// Use the hub message type as the SSE event name
+			var envelope struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(message, &envelope); err != nil || envelope.Type == "" {
+				envelope.Type = "message"
+			}
+
+			c.SSEvent(envelope.Type, string(message))
+			return true
+
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return false
+			}
+			return true
+
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+
+	log.Printf("❌ SSE: Stream closed for user %d", userID)
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetFilterRules - List the content filter rules with how often each matched
+func GetFilterRules(c *gin.Context) {
+	rules, err := services.ListFilterRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// CreateFilterRule - Add a word, regex or domain rule that rejects, holds or masks content
+func CreateFilterRule(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req services.FilterRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	rule, err := services.CreateFilterRule(actorID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditFilterRuleCreated, 0, map[string]interface{}{"rule_id": rule.ID, "kind": rule.Kind, "pattern": rule.Pattern, "action": rule.Action})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Filter rule created successfully", "rule": rule})
+}
+
+// UpdateFilterRule - Change a rule, or enable/disable it
+func UpdateFilterRule(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+
+	var req services.FilterRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	rule, err := services.UpdateFilterRule(actorID, uint(ruleID), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditFilterRuleUpdated, 0, map[string]interface{}{"rule_id": rule.ID, "kind": rule.Kind, "pattern": rule.Pattern, "action": rule.Action, "enabled": rule.Enabled})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Filter rule updated successfully", "rule": rule})
+}
+
+// DeleteFilterRule - Remove a rule
+func DeleteFilterRule(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+
+	if err := services.DeleteFilterRule(actorID, uint(ruleID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditFilterRuleDeleted, 0, map[string]interface{}{"rule_id": ruleID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Filter rule deleted successfully"})
+}
+
+// TestContentFilter - Show which rules match a sample text and what would be saved
+func TestContentFilter(c *gin.Context) {
+	var req struct {
+		Text string `json:"text" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "text is required"})
+		return
+	}
+
+	result, matches := services.TestContentFilter(req.Text)
+	c.JSON(http.StatusOK, gin.H{"result": result, "matches": matches})
+}
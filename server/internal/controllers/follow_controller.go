@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdatePrivacy - Make the current user's account private or public
+func UpdatePrivacy(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		IsPrivate *bool `json:"is_private" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "is_private is required"})
+		return
+	}
+
+	if err := services.SetAccountPrivacy(userID, *req.IsPrivate); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Privacy updated successfully", "is_private": *req.IsPrivate})
+}
+
+// FollowUser - Follow a user, or request to when their account is private
+func FollowUser(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	follow, err := services.FollowUser(Hub, userID, targetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "User followed successfully"
+	if !follow.IsAccepted() {
+		message = "Follow request sent"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message, "status": follow.Status})
+}
+
+// UnfollowUser - Stop following a user or withdraw a follow request
+func UnfollowUser(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	targetID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.UnfollowUser(userID, targetID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User unfollowed successfully"})
+}
+
+// RemoveFollower - Take someone off the current user's followers
+func RemoveFollower(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	followerID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.RemoveFollower(userID, followerID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follower removed successfully"})
+}
+
+// GetFollowRequests - Pending requests to follow the current user, and the ones they sent
+func GetFollowRequests(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	received, err := services.ListFollowRequests(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sent, err := services.ListSentFollowRequests(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": received, "sent": sent})
+}
+
+// ApproveFollowRequest - Let a user who asked follow the current user
+func ApproveFollowRequest(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.ApproveFollowRequest(Hub, userID, requesterID); err != nil {
+		respondFollowRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follow request approved"})
+}
+
+// DenyFollowRequest - Turn down a request to follow the current user
+func DenyFollowRequest(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	requesterID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DenyFollowRequest(userID, requesterID); err != nil {
+		respondFollowRequestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follow request denied"})
+}
+
+// GetFollowers - A user's followers, hidden from non-followers of a private account
+func GetFollowers(c *gin.Context) {
+	listConnections(c, services.ListFollowers, "followers")
+}
+
+// GetFollowing - The accounts a user follows, hidden from non-followers of a private account
+func GetFollowing(c *gin.Context) {
+	listConnections(c, services.ListFollowing, "following")
+}
+
+// listConnections answers with list(viewer, :id) under key
+func listConnections(c *gin.Context, list func(viewerID, ownerID uint) ([]services.RelatedUser, error), key string) {
+	viewerID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerID, ok := parseTargetUserID(c)
+	if !ok {
+		return
+	}
+
+	users, err := list(viewerID, ownerID)
+	if err != nil {
+		if errors.Is(err, services.ErrPrivateAccount) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{key: users})
+}
+
+// respondFollowRequestError maps follow request errors to status codes
+func respondFollowRequestError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrFollowRequestNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetIdentities - List the external logins linked to the current user
+func GetIdentities(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	identities, err := services.ListIdentities(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+// StartIdentityLink - Get the provider URL to open for linking it to this account
+func StartIdentityLink(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	authURL, err := services.BeginOIDCLink(c.Request.Context(), c.Param("provider"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+// ConfirmIdentityLink - Finish linking with the token the provider callback handed to the frontend
+func ConfirmIdentityLink(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		LinkToken string `json:"link_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "link_token is required"})
+		return
+	}
+
+	identity, err := services.ConfirmIdentityLink(userID, req.LinkToken)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditIdentityLinked, userID, map[string]interface{}{"provider": identity.Provider})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account linked successfully", "identity": identity})
+}
+
+// LinkGoogleIdentity - Link a Google account using an ID token from the client-side flow
+func LinkGoogleIdentity(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req GoogleTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id_token is required"})
+		return
+	}
+
+	googleUser, err := services.VerifyGoogleIDToken(req.IDToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	identity, err := services.LinkIdentity(userID, &services.ExternalIdentity{
+		Provider:      "google",
+		Subject:       googleUser.Subject,
+		Email:         googleUser.Email,
+		EmailVerified: googleUser.EmailVerified,
+	})
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditIdentityLinked, userID, map[string]interface{}{"provider": identity.Provider})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account linked successfully", "identity": identity})
+}
+
+// UnlinkIdentity - Remove a linked login, keeping at least one way to log in
+func UnlinkIdentity(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	identityID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid identity ID"})
+		return
+	}
+
+	if err := services.UnlinkIdentity(userID, uint(identityID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditIdentityUnlinked, userID, map[string]interface{}{"identity_id": identityID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account unlinked successfully"})
+}
+
+// SetPassword - Add password login to an account created through a provider
+func SetPassword(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req struct {
+		NewPassword string `json:"new_password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new_password is required"})
+		return
+	}
+
+	if err := services.SetPassword(userID, req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditPasswordSet, userID, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password set successfully"})
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS - Public keys other services can use to verify our access tokens
+func JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, config.Keys().JWKS())
+}
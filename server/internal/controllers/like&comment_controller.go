@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -64,9 +65,15 @@ func GetPostLikes(c *gin.Context) {
 		return
 	}
 
-	likes, err := services.GetPostLikes(uint(postID))
+	userID, err := getUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	likes, err := services.GetPostLikes(uint(postID), userID)
+	if err != nil {
+		respondPostReadError(c, err)
 		return
 	}
 
@@ -132,9 +139,15 @@ func GetPostComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := services.GetPostComments(uint(postID))
+	userID, err := getUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	comments, err := services.GetPostComments(uint(postID), userID)
+	if err != nil {
+		respondPostReadError(c, err)
 		return
 	}
 
@@ -258,3 +271,12 @@ func GetUserLikedPosts(c *gin.Context) {
 		"posts": posts,
 	})
 }
+
+// respondPostReadError answers 404 for posts the viewer can't see, 500 otherwise
+func respondPostReadError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrPostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateCookie binds a started login to the browser that started it,
+// so a callback URL from someone else's login can't be replayed here
+const oauthStateCookie = "oauth_state"
+
+// ListLoginProviders - Providers the frontend can show login buttons for
+func ListLoginProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": services.ListLoginProviders()})
+}
+
+// OIDCLogin - Redirect the browser to the provider's login page
+func OIDCLogin(c *gin.Context) {
+	startExternalLogin(c, c.Param("provider"))
+}
+
+// OIDCCallback - Provider redirects back here with an authorization code
+func OIDCCallback(c *gin.Context) {
+	completeExternalLogin(c, c.Param("provider"))
+}
+
+func startExternalLogin(c *gin.Context, provider string) {
+	authURL, state, err := services.BeginOIDCLogin(c.Request.Context(), provider)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setOAuthStateCookie(c, state, 600)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func completeExternalLogin(c *gin.Context, provider string) {
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(oauthStateCookie)
+	setOAuthStateCookie(c, "", -1)
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		respondExternalLoginError(c, http.StatusBadRequest, providerErr)
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" || state == "" {
+		respondExternalLoginError(c, http.StatusBadRequest, "code not found")
+		return
+	}
+
+	browserBound := cookieState != "" && subtle.ConstantTimeCompare([]byte(cookieState), []byte(state)) == 1
+
+	result, err := services.CompleteOIDCCallback(c.Request.Context(), provider, state, code, browserBound)
+	if err != nil {
+		respondExternalLoginError(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	if result.LinkToken != "" {
+		// The signed-in frontend confirms with POST /users/identities/confirm
+		respondExternalLogin(c, gin.H{"link_token": result.LinkToken, "provider": provider})
+		return
+	}
+
+	finishExternalLogin(c, *result.User)
+}
+
+// finishExternalLogin issues a session (or a 2FA challenge) and hands it to the frontend
+func finishExternalLogin(c *gin.Context, user models.User) {
+	if user.TwoFactorEnabled {
+		challenge, err := services.CreateLoginChallenge(user)
+		if err != nil {
+			respondExternalLoginError(c, http.StatusInternalServerError, "failed to create token")
+			return
+		}
+		respondExternalLogin(c, gin.H{
+			"message":             "Two-factor authentication required",
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		})
+		return
+	}
+
+	tokens, err := services.StartSession(user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		var restricted *middleware.AccountRestrictedError
+		if errors.As(err, &restricted) {
+			respondExternalLoginError(c, http.StatusForbidden, err.Error())
+			return
+		}
+		respondExternalLoginError(c, http.StatusInternalServerError, "failed to create token")
+		return
+	}
+
+	respondExternalLogin(c, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          gin.H{"id": user.ID},
+	})
+}
+
+// respondExternalLogin answers with result as JSON when no FRONTEND_URL is
+// configured. Otherwise the browser goes back to the frontend with a one-time
+// code it trades for result at POST /auth/exchange.
+func respondExternalLogin(c *gin.Context, result gin.H) {
+	if config.FrontendURL == "" {
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	code, err := services.StoreLoginHandoff(result)
+	if err != nil {
+		respondExternalLoginError(c, http.StatusInternalServerError, "failed to complete login")
+		return
+	}
+	redirectToFrontend(c, url.Values{"code": {code}})
+}
+
+// ExchangeLoginCode - Trade the code from an external login redirect for its tokens
+func ExchangeLoginCode(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code is required"})
+		return
+	}
+
+	result, err := services.RedeemLoginHandoff(req.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// respondExternalLoginError sends the browser back to the frontend with the
+// error, since the callback is a top-level navigation
+func respondExternalLoginError(c *gin.Context, status int, message string) {
+	if config.FrontendURL == "" {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+	redirectToFrontend(c, url.Values{"error": {message}})
+}
+
+// redirectToFrontend sends the browser to the frontend's OAuth callback page
+func redirectToFrontend(c *gin.Context, params url.Values) {
+	c.Redirect(http.StatusFound, config.FrontendURL+"/auth/callback?"+params.Encode())
+}
+
+func setOAuthStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	// Lax so the cookie is sent on the provider's top-level redirect back
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, value, maxAge, "/", "", secure, true)
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PasskeyFinishRequest carries the browser's PublicKeyCredential, serialized
+// as JSON, for the ceremony started by the matching begin call
+type PasskeyFinishRequest struct {
+	CeremonyID string          `json:"ceremony_id" binding:"required"`
+	Name       string          `json:"name"`
+	Credential json.RawMessage `json:"credential" binding:"required"`
+}
+
+// BeginPasskeyRegistration - Options for navigator.credentials.create()
+func BeginPasskeyRegistration(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	options, ceremonyID, err := services.BeginPasskeyRegistration(userID)
+	if err != nil {
+		respondPasskeyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
+
+// FinishPasskeyRegistration - Verify the new credential and save it to the account
+func FinishPasskeyRegistration(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req PasskeyFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ceremony_id and credential are required"})
+		return
+	}
+
+	passkey, err := services.FinishPasskeyRegistration(userID, req.CeremonyID, req.Name, req.Credential)
+	if err != nil {
+		respondPasskeyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	recordAudit(c, models.AuditPasskeyAdded, userID, map[string]interface{}{"passkey_id": passkey.ID})
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Passkey added successfully", "passkey": passkey})
+}
+
+// BeginPasskeyLogin - Options for navigator.credentials.get()
+func BeginPasskeyLogin(c *gin.Context) {
+	options, ceremonyID, err := services.BeginPasskeyLogin()
+	if err != nil {
+		respondPasskeyError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ceremony_id": ceremonyID, "options": options})
+}
+
+// FinishPasskeyLogin - Verify the assertion and start a session
+func FinishPasskeyLogin(c *gin.Context) {
+	var req PasskeyFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ceremony_id and credential are required"})
+		return
+	}
+
+	user, err := services.FinishPasskeyLogin(req.CeremonyID, req.Credential, loginAttemptInfo(c))
+	if err != nil {
+		respondPasskeyError(c, http.StatusUnauthorized, err)
+		return
+	}
+
+	// The passkey was unlocked with a PIN or biometric, so it already covers 2FA
+	tokens, err := services.StartSession(*user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Logged in successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          gin.H{"id": user.ID},
+	})
+}
+
+// GetPasskeys - List the passkeys registered to the current user
+func GetPasskeys(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	passkeys, err := services.ListPasskeys(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"passkeys": passkeys})
+}
+
+// DeletePasskey - Remove a passkey, keeping at least one way to log in
+func DeletePasskey(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	passkeyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid passkey ID"})
+		return
+	}
+
+	if err := services.DeletePasskey(userID, uint(passkeyID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recordAudit(c, models.AuditPasskeyRemoved, userID, map[string]interface{}{"passkey_id": passkeyID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Passkey removed successfully"})
+}
+
+// respondPasskeyError answers 404 when passkeys aren't configured, and status otherwise
+func respondPasskeyError(c *gin.Context, status int, err error) {
+	if errors.Is(err, services.ErrPasskeysDisabled) {
+		status = http.StatusNotFound
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
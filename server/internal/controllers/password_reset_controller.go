@@ -1,10 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 
+	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/Bauka07/SocialApp/internal/services"
 	"github.com/Bauka07/SocialApp/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -22,7 +24,7 @@ type VerifyCodeRequest struct {
 type ResetPasswordRequest struct {
 	Email       string `json:"email" binding:"required,email"`
 	Code        string `json:"code" binding:"required,len=6"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"`
 }
 
 // ForgotPassword initiates the password reset process
@@ -39,13 +41,19 @@ func ForgotPassword(c *gin.Context) {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 	log.Printf("📧 Forgot password request for: %s", email)
 
-	if err := services.InitiatePasswordReset(email); err != nil {
+	if err := services.InitiatePasswordReset(email, c.ClientIP()); err != nil {
+		if errors.Is(err, services.ErrTooManyRequests) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many reset requests, please try again later"})
+			return
+		}
 		// Log error but don't expose details to prevent user enumeration
 		log.Printf("❌ Error in InitiatePasswordReset: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process request"})
 		return
 	}
 
+	recordAudit(c, models.AuditPasswordResetRequested, services.AuditUserIDByEmail(email), map[string]interface{}{"email": email})
+
 	log.Printf("✅ Password reset initiated successfully for: %s", email)
 	// Always return success to prevent email enumeration attacks
 	c.JSON(http.StatusOK, gin.H{
@@ -89,17 +97,14 @@ func ResetPassword(c *gin.Context) {
 	email := strings.ToLower(strings.TrimSpace(req.Email))
 	code := strings.TrimSpace(req.Code)
 
-	// Validate password strength
-	if len(req.NewPassword) < 6 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password must be at least 6 characters"})
-		return
-	}
-
+	// Password rules are enforced by the service, same as registration
 	if err := services.ResetPassword(email, code, req.NewPassword); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	recordAudit(c, models.AuditPasswordReset, services.AuditUserIDByEmail(email), map[string]interface{}{"email": email})
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Password reset successfully",
 	})
@@ -32,8 +32,9 @@ func CreatePost(c *gin.Context) {
 
 	title := c.PostForm("title")
 	content := c.PostForm("content")
+	visibility := c.PostForm("visibility")
 
-	post, err := services.CreatePost(uint(userID), title, content)
+	post, err := services.CreatePost(uint(userID), title, content, visibility)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -100,7 +101,7 @@ func GetMyPosts(c *gin.Context) {
 		return
 	}
 
-	posts, err := services.GetUserPostsWithStats(uint(userID), uint(userID))
+	posts, _, err := services.GetUserPostsWithStats(uint(userID), uint(userID), 0, 0)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,7 +121,10 @@ func GetPostByID(c *gin.Context) {
 		return
 	}
 
-	post, err := services.GetPostByID(uint(postID))
+	// The route is public; anonymous viewers get ID 0 and only see public accounts' posts
+	viewerID, _ := getUserIDFromContext(c)
+
+	post, err := services.GetPostByID(uint(postID), viewerID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -180,6 +184,40 @@ func UpdatePost(c *gin.Context) {
 	})
 }
 
+// UpdatePostVisibility - Change who can see a post: public, followers, close_friends or only_me
+func UpdatePostVisibility(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid post ID"})
+		return
+	}
+
+	var req struct {
+		Visibility string `json:"visibility" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "visibility is required"})
+		return
+	}
+
+	post, err := services.SetPostVisibility(uint(postID), userID, req.Visibility)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "post visibility updated successfully",
+		"post":    post,
+	})
+}
+
 // DeletePost - Delete a post
 func DeletePost(c *gin.Context) {
 	userVal, exists := c.Get("userID")
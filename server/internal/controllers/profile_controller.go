@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPublicProfile - A user's public profile by username, with a page of their posts
+func GetPublicProfile(c *gin.Context) {
+	// The route is public; anonymous viewers get ID 0
+	viewerID, _ := getUserIDFromContext(c)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "0"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	if page < 0 {
+		page = 0
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 10
+	}
+
+	// /users/:id — the segment is a username (see user_routes.go)
+	profile, err := services.GetPublicProfile(viewerID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	posts := []map[string]interface{}{}
+	hasMore := false
+	if profile.CanViewContent {
+		posts, hasMore, err = services.GetUserPostsWithStats(profile.ID, viewerID, page, pageSize)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":     profile,
+		"posts":    posts,
+		"page":     page,
+		"has_more": hasMore,
+	})
+}
+
+// UpdateProfileDetails - Change the current user's display name, bio, location or website
+func UpdateProfileDetails(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req services.ProfileDetails
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	user, err := services.UpdateProfileDetails(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "profile updated successfully",
+		"user":    user,
+	})
+}
+
+// UploadCoverImage - Set the current user's profile cover image
+func UploadCoverImage(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no image uploaded"})
+		return
+	}
+
+	if err := services.ValidateImageFile(fileHeader); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to open image"})
+		return
+	}
+	defer src.Close()
+
+	url, err := services.UploadCoverImage(src, fmt.Sprintf("cover_%d", userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "upload failed"})
+		return
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Update("cover_image_url", url).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update cover image"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "cover image uploaded successfully",
+		"cover_image_url": url,
+	})
+}
@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateReportRequest - Reason is one of models.ReportReasons
+type CreateReportRequest struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details"`
+}
+
+// ReportPost - Report a post to the moderators
+func ReportPost(c *gin.Context) {
+	createReport(c, models.ReportTargetPost, "id")
+}
+
+// ReportComment - Report a comment to the moderators
+func ReportComment(c *gin.Context) {
+	createReport(c, models.ReportTargetComment, "id")
+}
+
+// ReportMessage - Report a message you received to the moderators
+func ReportMessage(c *gin.Context) {
+	createReport(c, models.ReportTargetMessage, "message_id")
+}
+
+// ReportUser - Report an account to the moderators
+func ReportUser(c *gin.Context) {
+	createReport(c, models.ReportTargetUser, "id")
+}
+
+// createReport files a report against the target identified by the param URL parameter
+func createReport(c *gin.Context, targetType, param string) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + targetType + " ID"})
+		return
+	}
+
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	report, err := services.CreateReport(userID, targetType, uint(targetID), req.Reason, req.Details)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Thanks, our moderators will review your report", "report": report})
+}
+
+// GetMyReports - List the reports the current user has filed and the reasons they can pick
+func GetMyReports(c *gin.Context) {
+	userID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	reports, err := services.ListMyReports(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reports": reports, "reasons": services.ListReportReasons()})
+}
+
+// GetReportQueue - The moderation queue, filtered by status, target type and assignee
+func GetReportQueue(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "0"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 0 {
+		page = 0
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	filter := services.ReportQueueFilter{
+		Status:     c.Query("status"),
+		TargetType: c.Query("type"),
+		Assignee:   c.Query("assignee"),
+	}
+
+	reports, total, err := services.ListReports(actorID, filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports":   reports,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// GetReport - A report with the reported content
+func GetReport(c *gin.Context) {
+	reportID, ok := parseReportID(c)
+	if !ok {
+		return
+	}
+
+	report, err := services.GetReport(reportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// AssignReport - Assign a report to a moderator (yourself when assignee_id is omitted)
+func AssignReport(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	reportID, ok := parseReportID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AssigneeID *uint `json:"assignee_id"`
+		Unassign   bool  `json:"unassign"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	assigneeID := req.AssigneeID
+	if assigneeID == nil {
+		assigneeID = &actorID
+	}
+	if req.Unassign {
+		assigneeID = nil
+	}
+
+	report, err := services.AssignReport(actorID, reportID, assigneeID)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	recordAudit(c, models.AuditReportAssigned, report.TargetUserID, map[string]interface{}{"report_id": report.ID, "assignee_id": report.AssigneeID})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report assigned successfully", "report": report})
+}
+
+// ResolveReport - Act on a report (hide, delete, warn, suspend) or dismiss it
+func ResolveReport(c *gin.Context) {
+	actorID, err := getUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	reportID, ok := parseReportID(c)
+	if !ok {
+		return
+	}
+
+	var req services.ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action is required"})
+		return
+	}
+
+	report, err := services.ResolveReport(Hub, actorID, reportID, req)
+	if err != nil {
+		respondReportError(c, err)
+		return
+	}
+
+	recordAudit(c, models.AuditReportResolved, report.TargetUserID, map[string]interface{}{"report_id": report.ID, "action": report.Resolution})
+
+	switch report.Resolution {
+	case models.ReportActionSuspendUser:
+		Hub.DisconnectUser(report.TargetUserID, "account_suspended")
+	case models.ReportActionShadowBanUser:
+		Hub.SetShadowBanned(report.TargetUserID, true)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Report resolved successfully", "report": report})
+}
+
+// parseReportID reads the :id param, answering 400 when it isn't a valid ID
+func parseReportID(c *gin.Context) (uint, bool) {
+	reportID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid report ID"})
+		return 0, false
+	}
+	return uint(reportID), true
+}
+
+// respondReportError maps moderation queue errors to status codes
+func respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotModerator):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -1,19 +1,18 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/Bauka07/SocialApp/internal/database"
-	"github.com/Bauka07/SocialApp/internal/middleware"
 	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/Bauka07/SocialApp/internal/services"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
-	"golang.org/x/oauth2"
-	"gorm.io/gorm"
 )
 
 // -------------- HELPER FUNCTIONS --------------
@@ -23,6 +22,24 @@ func generateUsername(email string) string {
 	return parts[0]
 }
 
+func loginAttemptInfo(c *gin.Context) services.LoginAttemptInfo {
+	return services.LoginAttemptInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// respondLoginError answers 429 with Retry-After while logins are locked, and status otherwise
+func respondLoginError(c *gin.Context, status int, err error) {
+	var locked *services.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
 // -------------- STANDARD AUTH --------------
 
 // Register user without reCAPTCHA (for internal use)
@@ -45,21 +62,15 @@ func Register(c *gin.Context) {
 		Username: req.Username,
 		Email:    req.Email,
 		Password: string(hashed),
-		Provider: "local",
 	}
-	if err := database.DB.Create(&user).Error; err != nil {
+	if err := services.RegisterLocalUser(&user); err != nil {
 		c.JSON(400, gin.H{"error": "Could not create user"})
 		return
 	}
-	token, err := middleware.CreateToken(user)
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create token"})
-		return
-	}
 	c.JSON(201, gin.H{
-		"message": "User registered successfully",
-		"token":   token,
-		"user":    gin.H{"id": user.ID},
+		"message":               "User registered successfully. Check your email for a verification code",
+		"verification_required": true,
+		"user":                  gin.H{"id": user.ID},
 	})
 }
 
@@ -102,23 +113,17 @@ func RegisterWithRecaptcha(c *gin.Context) {
 	}
 
 	user.Password = string(hashed)
-	user.Provider = "local"
 
-	if err := database.DB.Create(&user).Error; err != nil {
+	// Account stays unverified (and cannot log in) until the emailed code is confirmed
+	if err := services.RegisterLocalUser(&user); err != nil {
 		c.JSON(400, gin.H{"error": "Could not create user"})
 		return
 	}
 
-	token, err := middleware.CreateToken(user)
-	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create token"})
-		return
-	}
-
 	c.JSON(201, gin.H{
-		"message": "User registered successfully",
-		"token":   token,
-		"user":    gin.H{"id": user.ID},
+		"message":               "User registered successfully. Check your email for a verification code",
+		"verification_required": true,
+		"user":                  gin.H{"id": user.ID},
 	})
 }
 
@@ -147,176 +152,146 @@ func LoginWithRecaptcha(c *gin.Context) {
 		Password: req.Password,
 	}
 
-	loggedInUser, err := services.LEH(&user)
+	loggedInUser, err := services.LEH(&user, loginAttemptInfo(c))
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		respondLoginError(c, 400, err)
 		return
 	}
 
-	token, err := middleware.CreateToken(*loggedInUser)
+	if loggedInUser.TwoFactorEnabled {
+		challenge, err := services.CreateLoginChallenge(*loggedInUser)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to create token"})
+			return
+		}
+		respondTwoFactorRequired(c, challenge)
+		return
+	}
+
+	tokens, err := services.StartSession(*loggedInUser, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		c.JSON(500, gin.H{"error": "Failed to create token"})
+		respondSessionError(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"message": "Logged in successfully",
-		"token":   token,
-		"user":    gin.H{"id": loggedInUser.ID},
+		"message":       "Logged in successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          gin.H{"id": loggedInUser.ID},
 	})
 }
 
 // -------------- GOOGLE OAUTH --------------
 
-// GoogleLogin - Server-side OAuth flow (generates OAuth URL)
+// GoogleLogin - Server-side OAuth flow, kept at its original path for GOOGLE_REDIRECT_URL
 func GoogleLogin(c *gin.Context) {
-	url := services.GoogleOAuthConfig.AuthCodeURL("state", oauth2.AccessTypeOffline)
-	c.JSON(http.StatusOK, gin.H{"url": url})
+	startExternalLogin(c, "google")
 }
 
 // GoogleCallback - Server-side OAuth callback
 func GoogleCallback(c *gin.Context) {
-	code := c.Query("code")
-	if code == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "code not found"})
-		return
-	}
-
-	userInfo, err := services.GetGoogleUserInfo(code)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	var user models.User
-	result := database.DB.Where("provider = ? AND provider_id = ?", "google", userInfo.ID).First(&user)
-
-	if result.Error == gorm.ErrRecordNotFound {
-		// Create new user
-		username := generateUsername(userInfo.Email)
-		password := services.GenerateRandomPassword(16)
-		hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-
-		user = models.User{
-			Username:   username,
-			Email:      userInfo.Email,
-			Password:   string(hashedPassword),
-			ImageURL:   userInfo.Picture,
-			Provider:   "google",
-			ProviderID: userInfo.ID,
-		}
-
-		if err := database.DB.Create(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
-			return
-		}
-	} else if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
-		return
-	}
-
-	token, err := middleware.CreateToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create token"})
-		return
-	}
-
-	// Redirect to frontend with token
-	c.Redirect(http.StatusFound, "http://localhost:3000/auth/callback?token="+token)
+	completeExternalLogin(c, "google")
 }
 
 // Google OAuth - Client-side flow handlers
-type GoogleUserRequest struct {
-	GoogleUser struct {
-		ID            string `json:"id"`
-		Email         string `json:"email"`
-		VerifiedEmail bool   `json:"verified_email"`
-		Name          string `json:"name"`
-		Picture       string `json:"picture"`
-	} `json:"google_user"`
+
+// GoogleTokenRequest carries the ID token from Google Identity Services.
+// Identity is taken only from the verified token, never from client-supplied profile fields.
+type GoogleTokenRequest struct {
+	IDToken string `json:"id_token" binding:"required"`
 }
 
 func GoogleLoginClient(c *gin.Context) {
-	var req GoogleUserRequest
+	var req GoogleTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	var user models.User
-	result := database.DB.Where("provider = ? AND provider_id = ?", "google", req.GoogleUser.ID).First(&user)
+	googleUser, err := services.VerifyGoogleIDToken(req.IDToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
 
-	if result.Error == gorm.ErrRecordNotFound {
+	linked, err := services.FindUserByIdentity("google", googleUser.Subject)
+	if errors.Is(err, services.ErrIdentityNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found, please register first"})
 		return
-	} else if result.Error != nil {
+	} else if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
 		return
 	}
+	user := *linked
+
+	if user.TwoFactorEnabled {
+		challenge, err := services.CreateLoginChallenge(user)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create token"})
+			return
+		}
+		respondTwoFactorRequired(c, challenge)
+		return
+	}
 
-	token, err := middleware.CreateToken(user)
+	tokens, err := services.StartSession(user, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create token"})
+		respondSessionError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  gin.H{"id": user.ID},
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          gin.H{"id": user.ID},
 	})
 }
 
 func GoogleRegisterClient(c *gin.Context) {
-	var req GoogleUserRequest
+	var req GoogleTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
 		return
 	}
 
-	// Check if user already exists with this Google ID
-	var existingUser models.User
-	result := database.DB.Where("provider = ? AND provider_id = ?", "google", req.GoogleUser.ID).First(&existingUser)
-
-	if result.Error == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "user already exists, please login"})
+	googleUser, err := services.VerifyGoogleIDToken(req.IDToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Check if email is already used
-	result = database.DB.Where("email = ?", req.GoogleUser.Email).First(&existingUser)
-	if result.Error == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "email already registered with another account"})
+	// Check if user already exists with this Google ID
+	if _, err := services.FindUserByIdentity("google", googleUser.Subject); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "user already exists, please login"})
 		return
 	}
 
-	// Create new user
-	username := generateUsername(req.GoogleUser.Email)
-	password := services.GenerateRandomPassword(16)
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-
-	user := models.User{
-		Username:   username,
-		Email:      req.GoogleUser.Email,
-		Password:   string(hashedPassword),
-		ImageURL:   req.GoogleUser.Picture,
-		Provider:   "google",
-		ProviderID: req.GoogleUser.ID,
-	}
-
-	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+	user, err := services.CreateExternalUser(&services.ExternalIdentity{
+		Provider:      "google",
+		Subject:       googleUser.Subject,
+		Email:         googleUser.Email,
+		EmailVerified: googleUser.EmailVerified,
+		Picture:       googleUser.Picture,
+	})
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
 
-	token, err := middleware.CreateToken(user)
+	tokens, err := services.StartSession(*user, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create token"})
+		respondSessionError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  gin.H{"id": user.ID},
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user":          gin.H{"id": user.ID},
 	})
 }
 
@@ -337,11 +312,23 @@ func GetMyProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"user": gin.H{
-			"id":        user.ID,
-			"username":  user.Username,
-			"email":     user.Email,
-			"image_url": user.ImageURL,
-			"posts":     user.Posts,
+			"id":                 user.ID,
+			"username":           user.Username,
+			"email":              user.Email,
+			"image_url":          user.ImageURL,
+			"display_name":       user.DisplayName,
+			"bio":                user.Bio,
+			"location":           user.Location,
+			"website":            user.Website,
+			"cover_image_url":    user.CoverImageURL,
+			"posts":              user.Posts,
+			"email_verified":     user.EmailVerified,
+			"pending_email":      user.PendingEmail,
+			"two_factor_enabled": user.TwoFactorEnabled,
+			"has_password":       user.HasPassword,
+			"is_private":         user.IsPrivate,
+			"role":               user.Role,
+			"permissions":        user.Permissions(),
 		},
 	})
 }
@@ -451,9 +438,17 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	message := "profile updated successfully"
+	verificationRequired := user.PendingEmail != ""
+	if verificationRequired {
+		recordAudit(c, models.AuditEmailChangeRequested, uint(userID), map[string]interface{}{"new_email": user.PendingEmail})
+		message = "profile updated, confirm the code sent to your new email to finish changing it"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "profile updated successfully",
-		"user":    user,
+		"message":                     message,
+		"user":                        user,
+		"email_verification_required": verificationRequired,
 	})
 }
 
@@ -503,10 +498,12 @@ func UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	if err := services.UpdateUserPassword(uint(userID), req.OldPassword, req.NewPassword); err != nil {
+	if err := services.UpdateUserPassword(uint(userID), getSessionIDFromContext(c), req.OldPassword, req.NewPassword); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	recordAudit(c, models.AuditPasswordChanged, uint(userID), nil)
+
 	c.JSON(http.StatusOK, gin.H{"message": "password updated successfully"})
 }
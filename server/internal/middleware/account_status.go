@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+// AccountRestrictedError is returned for suspended and banned accounts
+type AccountRestrictedError struct {
+	Banned bool
+	Until  time.Time // end of a suspension
+	Reason string
+}
+
+func (e *AccountRestrictedError) Error() string {
+	msg := "your account has been banned"
+	if !e.Banned {
+		msg = fmt.Sprintf("your account is suspended until %s", e.Until.UTC().Format(time.RFC1123))
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// CheckAccountStatus returns an *AccountRestrictedError when the user is
+// banned or currently suspended
+func CheckAccountStatus(user *models.User) error {
+	switch {
+	case user.IsBanned():
+		return &AccountRestrictedError{Banned: true, Reason: user.RestrictionReason}
+	case user.IsSuspended():
+		return &AccountRestrictedError{Until: *user.SuspendedUntil, Reason: user.RestrictionReason}
+	}
+	return nil
+}
+
+// checkAccountActive loads the account behind a token and rejects it while restricted
+func checkAccountActive(userID string) error {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return errors.New("invalid user ID in token")
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, suspended_until, banned_at, restriction_reason").First(&user, id).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	return CheckAccountStatus(&user)
+}
@@ -1,13 +1,11 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
-	"time"
 
-	"github.com/Bauka07/SocialApp/internal/config"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // AuthCheck - Middleware to protect routes (REQUIRED auth)
@@ -31,19 +29,32 @@ func AuthCheck() gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return config.GetJWT(), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
+		// Personal access tokens are only accepted on routes with a scope
+		if IsAccessToken(tokenString) {
+			if err := authenticateAccessToken(c, tokenString); err != nil {
+				var restricted *AccountRestrictedError
+				status := http.StatusUnauthorized
+				if errors.Is(err, errScopeNotAllowed) || errors.Is(err, errMissingScope) || errors.As(err, &restricted) {
+					status = http.StatusForbidden
+				}
+				c.JSON(status, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			c.Next()
 			return
 		}
 
-		if claims.ExpiresAt.Time.Before(time.Now()) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired"})
+		claims, err := ParseToken(tokenString)
+		if err != nil {
+			// Tell suspended users why, so the frontend can show it
+			var restricted *AccountRestrictedError
+			if errors.As(err, &restricted) {
+				c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "account_restricted": true})
+				c.Abort()
+				return
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
@@ -51,6 +62,7 @@ func AuthCheck() gin.HandlerFunc {
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
@@ -73,17 +85,15 @@ func OptionalAuth() gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return config.GetJWT(), nil
-		})
-
-		if err != nil || !token.Valid {
+		if IsAccessToken(tokenString) {
+			// Continue anonymously when the token can't be used here, like an invalid JWT
+			authenticateAccessToken(c, tokenString)
 			c.Next()
 			return
 		}
 
-		if claims.ExpiresAt.Time.Before(time.Now()) {
+		claims, err := ParseToken(tokenString)
+		if err != nil {
 			c.Next()
 			return
 		}
@@ -91,6 +101,7 @@ func OptionalAuth() gin.HandlerFunc {
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
 
 		c.Next()
 	}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole - Only lets users with one of roles through. Use after AuthCheck.
+// The role is read from the database so a change applies immediately.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := loadRole(c)
+		if !ok {
+			return
+		}
+
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+		c.Abort()
+	}
+}
+
+// RequirePermission - Only lets users whose role grants permission through. Use after AuthCheck.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := loadRole(c)
+		if !ok {
+			return
+		}
+
+		if !models.RoleHasPermission(role, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to do this"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// loadRole looks up the authenticated user's current role and stores it in
+// the context, aborting the request when there is none
+func loadRole(c *gin.Context) (string, bool) {
+	id, err := strconv.ParseUint(c.GetString("userID"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return "", false
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, role").First(&user, id).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		c.Abort()
+		return "", false
+	}
+
+	c.Set("role", user.Role)
+	return user.Role, true
+}
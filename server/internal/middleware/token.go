@@ -1,39 +1,184 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
 	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	UserID   string `json:"userID"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	UserID    string `json:"userID"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	SessionID uint   `json:"sid"`
+	// Purpose is empty for access tokens and set for single-step tokens
+	// (e.g. the 2FA login challenge) that must never grant API access
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func CreateToken(user models.User) (string, error) {
-	expirationTime := time.Now().Add(time.Hour * 24)
+// PurposeTwoFactor marks the token handed out after a correct password when 2FA is enabled
+const PurposeTwoFactor = "2fa_challenge"
+
+// ChallengeTokenTTL is how long a user has to enter their 2FA code
+const ChallengeTokenTTL = 5 * time.Minute
+
+// CreateToken issues a short-lived access token bound to a session
+func CreateToken(user models.User, sessionID uint) (string, error) {
+	expirationTime := time.Now().Add(config.AccessTokenTTL)
 
 	claims := &Claims{
-		UserID:   fmt.Sprintf("%d", user.ID),
-		Username: user.Username,
-		Email:    user.Email,
+		UserID:    fmt.Sprintf("%d", user.ID),
+		Username:  user.Username,
+		Email:     user.Email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(config.GetJWT())
+	key := config.Keys().SigningKey()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.Private)
+	if err != nil {
+		return "", fmt.Errorf("cannot create token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// CreatePurposeToken issues a short-lived token that only proves one step
+// of a multi-step flow, such as a correct password before the 2FA code
+func CreatePurposeToken(user models.User, purpose string, ttl time.Duration) (string, error) {
+	claims := &Claims{
+		UserID:  fmt.Sprintf("%d", user.ID),
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	key := config.Keys().SigningKey()
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.Private)
 	if err != nil {
 		return "", fmt.Errorf("cannot create token: %w", err)
 	}
 
 	return tokenString, nil
 }
+
+// ParsePurposeToken validates a token created by CreatePurposeToken
+func ParsePurposeToken(tokenString, purpose string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, lookupVerificationKey)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	if claims.Purpose != purpose {
+		return nil, errors.New("invalid token purpose")
+	}
+
+	return claims, nil
+}
+
+// ParseToken validates a signed token string and returns its claims
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, lookupVerificationKey)
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Time.Before(time.Now()) {
+		return nil, errors.New("token expired")
+	}
+
+	if claims.Purpose != "" {
+		return nil, errors.New("token cannot be used for API access")
+	}
+
+	if err := checkSession(claims); err != nil {
+		return nil, err
+	}
+
+	if err := checkAccountActive(claims.UserID); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// lookupVerificationKey picks the keyring entry named by the kid header and
+// makes sure the token's alg matches it (no alg confusion between keys)
+func lookupVerificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := config.Keys().VerificationKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+// checkSession rejects tokens whose session was revoked (logout, password change)
+func checkSession(claims *Claims) error {
+	if claims.SessionID == 0 {
+		return errors.New("token has no session")
+	}
+
+	var session models.Session
+	if err := database.DB.Select("id, user_id, expires_at, revoked_at").
+		First(&session, claims.SessionID).Error; err != nil {
+		return errors.New("session not found")
+	}
+
+	if fmt.Sprintf("%d", session.UserID) != claims.UserID || !session.IsActive() {
+		return errors.New("session has been revoked")
+	}
+
+	return nil
+}
+
+// CheckCredential re-validates a credential that authenticated an earlier
+// request (e.g. the one that issued an event ticket): the session, or the
+// personal access token when accessTokenID is set, must still be active and
+// the account unrestricted
+func CheckCredential(userID, sessionID, accessTokenID uint) error {
+	if accessTokenID != 0 {
+		var pat models.PersonalAccessToken
+		if err := database.DB.Select("id, user_id, expires_at").First(&pat, accessTokenID).Error; err != nil ||
+			pat.UserID != userID || !pat.IsActive() {
+			return errors.New("access token has been revoked")
+		}
+	} else if err := checkSession(&Claims{UserID: fmt.Sprintf("%d", userID), SessionID: sessionID}); err != nil {
+		return err
+	}
+
+	return checkAccountActive(fmt.Sprintf("%d", userID))
+}
+
+// UserIDUint converts the string userID claim to a numeric ID
+func (c *Claims) UserIDUint() (uint, error) {
+	id, err := strconv.ParseUint(c.UserID, 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid user ID in token")
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// useTestKeyring loads a keyring with an RS256 key (kid "rsa") and the legacy
+// HS256 secret, and returns the RSA key and its public PEM
+func useTestKeyring(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "rsa.pem")
+	private := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+	if err := os.WriteFile(path, private, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("JWT_KEYS", "rsa:RS256:"+path)
+	t.Setenv("JWT_SIGNING_KID", "")
+	t.Setenv("JWT_SECRET", "s3cret")
+	config.InitConfig()
+
+	return rsaKey, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+func TestLookupVerificationKey(t *testing.T) {
+	rsaKey, rsaPublicPEM := useTestKeyring(t)
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, &Claims{
+			UserID:           "1",
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+		})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "RS256 with its kid", token: sign(jwt.SigningMethodRS256, "rsa", rsaKey)},
+		{name: "legacy HS256 without kid", token: sign(jwt.SigningMethodHS256, "", []byte("s3cret"))},
+		{name: "legacy HS256 with its kid", token: sign(jwt.SigningMethodHS256, config.LegacyKeyID, []byte("s3cret"))},
+		// The classic alg confusion: HMAC keyed with the RSA public key
+		{name: "HS256 signed with the RSA public key", token: sign(jwt.SigningMethodHS256, "rsa", rsaPublicPEM), wantErr: true},
+		{name: "RS256 under the legacy kid", token: sign(jwt.SigningMethodRS256, config.LegacyKeyID, rsaKey), wantErr: true},
+		{name: "unknown kid", token: sign(jwt.SigningMethodRS256, "retired", rsaKey), wantErr: true},
+		{name: "wrong secret", token: sign(jwt.SigningMethodHS256, "", []byte("guess")), wantErr: true},
+		{name: "alg none", token: sign(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.ParseWithClaims(tt.token, &Claims{}, lookupVerificationKey)
+			valid := err == nil && token.Valid
+			if valid == tt.wantErr {
+				t.Fatalf("token accepted = %v, want %v (error %v)", valid, !tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Scopes a personal access token can be granted
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopePostsRead    = "posts:read"
+	ScopePostsWrite   = "posts:write"
+	ScopeMessagesRead = "messages:read"
+	ScopeMessagesSend = "messages:send"
+)
+
+// TokenScopes describes every scope, for validation and for the token settings page
+var TokenScopes = map[string]string{
+	ScopeProfileRead:  "Read your profile",
+	ScopePostsRead:    "Read posts, comments and likes",
+	ScopePostsWrite:   "Create, edit, delete and like posts and comments",
+	ScopeMessagesRead: "Read chats and messages",
+	ScopeMessagesSend: "Send, edit and delete messages",
+}
+
+// PersonalAccessTokenPrefix starts every personal access token, which tells
+// them apart from login JWTs and makes leaked ones easy to search for
+const PersonalAccessTokenPrefix = "sap_"
+
+// PersonalAccessToken lets scripts and bots call the API as the user without
+// their password. Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint `gorm:"not null;index" json:"-"`
+
+	Name      string `gorm:"size:100;not null" json:"name"`
+	TokenHash string `gorm:"size:64;not null;uniqueIndex" json:"-"` // SHA-256 hex
+	// Hint is the start of the token, so users can tell their tokens apart
+	Hint   string   `gorm:"size:16" json:"hint"`
+	Scopes []string `gorm:"serializer:json" json:"scopes"`
+
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// IsActive checks that the token hasn't expired
+func (t *PersonalAccessToken) IsActive() bool {
+	return time.Now().Before(t.ExpiresAt)
+}
+
+// HasScope reports whether the token was granted scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
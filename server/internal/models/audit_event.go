@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audited actions. Admin and moderation actions are prefixed so they can be
+// told apart from a user's own security events.
+const (
+	AuditLogin           = "auth.login"
+	AuditLoginFailed     = "auth.login_failed"
+	AuditLogout          = "auth.logout"
+	AuditRefreshReused   = "auth.refresh_token_reused"
+	AuditSessionRevoked  = "session.revoked"
+	AuditSessionsRevoked = "session.revoked_all"
+
+	AuditPasswordChanged        = "password.changed"
+	AuditPasswordSet            = "password.set"
+	AuditPasswordResetRequested = "password.reset_requested"
+	AuditPasswordReset          = "password.reset"
+
+	AuditEmailVerified        = "email.verified"
+	AuditEmailChangeRequested = "email.change_requested"
+	AuditEmailChanged         = "email.changed"
+	AuditEmailChangeCancelled = "email.change_cancelled"
+
+	AuditIdentityLinked   = "identity.linked"
+	AuditIdentityUnlinked = "identity.unlinked"
+
+	AuditTwoFactorEnabled       = "2fa.enabled"
+	AuditTwoFactorDisabled      = "2fa.disabled"
+	AuditRecoveryCodesGenerated = "2fa.recovery_codes_regenerated"
+	AuditPasskeyAdded           = "passkey.added"
+	AuditPasskeyRemoved         = "passkey.removed"
+
+	AuditTokenCreated = "token.created"
+	AuditTokenRevoked = "token.revoked"
+
+	AuditAdminRoleChanged   = "admin.role_changed"
+	AuditAdminSuspended     = "admin.user_suspended"
+	AuditAdminBanned        = "admin.user_banned"
+	AuditAdminReinstated    = "admin.user_reinstated"
+	AuditAdminForcedLogout  = "admin.forced_logout"
+	AuditAdminPasswordReset = "admin.forced_password_reset"
+
+	AuditShadowBanned      = "moderation.user_shadow_banned"
+	AuditShadowBanLifted   = "moderation.user_shadow_ban_lifted"
+	AuditReportAssigned    = "moderation.report_assigned"
+	AuditReportResolved    = "moderation.report_resolved"
+	AuditFilterRuleCreated = "moderation.filter_rule_created"
+	AuditFilterRuleUpdated = "moderation.filter_rule_updated"
+	AuditFilterRuleDeleted = "moderation.filter_rule_deleted"
+)
+
+// ErrAuditAppendOnly is returned when something tries to change or remove an audit event
+var ErrAuditAppendOnly = errors.New("audit log is append-only")
+
+// AuditEvent is one entry of the append-only audit trail. ActorID is who did
+// it (nil for anonymous requests like a failed login); TargetUserID is the
+// account it happened to.
+type AuditEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	Action       string `gorm:"size:50;not null;index" json:"action"`
+	ActorID      *uint  `gorm:"index" json:"actor_id,omitempty"`
+	TargetUserID *uint  `gorm:"index" json:"target_user_id,omitempty"`
+
+	IPAddress string `gorm:"size:45;index" json:"ip_address"`
+	UserAgent string `gorm:"size:255" json:"user_agent"`
+
+	Details map[string]interface{} `gorm:"serializer:json" json:"details,omitempty"`
+}
+
+// IsStaffAction reports whether the event is an admin or moderation action
+// rather than something the account owner did
+func (e *AuditEvent) IsStaffAction() bool {
+	return strings.HasPrefix(e.Action, "admin.") || strings.HasPrefix(e.Action, "moderation.")
+}
+
+// BeforeUpdate keeps audit events from being rewritten through gorm. Hooks
+// don't run for raw SQL, so the database also rejects the change (see
+// services.EnforceAuditAppendOnly).
+func (e *AuditEvent) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditAppendOnly
+}
+
+// BeforeDelete keeps audit events from being removed through gorm; the
+// database triggers cover everything else
+func (e *AuditEvent) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditAppendOnly
+}
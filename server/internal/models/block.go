@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserBlock cuts contact between two accounts in both directions: neither
+// sees the other's posts and comments, finds the other in search, or can
+// message them
+type UserBlock struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	BlockerID uint `gorm:"not null;uniqueIndex:idx_user_block" json:"blocker_id"`
+	BlockedID uint `gorm:"not null;uniqueIndex:idx_user_block;index" json:"blocked_id"`
+}
+
+// UserMute takes an account's posts out of the muter's feed. Unlike a block,
+// the muted user isn't affected and can't tell.
+type UserMute struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	MuterID uint `gorm:"not null;uniqueIndex:idx_user_mute" json:"muter_id"`
+	MutedID uint `gorm:"not null;uniqueIndex:idx_user_mute" json:"muted_id"`
+}
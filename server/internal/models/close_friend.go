@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// CloseFriend puts FriendID on UserID's close friends list, the audience of
+// their close_friends posts. The friend isn't told.
+type CloseFriend struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID   uint `gorm:"not null;uniqueIndex:idx_close_friend" json:"user_id"`
+	FriendID uint `gorm:"not null;uniqueIndex:idx_close_friend;index" json:"friend_id"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// Purposes for EmailVerification
+const (
+	VerificationSignup      = "signup"
+	VerificationEmailChange = "email_change"
+)
+
+// EmailVerification stores OTP codes that confirm ownership of an email
+// address, either for a new account or for a pending email change
+type EmailVerification struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Email     string    `gorm:"not null;index" json:"email"`
+	Code      string    `gorm:"not null" json:"-"`
+	Purpose   string    `gorm:"not null;size:20" json:"purpose"`
+	Attempts  int       `gorm:"default:0" json:"attempts"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	Used      bool      `gorm:"default:false" json:"used"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsExpired checks if the verification code has expired
+func (ev *EmailVerification) IsExpired() bool {
+	return time.Now().After(ev.ExpiresAt)
+}
+
+// IsValid checks if code is valid (not expired and not used)
+func (ev *EmailVerification) IsValid() bool {
+	return !ev.IsExpired() && !ev.Used
+}
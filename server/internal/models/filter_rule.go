@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// What a filter rule's pattern is matched as
+const (
+	FilterKindWord   = "word"   // whole word or phrase, case-insensitive
+	FilterKindRegex  = "regex"  // RE2 regular expression
+	FilterKindDomain = "domain" // links to the domain or any of its subdomains
+)
+
+// What happens to content that matches a rule
+const (
+	FilterActionReject = "reject" // refuse to save it
+	FilterActionHold   = "hold"   // save it hidden and queue it for a moderator
+	FilterActionMask   = "mask"   // replace the matched text with asterisks
+)
+
+// FilterRule is an admin-managed rule checked against new and edited posts,
+// comments and messages
+type FilterRule struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Kind    string `gorm:"size:20;not null" json:"kind"`
+	Pattern string `gorm:"size:255;not null" json:"pattern"`
+	Action  string `gorm:"size:20;not null" json:"action"`
+	Note    string `gorm:"size:255" json:"note,omitempty"`
+	Enabled bool   `gorm:"not null" json:"enabled"`
+
+	CreatedByID   uint       `gorm:"not null" json:"created_by_id"`
+	MatchCount    int64      `gorm:"not null;default:0" json:"match_count"`
+	LastMatchedAt *time.Time `json:"last_matched_at,omitempty"`
+}
+
+// IsValidFilterKind reports whether kind is one of the FilterKind values
+func IsValidFilterKind(kind string) bool {
+	return kind == FilterKindWord || kind == FilterKindRegex || kind == FilterKindDomain
+}
+
+// IsValidFilterAction reports whether action is one of the FilterAction values
+func IsValidFilterAction(action string) bool {
+	return action == FilterActionReject || action == FilterActionHold || action == FilterActionMask
+}
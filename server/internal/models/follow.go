@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Follow states. Following a public account is accepted right away; a
+// private account approves each follower first.
+const (
+	FollowPending  = "pending"
+	FollowAccepted = "accepted"
+)
+
+// Follow is one account following another, or asking to
+type Follow struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	FollowerID  uint   `gorm:"not null;uniqueIndex:idx_follow" json:"follower_id"`
+	FollowingID uint   `gorm:"not null;uniqueIndex:idx_follow;index:idx_follow_target" json:"following_id"`
+	Status      string `gorm:"size:20;not null;index:idx_follow_target" json:"status"`
+}
+
+// IsAccepted reports whether the follower can see the account's content
+func (f *Follow) IsAccepted() bool {
+	return f.Status == FollowAccepted
+}
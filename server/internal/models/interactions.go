@@ -28,6 +28,12 @@ type Comment struct {
 	User      User      `gorm:"foreignKey:UserID" json:"user"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Set when a moderator hides the comment after a report
+	HiddenAt *time.Time `gorm:"index" json:"-"`
+	// Set while a content filter holds the comment for review. Held comments
+	// stay visible to their author.
+	HeldAt *time.Time `gorm:"index" json:"-"`
 }
 
 type PostWithStats struct {
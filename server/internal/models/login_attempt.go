@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Login attempt outcomes stored in LoginAttempt.Reason
+const (
+	LoginSucceeded          = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginInvalidSecondStep  = "invalid_second_factor"
+	LoginSecondStepRequired = "two_factor_required"
+	LoginEmailUnverified    = "email_unverified"
+	LoginBlocked            = "locked"
+	LoginInvalidPasskey     = "invalid_passkey"
+	LoginAccountRestricted  = "account_restricted"
+)
+
+// LoginAttempt is one entry of the login history. UserID is nil when the
+// email didn't match an account.
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	UserID *uint  `gorm:"index" json:"-"`
+	Email  string `gorm:"size:255;index" json:"-"`
+
+	IPAddress string `gorm:"size:45;index" json:"ip_address"`
+	Device    string `gorm:"size:100" json:"device"`
+	UserAgent string `gorm:"size:255" json:"user_agent"`
+
+	Success bool   `gorm:"not null;default:false" json:"success"`
+	Reason  string `gorm:"size:32;not null" json:"reason"`
+}
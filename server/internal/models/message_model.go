@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
@@ -21,6 +22,10 @@ type Message struct {
 	DeletedForSender   bool `json:"deleted_for_sender,omitempty" gorm:"default:false"`
 	DeletedForReceiver bool `json:"deleted_for_receiver,omitempty" gorm:"default:false"`
 
+	// Set while a content filter holds the message for review. Only the
+	// sender sees a held message; it's delivered once a moderator approves it.
+	HiddenAt *time.Time `json:"hidden_at,omitempty"`
+
 	// Reply functionality - ADD THESE
 	ReplyToID *uint    `json:"reply_to_id,omitempty" gorm:"index"`
 	ReplyTo   *Message `json:"reply_to,omitempty" gorm:"foreignKey:ReplyToID"`
@@ -29,3 +34,34 @@ type Message struct {
 	Sender   User `json:"sender,omitempty" gorm:"foreignKey:SenderID"`
 	Receiver User `json:"receiver,omitempty" gorm:"foreignKey:ReceiverID"`
 }
+
+// ChatParticipant is the part of a message's sender or receiver that is sent
+// with the message
+type ChatParticipant struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Avatar   string `json:"avatar,omitempty"`
+}
+
+// chatParticipantOf returns nil when the user wasn't loaded
+func chatParticipantOf(user User) *ChatParticipant {
+	if user.ID == 0 {
+		return nil
+	}
+	return &ChatParticipant{ID: user.ID, Username: user.Username, Avatar: user.ImageURL}
+}
+
+// MarshalJSON keeps the participants' private fields (email, password hash,
+// pending email...) out of message payloads
+func (m Message) MarshalJSON() ([]byte, error) {
+	type Alias Message
+	return json.Marshal(&struct {
+		*Alias
+		Sender   *ChatParticipant `json:"sender,omitempty"`
+		Receiver *ChatParticipant `json:"receiver,omitempty"`
+	}{
+		Alias:    (*Alias)(&m),
+		Sender:   chatParticipantOf(m.Sender),
+		Receiver: chatParticipantOf(m.Receiver),
+	})
+}
@@ -11,6 +11,7 @@ type PasswordReset struct {
 	Code      string    `gorm:"not null" json:"code"`
 	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
 	Used      bool      `gorm:"default:false" json:"used"`
+	Attempts  int       `gorm:"not null;default:0" json:"-"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -8,6 +8,23 @@ import (
 	"gorm.io/gorm"
 )
 
+// Who a post is shown to besides its author
+const (
+	PostPublic       = "public"
+	PostFollowers    = "followers"     // approved followers
+	PostCloseFriends = "close_friends" // the author's close friends list
+	PostOnlyMe       = "only_me"
+)
+
+// IsValidPostVisibility reports whether visibility is one of the Post* values
+func IsValidPostVisibility(visibility string) bool {
+	switch visibility {
+	case PostPublic, PostFollowers, PostCloseFriends, PostOnlyMe:
+		return true
+	}
+	return false
+}
+
 type Post struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -19,16 +36,46 @@ type Post struct {
 	ImageURL sql.NullString `json:"-" gorm:"size:255"`
 	UserID   uint           `json:"user_id" gorm:"not null;index"`
 	User     User           `json:"user" gorm:"foreignKey:UserID"`
+
+	Visibility string `json:"visibility" gorm:"size:20;not null;default:'public';index"`
+
+	// Set when a moderator hides the post after a report. Hidden posts stay
+	// visible to their author but are left out of feeds and lookups.
+	HiddenAt *time.Time `json:"-" gorm:"index"`
+	// Set while a content filter holds the post for review. Kept apart from
+	// HiddenAt so approving a hold can't republish a post a moderator hid.
+	HeldAt *time.Time `json:"-" gorm:"index"`
+}
+
+// PostAuthor is the part of a post's author that is shown with the post
+type PostAuthor struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	ImageURL    string `json:"image_url"`
+}
+
+// PostAuthorOf returns the public fields of user
+func PostAuthorOf(user User) PostAuthor {
+	return PostAuthor{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		ImageURL:    user.ImageURL,
+	}
 }
 
-// Custom JSON marshaling to handle sql.NullString properly
+// Custom JSON marshaling to handle sql.NullString properly and to keep the
+// author's private fields (email, password hash, role...) out of responses
 func (p Post) MarshalJSON() ([]byte, error) {
 	type Alias Post
 	return json.Marshal(&struct {
 		*Alias
-		ImageURL string `json:"image_url,omitempty"`
+		ImageURL string     `json:"image_url,omitempty"`
+		User     PostAuthor `json:"user"`
 	}{
 		Alias:    (*Alias)(&p),
 		ImageURL: p.ImageURL.String, // Extract string value
+		User:     PostAuthorOf(p.User),
 	})
 }
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Kinds of content a report can point at
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetMessage = "message"
+	ReportTargetUser    = "user"
+)
+
+// ReportReasons lists the categories a reporter can pick from
+var ReportReasons = map[string]string{
+	"spam":           "Spam or scam",
+	"harassment":     "Harassment or bullying",
+	"hate_speech":    "Hate speech or symbols",
+	"violence":       "Violence or threats",
+	"nudity":         "Nudity or sexual content",
+	"self_harm":      "Self-harm or suicide",
+	"misinformation": "False information",
+	"impersonation":  "Impersonation",
+	"other":          "Something else",
+}
+
+// ReportReasonFilter marks reports filed by a content filter rule that held
+// the content for review. Those reports have no reporter (ReporterID 0).
+const ReportReasonFilter = "content_filter"
+
+// Report workflow states
+const (
+	ReportOpen      = "open"
+	ReportActioned  = "actioned"
+	ReportDismissed = "dismissed"
+)
+
+// Actions a moderator can take when resolving a report
+const (
+	ReportActionNone          = "none" // close as actioned without touching content
+	ReportActionHidePost      = "hide_post"
+	ReportActionDeleteComment = "delete_comment"
+	ReportActionDeleteMessage = "delete_message"
+	ReportActionWarnUser      = "warn_user"
+	ReportActionSuspendUser   = "suspend_user"
+	ReportActionShadowBanUser = "shadow_ban_user" // hide the author's activity from others
+	ReportActionApprove       = "approve"         // publish content held by a filter
+)
+
+// Report is a user's complaint about a post, comment, message or account.
+// Open reports make up the moderation queue.
+type Report struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	ReporterID uint `gorm:"not null;uniqueIndex:idx_report_once" json:"reporter_id"`
+
+	TargetType string `gorm:"size:20;not null;uniqueIndex:idx_report_once;index:idx_report_target" json:"target_type"`
+	TargetID   uint   `gorm:"not null;uniqueIndex:idx_report_once;index:idx_report_target" json:"target_id"`
+	// TargetUserID is the author of the reported content, or the reported account
+	TargetUserID uint `gorm:"not null;index" json:"target_user_id"`
+
+	Reason  string `gorm:"size:32;not null" json:"reason"`
+	Details string `gorm:"size:1000" json:"details,omitempty"`
+
+	Status     string `gorm:"size:20;not null;default:'open';index" json:"status"`
+	AssigneeID *uint  `gorm:"index" json:"assignee_id,omitempty"`
+
+	Resolution     string     `gorm:"size:32" json:"resolution,omitempty"`
+	ResolutionNote string     `gorm:"size:1000" json:"resolution_note,omitempty"`
+	ResolvedByID   *uint      `json:"resolved_by_id,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+}
+
+// IsOpen reports whether the report still waits for a moderator
+func (r *Report) IsOpen() bool {
+	return r.Status == ReportOpen
+}
@@ -0,0 +1,45 @@
+package models
+
+// Roles a user can have. Every account starts as RoleUser.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permissions granted by roles
+const (
+	// Remove other users' posts, comments and messages
+	PermModerateContent = "content:moderate"
+	// Suspend, ban and otherwise manage other accounts
+	PermManageUsers = "users:manage"
+	// Change other users' roles
+	PermManageRoles = "roles:manage"
+	// Edit the keyword, regex and domain filters applied to new content
+	PermManageFilters = "filters:manage"
+	// Read the audit log of security and moderation events
+	PermViewAuditLog = "audit:read"
+)
+
+// RolePermissions lists what each role is allowed to do
+var RolePermissions = map[string][]string{
+	RoleUser:      {},
+	RoleModerator: {PermModerateContent},
+	RoleAdmin:     {PermModerateContent, PermManageUsers, PermManageRoles, PermManageFilters, PermViewAuditLog},
+}
+
+// IsValidRole reports whether role is one of the known roles
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission reports whether role grants permission
+func RoleHasPermission(role, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Session is a refresh-token backed login on one device.
+// Access tokens carry the session ID so revoking a session invalidates them too.
+type Session struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint `gorm:"not null;index" json:"user_id"`
+
+	// SHA-256 of the current refresh token, and of the one it replaced
+	// (used to detect reuse of a rotated token)
+	RefreshTokenHash  string `gorm:"not null;uniqueIndex;size:64" json:"-"`
+	PreviousTokenHash string `gorm:"index;size:64" json:"-"`
+
+	Device    string `gorm:"size:100" json:"device"`
+	UserAgent string `gorm:"size:255" json:"user_agent"`
+	IPAddress string `gorm:"size:45" json:"ip_address"`
+
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+}
+
+// IsActive checks that the session is neither revoked nor expired
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UserIdentity links an external login (Google, Keycloak, GitHub...) to an
+// account. One account can have several; each provider account maps to one user.
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint `gorm:"not null;index" json:"-"`
+
+	Provider string `gorm:"size:20;not null;uniqueIndex:idx_identity_provider_subject" json:"provider"`
+	Subject  string `gorm:"size:255;not null;uniqueIndex:idx_identity_provider_subject" json:"-"`
+	Email    string `gorm:"size:255" json:"email"`
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
@@ -17,8 +17,75 @@ type User struct {
 	Password string `json:"password,omitempty" gorm:"not null"`
 	ImageURL string `json:"image_url,omitempty" gorm:"size:255"`
 
+	// Public profile details, all optional
+	DisplayName   string `json:"display_name,omitempty" gorm:"size:50"`
+	Bio           string `json:"bio,omitempty" gorm:"size:300"`
+	Location      string `json:"location,omitempty" gorm:"size:100"`
+	Website       string `json:"website,omitempty" gorm:"size:255"`
+	CoverImageURL string `json:"cover_image_url,omitempty" gorm:"size:255"`
+
+	// Accounts created before verification existed default to verified;
+	// new local registrations are explicitly set to false until confirmed.
+	EmailVerified bool `json:"email_verified" gorm:"not null;default:true"`
+	// New address waiting for confirmation; Email keeps the verified one until then
+	PendingEmail string `json:"pending_email,omitempty" gorm:"size:255"`
+
+	// Whether the account can log in with a password. Accounts created through
+	// an external provider start without one (see Identities).
+	HasPassword bool `json:"has_password" gorm:"not null;default:true"`
+
+	// Only approved followers see a private account's posts and connections;
+	// everyone else gets the basic profile
+	IsPrivate bool `json:"is_private" gorm:"not null;default:false"`
+
+	// Role decides what the user may do beyond their own content (see RolePermissions)
+	Role string `json:"role" gorm:"size:20;not null;default:'user'"`
+
+	// Set by an admin. A suspended or banned account can't log in or use
+	// existing tokens; the reason is shown to the user when they try.
+	SuspendedUntil    *time.Time `json:"-"`
+	BannedAt          *time.Time `json:"-"`
+	RestrictionReason string     `json:"-" gorm:"size:500"`
+
+	// Set by a moderator. A shadow-banned account works as usual for its
+	// owner, but its posts, comments and messages are hidden from everyone else.
+	ShadowBannedAt *time.Time `json:"-" gorm:"index"`
+
+	// How the account was created; logins are looked up through Identities
 	Provider   string `json:"provider,omitempty" gorm:"size:20;default:'local'"`
 	ProviderID string `json:"provider_id,omitempty" gorm:"size:100"`
 
-	Posts []Post `json:"posts,omitempty" gorm:"foreignKey:UserID"`
+	// TOTP two-factor authentication. The secret is stored while enrollment
+	// is pending and TwoFactorEnabled flips once the first code is confirmed.
+	TwoFactorEnabled  bool   `json:"two_factor_enabled" gorm:"default:false"`
+	TwoFactorSecret   string `json:"-" gorm:"size:64"`
+	TwoFactorLastStep int64  `json:"-" gorm:"default:0"` // last accepted time step, prevents code replay
+
+	Posts      []Post         `json:"posts,omitempty" gorm:"foreignKey:UserID"`
+	Identities []UserIdentity `json:"identities,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// HasPermission reports whether the user's role grants permission
+func (u *User) HasPermission(permission string) bool {
+	return RoleHasPermission(u.Role, permission)
+}
+
+// Permissions lists what the user's role allows
+func (u *User) Permissions() []string {
+	return RolePermissions[u.Role]
+}
+
+// IsBanned reports whether the account is permanently banned
+func (u *User) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+// IsShadowBanned reports whether the account's activity is hidden from others
+func (u *User) IsShadowBanned() bool {
+	return u.ShadowBannedAt != nil
+}
+
+// IsSuspended reports whether the account is suspended right now
+func (u *User) IsSuspended() bool {
+	return u.SuspendedUntil != nil && time.Now().Before(*u.SuspendedUntil)
 }
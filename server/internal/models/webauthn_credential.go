@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a passkey (security key, phone, platform authenticator)
+// registered to an account. One account can have several.
+type WebAuthnCredential struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID uint `gorm:"not null;index" json:"-"`
+
+	// Name is a label the user picks, e.g. "MacBook" or "YubiKey"
+	Name string `gorm:"size:100" json:"name"`
+
+	CredentialID    []byte `gorm:"not null;uniqueIndex" json:"-"`
+	PublicKey       []byte `gorm:"not null" json:"-"`
+	AttestationType string `gorm:"size:32" json:"-"`
+	AAGUID          []byte `gorm:"column:aaguid" json:"-"`
+	Transports      string `gorm:"size:100" json:"-"` // comma-separated
+
+	// SignCount is the authenticator's signature counter; a counter that goes
+	// backwards means the credential may have been cloned
+	SignCount      uint32 `gorm:"not null;default:0" json:"-"`
+	BackupEligible bool   `json:"backup_eligible"`
+	BackupState    bool   `json:"backed_up"`
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
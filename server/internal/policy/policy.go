@@ -0,0 +1,82 @@
+// Package policy decides who may act on posts, comments and messages.
+// Services load the acting user and the resource, then ask here instead of
+// comparing owner IDs themselves.
+package policy
+
+import "github.com/Bauka07/SocialApp/internal/models"
+
+// CanEditPost - Only the author edits a post; moderators remove, they don't rewrite
+func CanEditPost(actor *models.User, post *models.Post) bool {
+	return actor.ID == post.UserID
+}
+
+// CanChangePostVisibility - Only the author decides who sees a post;
+// moderators hide posts instead
+func CanChangePostVisibility(actor *models.User, post *models.Post) bool {
+	return actor.ID == post.UserID
+}
+
+// CanDeletePost - The author, or anyone who can moderate content
+func CanDeletePost(actor *models.User, post *models.Post) bool {
+	return actor.ID == post.UserID || actor.HasPermission(models.PermModerateContent)
+}
+
+// CanEditComment - Only the author edits a comment
+func CanEditComment(actor *models.User, comment *models.Comment) bool {
+	return actor.ID == comment.UserID
+}
+
+// CanDeleteComment - The author, or anyone who can moderate content
+func CanDeleteComment(actor *models.User, comment *models.Comment) bool {
+	return actor.ID == comment.UserID || actor.HasPermission(models.PermModerateContent)
+}
+
+// CanEditMessage - Only the sender edits a message
+func CanEditMessage(actor *models.User, message *models.Message) bool {
+	return actor.ID == message.SenderID
+}
+
+// CanDeleteMessage - The sender, or anyone who can moderate content (which
+// removes it for both participants)
+func CanDeleteMessage(actor *models.User, message *models.Message) bool {
+	return actor.ID == message.SenderID || actor.HasPermission(models.PermModerateContent)
+}
+
+// IsModeratorAction reports whether actor is acting on someone else's content
+// through a moderation permission rather than as its owner
+func IsModeratorAction(actor *models.User, ownerID uint) bool {
+	return actor.ID != ownerID && actor.HasPermission(models.PermModerateContent)
+}
+
+// CanChangeRole - Admins manage roles, but not their own, so the last admin
+// can't lock everyone out by accident
+func CanChangeRole(actor *models.User, target *models.User) bool {
+	return actor.HasPermission(models.PermManageRoles) && actor.ID != target.ID
+}
+
+// CanManageUser - Suspend, ban, log out or reset another account. Admins
+// can't be acted on this way; demote them first.
+func CanManageUser(actor *models.User, target *models.User) bool {
+	return actor.HasPermission(models.PermManageUsers) &&
+		actor.ID != target.ID &&
+		target.Role != models.RoleAdmin
+}
+
+// CanSuspendReportedUser - Suspending from the moderation queue. Anyone who
+// can manage the account may; moderators may too, unless the target is staff.
+func CanSuspendReportedUser(actor *models.User, target *models.User) bool {
+	if CanManageUser(actor, target) {
+		return true
+	}
+	return actor.HasPermission(models.PermModerateContent) &&
+		actor.ID != target.ID &&
+		!target.HasPermission(models.PermModerateContent)
+}
+
+// CanShadowBan - Moderators quietly hide spam accounts. Staff accounts can't
+// be shadow-banned.
+func CanShadowBan(actor *models.User, target *models.User) bool {
+	return actor.HasPermission(models.PermModerateContent) &&
+		actor.ID != target.ID &&
+		!target.HasPermission(models.PermModerateContent)
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestCanSuspendReportedUser(t *testing.T) {
+	user := &models.User{ID: 1, Role: models.RoleUser}
+	otherUser := &models.User{ID: 2, Role: models.RoleUser}
+	moderator := &models.User{ID: 3, Role: models.RoleModerator}
+	otherModerator := &models.User{ID: 4, Role: models.RoleModerator}
+	admin := &models.User{ID: 5, Role: models.RoleAdmin}
+	otherAdmin := &models.User{ID: 6, Role: models.RoleAdmin}
+
+	tests := []struct {
+		name   string
+		actor  *models.User
+		target *models.User
+		want   bool
+	}{
+		{name: "moderator suspends a user", actor: moderator, target: user, want: true},
+		{name: "moderator suspends a moderator", actor: moderator, target: otherModerator, want: false},
+		{name: "moderator suspends an admin", actor: moderator, target: admin, want: false},
+		{name: "moderator suspends themselves", actor: moderator, target: moderator, want: false},
+		{name: "admin suspends a moderator", actor: admin, target: moderator, want: true},
+		{name: "admin suspends an admin", actor: admin, target: otherAdmin, want: false},
+		{name: "user suspends a user", actor: user, target: otherUser, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanSuspendReportedUser(tt.actor, tt.target); got != tt.want {
+				t.Fatalf("CanSuspendReportedUser() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanChangePostVisibility(t *testing.T) {
+	author := &models.User{ID: 1, Role: models.RoleUser}
+	post := &models.Post{UserID: author.ID}
+
+	tests := []struct {
+		name  string
+		actor *models.User
+		want  bool
+	}{
+		{name: "author", actor: author, want: true},
+		{name: "another user", actor: &models.User{ID: 2, Role: models.RoleUser}, want: false},
+		{name: "moderator", actor: &models.User{ID: 3, Role: models.RoleModerator}, want: false},
+		{name: "admin", actor: &models.User{ID: 4, Role: models.RoleAdmin}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanChangePostVisibility(tt.actor, post); got != tt.want {
+				t.Fatalf("CanChangePostVisibility() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
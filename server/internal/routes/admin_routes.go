@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"github.com/Bauka07/SocialApp/internal/controllers"
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func AdminRoutes(r *gin.Engine) {
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthCheck())
+	{
+		admin.GET("/roles", middleware.RequirePermission(models.PermManageRoles), controllers.GetRoles)
+		admin.PUT("/users/:id/role", middleware.RequirePermission(models.PermManageRoles), controllers.SetUserRole)
+
+		// Moderators quietly hide spam accounts without banning them
+		admin.POST("/users/:id/shadow-ban", middleware.RequirePermission(models.PermModerateContent), controllers.AdminShadowBanUser)
+		admin.DELETE("/users/:id/shadow-ban", middleware.RequirePermission(models.PermModerateContent), controllers.AdminLiftShadowBan)
+	}
+
+	users := admin.Group("/users")
+	users.Use(middleware.RequirePermission(models.PermManageUsers))
+	{
+		users.GET("", controllers.AdminListUsers)
+		users.GET("/:id", controllers.AdminGetUser)
+		users.POST("/:id/suspend", controllers.AdminSuspendUser)
+		users.POST("/:id/ban", controllers.AdminBanUser)
+		users.POST("/:id/reinstate", controllers.AdminReinstateUser)
+		users.POST("/:id/logout", controllers.AdminForceLogout)
+		users.POST("/:id/password-reset", controllers.AdminForcePasswordReset)
+	}
+
+	// Moderation queue
+	reports := admin.Group("/reports")
+	reports.Use(middleware.RequirePermission(models.PermModerateContent))
+	{
+		reports.GET("", controllers.GetReportQueue)
+		reports.GET("/:id", controllers.GetReport)
+		reports.POST("/:id/assign", controllers.AssignReport)
+		reports.POST("/:id/resolve", controllers.ResolveReport)
+	}
+
+	// Keyword, regex and domain filters for new content
+	filters := admin.Group("/filters")
+	filters.Use(middleware.RequirePermission(models.PermManageFilters))
+	{
+		filters.GET("", controllers.GetFilterRules)
+		filters.POST("", controllers.CreateFilterRule)
+		filters.POST("/test", controllers.TestContentFilter)
+		filters.PUT("/:id", controllers.UpdateFilterRule)
+		filters.DELETE("/:id", controllers.DeleteFilterRule)
+	}
+
+	// Append-only trail of security and moderation events
+	admin.GET("/audit", middleware.RequirePermission(models.PermViewAuditLog), controllers.GetAuditLog)
+}
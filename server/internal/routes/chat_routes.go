@@ -3,24 +3,45 @@ package routes
 import (
 	"github.com/Bauka07/SocialApp/internal/controllers"
 	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
 func ChatRoutes(r *gin.Engine) {
 	r.GET("/ws", controllers.WebSocketHandler)
 
+	// Profile lookups live under /api for the chat client but need profile:read
+	// from personal access tokens, not the message scopes below
+	profile := r.Group("/api")
+	profile.Use(middleware.RequireScope(models.ScopeProfileRead), middleware.AuthCheck())
+	{
+		profile.GET("/users/search", controllers.SearchUsers)
+		profile.GET("/user/me", controllers.GetMyProfile)
+	}
+
+	// Server-Sent Events fallback for clients behind proxies that block /ws.
+	// EventSource can't send headers, so the stream is opened with a
+	// one-time ticket fetched over an authenticated request.
+	events := r.Group("/api/events")
+	{
+		events.POST("/ticket", middleware.RequireScope(models.ScopeMessagesRead), middleware.AuthCheck(), controllers.CreateEventTicket)
+		events.GET("", controllers.StreamEvents)
+	}
+
 	api := r.Group("/api")
+	// Personal access tokens need messages:read for GETs and messages:send otherwise
+	api.Use(middleware.ScopeByMethod(models.ScopeMessagesRead, models.ScopeMessagesSend))
 	api.Use(middleware.AuthCheck())
 	{
 		api.GET("/chats", controllers.GetChats)
+		api.POST("/messages", controllers.SendMessage)
 		api.GET("/messages/:user_id", controllers.GetMessages)
 		api.PUT("/messages/:message_id/read", controllers.MarkMessageAsRead)
-		api.GET("/users/search", controllers.SearchUsers)
-		api.GET("/user/me", controllers.GetMyProfile)
 
 		// Message management (owner only)
 		api.PUT("/messages/:message_id", controllers.EditMessage)
 		api.DELETE("/messages/:message_id", controllers.DeleteMessage)
+		api.POST("/messages/:message_id/report", controllers.ReportMessage)
 
 		// Chat management
 		api.DELETE("/chats/:user_id", controllers.DeleteChat)
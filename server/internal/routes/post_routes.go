@@ -3,11 +3,14 @@ package routes
 import (
 	"github.com/Bauka07/SocialApp/internal/controllers"
 	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
 func PostRoutes(r *gin.Engine) {
 	posts := r.Group("/posts")
+	// Personal access tokens need posts:read for GETs and posts:write otherwise
+	posts.Use(middleware.ScopeByMethod(models.ScopePostsRead, models.ScopePostsWrite))
 	{
 
 		// Feed endpoints (specific routes first)
@@ -22,8 +25,9 @@ func PostRoutes(r *gin.Engine) {
 		posts.POST("", middleware.AuthCheck(), controllers.CreatePost)
 
 		// Wildcard routes MUST come LAST
-		posts.GET("/:id", controllers.GetPostByID)
+		posts.GET("/:id", middleware.OptionalAuth(), controllers.GetPostByID)
 		posts.PUT("/:id", middleware.AuthCheck(), controllers.UpdatePost)
+		posts.PUT("/:id/visibility", middleware.AuthCheck(), controllers.UpdatePostVisibility)
 		posts.DELETE("/:id", middleware.AuthCheck(), controllers.DeletePost)
 		posts.POST("/:id/upload-image", middleware.AuthCheck(), controllers.UploadPostImage)
 
@@ -32,12 +36,15 @@ func PostRoutes(r *gin.Engine) {
 		posts.GET("/:id/likes", middleware.AuthCheck(), controllers.GetPostLikes)
 		posts.POST("/:id/comments", middleware.AuthCheck(), controllers.CreateComment)
 		posts.GET("/:id/comments", middleware.AuthCheck(), controllers.GetPostComments)
+		posts.POST("/:id/report", middleware.AuthCheck(), controllers.ReportPost)
 	}
 
 	// Comment management
 	comments := r.Group("/comments")
+	comments.Use(middleware.ScopeByMethod(models.ScopePostsRead, models.ScopePostsWrite))
 	{
 		comments.PUT("/:id", middleware.AuthCheck(), controllers.UpdateComment)
 		comments.DELETE("/:id", middleware.AuthCheck(), controllers.DeleteComment)
+		comments.POST("/:id/report", middleware.AuthCheck(), controllers.ReportComment)
 	}
 }
@@ -0,0 +1,75 @@
+package routes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRouter registers every route group the way cmd/main.go does; gin
+// panics here if two routes conflict
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	UserRoutes(r)
+	ContactRoutes(r)
+	PostRoutes(r)
+	ChatRoutes(r)
+	AdminRoutes(r)
+	SetupPasswordResetRoutes(r)
+	return r
+}
+
+func TestRouteLookup(t *testing.T) {
+	registered := map[string]string{}
+	for _, route := range newTestRouter(t).Routes() {
+		registered[route.Method+" "+route.Path] = route.Handler
+	}
+
+	tests := []struct {
+		route   string
+		handler string
+	}{
+		{route: "GET /users/:id", handler: "GetPublicProfile"},
+		{route: "GET /users/blocked", handler: "GetBlockedUsers"},
+		{route: "GET /users/sessions", handler: "GetSessions"},
+		{route: "GET /api/user/me", handler: "GetMyProfile"},
+		{route: "GET /api/users/search", handler: "SearchUsers"},
+		{route: "POST /api/events/ticket", handler: "CreateEventTicket"},
+		{route: "GET /api/events", handler: "StreamEvents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			handler, ok := registered[tt.route]
+			if !ok {
+				t.Fatalf("%s is not registered", tt.route)
+			}
+			if !strings.HasSuffix(handler, "controllers."+tt.handler) {
+				t.Fatalf("%s is handled by %s, want %s", tt.route, handler, tt.handler)
+			}
+		})
+	}
+
+}
+
+// Every static /users route shadows the profile of a user with that name
+func TestStaticUserRoutesAreReservedUsernames(t *testing.T) {
+	for _, route := range newTestRouter(t).Routes() {
+		segment, ok := strings.CutPrefix(route.Path, "/users/")
+		if !ok {
+			continue
+		}
+		segment, _, _ = strings.Cut(segment, "/")
+		if strings.HasPrefix(segment, ":") {
+			continue
+		}
+		if !services.IsReservedUsername(segment) {
+			t.Errorf("%s %s: %q is not a reserved username", route.Method, route.Path, segment)
+		}
+	}
+}
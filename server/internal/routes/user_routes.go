@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/Bauka07/SocialApp/internal/controllers"
 	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,16 +12,104 @@ func UserRoutes(r *gin.Engine) {
 	{
 		users.POST("/register", controllers.RegisterWithRecaptcha)
 		users.POST("/login", controllers.LoginWithRecaptcha)
-		users.GET("/me", middleware.AuthCheck(), controllers.GetMyProfile)
+		users.POST("/login/2fa", controllers.VerifyTwoFactorLogin)
+		users.GET("/me", middleware.RequireScope(models.ScopeProfileRead), middleware.AuthCheck(), controllers.GetMyProfile)
 		users.PUT("/update", middleware.AuthCheck(), controllers.UpdateProfile)
+		users.POST("/email/confirm", middleware.AuthCheck(), controllers.ConfirmEmailChange)
+		users.DELETE("/email/pending", middleware.AuthCheck(), controllers.CancelEmailChange)
 		users.PUT("/password", middleware.AuthCheck(), controllers.UpdatePassword)
+		users.POST("/password", middleware.AuthCheck(), controllers.SetPassword)
 		users.POST("/upload-image", middleware.AuthCheck(), controllers.UploadProfileImage)
+		users.PUT("/profile", middleware.AuthCheck(), controllers.UpdateProfileDetails)
+		users.POST("/upload-cover", middleware.AuthCheck(), controllers.UploadCoverImage)
+
+		// Session management
+		users.POST("/logout", middleware.AuthCheck(), controllers.Logout)
+		users.GET("/sessions", middleware.AuthCheck(), controllers.GetSessions)
+		users.DELETE("/sessions", middleware.AuthCheck(), controllers.RevokeAllSessions)
+		users.DELETE("/sessions/:id", middleware.AuthCheck(), controllers.RevokeSession)
+		users.GET("/login-history", middleware.AuthCheck(), controllers.GetLoginHistory)
+		users.GET("/security-events", middleware.AuthCheck(), controllers.GetSecurityEvents)
+
+		// Linked login methods
+		users.GET("/identities", middleware.AuthCheck(), controllers.GetIdentities)
+		users.POST("/identities/google", middleware.AuthCheck(), controllers.LinkGoogleIdentity)
+		users.POST("/identities/confirm", middleware.AuthCheck(), controllers.ConfirmIdentityLink)
+		users.POST("/identities/:provider/link", middleware.AuthCheck(), controllers.StartIdentityLink)
+		users.DELETE("/identities/:id", middleware.AuthCheck(), controllers.UnlinkIdentity)
+
+		// Personal access tokens (only manageable with a login token)
+		users.GET("/tokens", middleware.AuthCheck(), controllers.GetAccessTokens)
+		users.POST("/tokens", middleware.AuthCheck(), controllers.CreateAccessToken)
+		users.DELETE("/tokens/:id", middleware.AuthCheck(), controllers.RevokeAccessToken)
+
+		// Passkeys
+		users.GET("/passkeys", middleware.AuthCheck(), controllers.GetPasskeys)
+		users.POST("/passkeys/register/begin", middleware.AuthCheck(), controllers.BeginPasskeyRegistration)
+		users.POST("/passkeys/register/finish", middleware.AuthCheck(), controllers.FinishPasskeyRegistration)
+		users.DELETE("/passkeys/:id", middleware.AuthCheck(), controllers.DeletePasskey)
+
+		// Two-factor authentication
+		users.POST("/2fa/setup", middleware.AuthCheck(), controllers.SetupTwoFactor)
+		users.POST("/2fa/confirm", middleware.AuthCheck(), controllers.ConfirmTwoFactor)
+		users.POST("/2fa/disable", middleware.AuthCheck(), controllers.DisableTwoFactor)
+		users.POST("/2fa/recovery-codes", middleware.AuthCheck(), controllers.RegenerateRecoveryCodes)
+
+		// Reporting other accounts
+		users.GET("/reports", middleware.AuthCheck(), controllers.GetMyReports)
+		users.POST("/:id/report", middleware.AuthCheck(), controllers.ReportUser)
+
+		// Followers and private accounts
+		users.PUT("/privacy", middleware.AuthCheck(), controllers.UpdatePrivacy)
+		users.GET("/follow-requests", middleware.AuthCheck(), controllers.GetFollowRequests)
+		users.POST("/follow-requests/:id/approve", middleware.AuthCheck(), controllers.ApproveFollowRequest)
+		users.POST("/follow-requests/:id/deny", middleware.AuthCheck(), controllers.DenyFollowRequest)
+		users.DELETE("/followers/:id", middleware.AuthCheck(), controllers.RemoveFollower)
+		users.POST("/:id/follow", middleware.AuthCheck(), controllers.FollowUser)
+		users.DELETE("/:id/follow", middleware.AuthCheck(), controllers.UnfollowUser)
+		users.GET("/:id/followers", middleware.AuthCheck(), controllers.GetFollowers)
+		users.GET("/:id/following", middleware.AuthCheck(), controllers.GetFollowing)
+
+		// Close friends, the audience of close_friends posts
+		users.GET("/close-friends", middleware.AuthCheck(), controllers.GetCloseFriends)
+		users.POST("/close-friends/:id", middleware.AuthCheck(), controllers.AddCloseFriend)
+		users.DELETE("/close-friends/:id", middleware.AuthCheck(), controllers.RemoveCloseFriend)
+
+		// Blocking and muting
+		users.GET("/blocked", middleware.AuthCheck(), controllers.GetBlockedUsers)
+		users.GET("/muted", middleware.AuthCheck(), controllers.GetMutedUsers)
+		users.POST("/:id/block", middleware.AuthCheck(), controllers.BlockUser)
+		users.DELETE("/:id/block", middleware.AuthCheck(), controllers.UnblockUser)
+		users.POST("/:id/mute", middleware.AuthCheck(), controllers.MuteUser)
+		users.DELETE("/:id/mute", middleware.AuthCheck(), controllers.UnmuteUser)
+
+		// Public profile by username. gin wants one wildcard name per path
+		// segment, so it's :id here too. Static routes above win, which is why
+		// their names are reserved usernames (services.IsReservedUsername).
+		users.GET("/:id", middleware.OptionalAuth(), controllers.GetPublicProfile)
 	}
 
+	// Token verification keys for other internal services
+	r.GET("/.well-known/jwks.json", controllers.JWKS)
+
 	// OAuth routes
 	auth := r.Group("/auth")
 	{
-		// Server-side OAuth flow (currently unused but kept for future)
+		auth.POST("/refresh", controllers.RefreshToken)
+		auth.POST("/verify-email", controllers.VerifyEmail)
+		auth.POST("/resend-verification", controllers.ResendVerification)
+
+		// Passwordless login with a passkey
+		auth.POST("/passkeys/login/begin", controllers.BeginPasskeyLogin)
+		auth.POST("/passkeys/login/finish", controllers.FinishPasskeyLogin)
+
+		// Server-side login with any configured OIDC/OAuth2 provider
+		auth.GET("/providers", controllers.ListLoginProviders)
+		auth.GET("/oidc/:provider/login", controllers.OIDCLogin)
+		auth.GET("/oidc/:provider/callback", controllers.OIDCCallback)
+		auth.POST("/exchange", controllers.ExchangeLoginCode)
+
+		// Google server-side flow at its original paths
 		auth.GET("/google", controllers.GoogleLogin)
 		auth.GET("/google/callback", controllers.GoogleCallback)
 
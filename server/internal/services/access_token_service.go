@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+)
+
+// Personal access token limits
+const (
+	MaxAccessTokensPerUser = 20
+	DefaultAccessTokenDays = 30
+	MaxAccessTokenDays     = 365
+)
+
+// ScopeInfo describes a scope for the token settings page
+type ScopeInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ListTokenScopes returns every scope a token can be granted, sorted by name
+func ListTokenScopes() []ScopeInfo {
+	scopes := make([]ScopeInfo, 0, len(models.TokenScopes))
+	for name, description := range models.TokenScopes {
+		scopes = append(scopes, ScopeInfo{Name: name, Description: description})
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i].Name < scopes[j].Name })
+	return scopes
+}
+
+// CreateAccessToken issues a personal access token. The token itself is
+// returned only here; afterwards just its hash is kept.
+func CreateAccessToken(userID uint, name string, scopes []string, expiresInDays int) (string, *models.PersonalAccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("token name is required")
+	}
+
+	if expiresInDays == 0 {
+		expiresInDays = DefaultAccessTokenDays
+	}
+	if expiresInDays < 1 || expiresInDays > MaxAccessTokenDays {
+		return "", nil, fmt.Errorf("expiry must be between 1 and %d days", MaxAccessTokenDays)
+	}
+
+	granted, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return "", nil, errors.New("database error")
+	}
+	if count >= MaxAccessTokensPerUser {
+		return "", nil, errors.New("you have reached the maximum number of access tokens, revoke one first")
+	}
+
+	secret, err := randomURLToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	token := models.PersonalAccessTokenPrefix + secret
+
+	pat := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      truncate(name, 100),
+		TokenHash: middleware.HashAccessToken(token),
+		Hint:      token[:len(models.PersonalAccessTokenPrefix)+4],
+		Scopes:    granted,
+		ExpiresAt: time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+	}
+	if err := database.DB.Create(&pat).Error; err != nil {
+		return "", nil, errors.New("failed to create access token")
+	}
+
+	log.Printf("✅ Access token %d created for user %d (%s)", pat.ID, userID, strings.Join(granted, " "))
+	return token, &pat, nil
+}
+
+// normalizeScopes checks every scope is known and drops duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var granted []string
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if _, ok := models.TokenScopes[scope]; !ok {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			granted = append(granted, scope)
+		}
+	}
+	if len(granted) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	sort.Strings(granted)
+	return granted, nil
+}
+
+// ListAccessTokens returns the user's personal access tokens, newest first
+func ListAccessTokens(userID uint) ([]models.PersonalAccessToken, error) {
+	var tokens []models.PersonalAccessToken
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, errors.New("failed to fetch access tokens")
+	}
+	return tokens, nil
+}
+
+// RevokeAccessToken deletes one of the user's personal access tokens
+func RevokeAccessToken(userID, tokenID uint) error {
+	result := database.DB.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		return errors.New("failed to revoke access token")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("access token not found")
+	}
+
+	log.Printf("✅ Access token %d revoked by user %d", tokenID, userID)
+	return nil
+}
+
+// RevokeAllAccessTokens deletes every personal access token of the user.
+// Access tokens outlive sessions, so anything that logs a user out everywhere
+// has to call this too.
+func RevokeAllAccessTokens(userID uint) error {
+	result := revokeAccessTokens(database.DB, userID)
+	if result.Error != nil {
+		return errors.New("failed to revoke access tokens")
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("✅ Revoked %d access tokens for user %d", result.RowsAffected, userID)
+	}
+	return nil
+}
+
+// revokeAccessTokens deletes the user's personal access tokens using db,
+// which may be a transaction
+func revokeAccessTokens(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Where("user_id = ?", userID).Delete(&models.PersonalAccessToken{})
+}
+
+// CleanupExpiredAccessTokens removes personal access tokens past their expiry (see StartCleanupJobs)
+func CleanupExpiredAccessTokens() error {
+	return database.DB.Where("expires_at < ?", time.Now()).Delete(&models.PersonalAccessToken{}).Error
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPasswordChangeRevokesAccessTokens(t *testing.T) {
+	newTestDB(t)
+	user := createTestUser(t, "alice")
+	hash, err := bcrypt.GenerateFromPassword([]byte("OldPassw0rd!"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	database.DB.Model(user).Update("password", string(hash))
+
+	if _, _, err := CreateAccessToken(user.ID, "script", []string{models.ScopePostsRead}, 30); err != nil {
+		t.Fatalf("create access token: %v", err)
+	}
+
+	if err := UpdateUserPassword(user.ID, 0, "OldPassw0rd!", "NewPassw0rd!"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+
+	var count int64
+	database.DB.Model(&models.PersonalAccessToken{}).Where("user_id = ?", user.ID).Count(&count)
+	if count != 0 {
+		t.Fatalf("%d access tokens left after a password change", count)
+	}
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/policy"
+	"gorm.io/gorm"
+)
+
+// Suspension limits; anything longer should be a ban
+const (
+	MinSuspension = time.Hour
+	MaxSuspension = 365 * 24 * time.Hour
+)
+
+// Account status filters for ListUsers
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusBanned    = "banned"
+	// Not a status of its own; a shadow-banned account can also be suspended
+	UserStatusShadowBanned = "shadow_banned"
+)
+
+// AdminUser is an account as shown to admins
+type AdminUser struct {
+	ID                uint       `json:"id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	ImageURL          string     `json:"image_url,omitempty"`
+	Role              string     `json:"role"`
+	Status            string     `json:"status"`
+	EmailVerified     bool       `json:"email_verified"`
+	TwoFactorEnabled  bool       `json:"two_factor_enabled"`
+	SuspendedUntil    *time.Time `json:"suspended_until,omitempty"`
+	BannedAt          *time.Time `json:"banned_at,omitempty"`
+	RestrictionReason string     `json:"restriction_reason,omitempty"`
+	ShadowBannedAt    *time.Time `json:"shadow_banned_at,omitempty"`
+}
+
+// AdminUserDetails adds activity counts and recent logins to AdminUser
+type AdminUserDetails struct {
+	AdminUser
+	PostCount      int64                 `json:"post_count"`
+	CommentCount   int64                 `json:"comment_count"`
+	ActiveSessions int64                 `json:"active_sessions"`
+	Identities     []models.UserIdentity `json:"identities"`
+	RecentLogins   []models.LoginAttempt `json:"recent_logins"`
+}
+
+func toAdminUser(user *models.User) AdminUser {
+	status := UserStatusActive
+	switch {
+	case user.IsBanned():
+		status = UserStatusBanned
+	case user.IsSuspended():
+		status = UserStatusSuspended
+	}
+
+	return AdminUser{
+		ID:                user.ID,
+		CreatedAt:         user.CreatedAt,
+		Username:          user.Username,
+		Email:             user.Email,
+		ImageURL:          user.ImageURL,
+		Role:              user.Role,
+		Status:            status,
+		EmailVerified:     user.EmailVerified,
+		TwoFactorEnabled:  user.TwoFactorEnabled,
+		SuspendedUntil:    user.SuspendedUntil,
+		BannedAt:          user.BannedAt,
+		RestrictionReason: user.RestrictionReason,
+		ShadowBannedAt:    user.ShadowBannedAt,
+	}
+}
+
+// ListUsers searches accounts by username or email, optionally filtered by
+// status, newest first
+func ListUsers(query, status string, page, pageSize int) ([]AdminUser, int64, error) {
+	db := database.DB.Model(&models.User{})
+
+	if query = strings.TrimSpace(query); query != "" {
+		like := "%" + strings.ToLower(query) + "%"
+		db = db.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ?", like, like)
+	}
+
+	now := time.Now()
+	switch status {
+	case "":
+	case UserStatusBanned:
+		db = db.Where("banned_at IS NOT NULL")
+	case UserStatusSuspended:
+		db = db.Where("banned_at IS NULL AND suspended_until > ?", now)
+	case UserStatusActive:
+		db = db.Where("banned_at IS NULL AND (suspended_until IS NULL OR suspended_until <= ?)", now)
+	case UserStatusShadowBanned:
+		db = db.Where("shadow_banned_at IS NOT NULL")
+	default:
+		return nil, 0, errors.New("unknown status filter")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count users")
+	}
+
+	var users []models.User
+	if err := db.Order("created_at DESC").Offset(page * pageSize).Limit(pageSize).Find(&users).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch users")
+	}
+
+	result := make([]AdminUser, 0, len(users))
+	for i := range users {
+		result = append(result, toAdminUser(&users[i]))
+	}
+	return result, total, nil
+}
+
+// GetUserDetails returns an account with its activity for the admin view
+func GetUserDetails(userID uint) (*AdminUserDetails, error) {
+	var user models.User
+	if err := database.DB.Preload("Identities").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user")
+	}
+
+	details := AdminUserDetails{AdminUser: toAdminUser(&user), Identities: user.Identities}
+	if details.Identities == nil {
+		details.Identities = []models.UserIdentity{}
+	}
+
+	database.DB.Model(&models.Post{}).Where("user_id = ?", userID).Count(&details.PostCount)
+	database.DB.Model(&models.Comment{}).Where("user_id = ?", userID).Count(&details.CommentCount)
+	database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Count(&details.ActiveSessions)
+
+	logins, err := GetLoginHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	details.RecentLogins = logins
+
+	return &details, nil
+}
+
+// loadManagedUser loads the actor and target of an admin action and checks
+// the actor may manage the target
+func loadManagedUser(actorID, targetID uint) (*models.User, *models.User, error) {
+	actor, err := loadActor(actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, targetID).Error; err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+
+	if !policy.CanManageUser(actor, &target) {
+		return nil, nil, errors.New("you don't have permission to manage this user")
+	}
+	return actor, &target, nil
+}
+
+// SuspendUser blocks an account from logging in until the suspension ends
+// and revokes its sessions. The caller closes its live connections.
+func SuspendUser(actorID, targetID uint, duration time.Duration, reason string) (*AdminUser, error) {
+	if err := validateSuspension(duration, reason); err != nil {
+		return nil, err
+	}
+
+	actor, target, err := loadManagedUser(actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return suspendUser(actor, target, duration, reason)
+}
+
+// SuspendReportedUser is SuspendUser for the moderation queue, where
+// moderators may suspend the author of reported content too
+func SuspendReportedUser(actorID, targetID uint, duration time.Duration, reason string) (*AdminUser, error) {
+	if err := validateSuspension(duration, reason); err != nil {
+		return nil, err
+	}
+
+	actor, err := loadActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, targetID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !policy.CanSuspendReportedUser(actor, &target) {
+		return nil, errors.New("you don't have permission to suspend this user")
+	}
+
+	return suspendUser(actor, &target, duration, reason)
+}
+
+func validateSuspension(duration time.Duration, reason string) error {
+	if duration < MinSuspension || duration > MaxSuspension {
+		return fmt.Errorf("suspension must last between %s and %d days, ban the account instead", MinSuspension, int(MaxSuspension.Hours()/24))
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("a reason is required")
+	}
+	return nil
+}
+
+// suspendUser applies a suspension the caller has already authorized
+func suspendUser(actor, target *models.User, duration time.Duration, reason string) (*AdminUser, error) {
+	if target.IsBanned() {
+		return nil, errors.New("user is already banned")
+	}
+
+	reason = strings.TrimSpace(reason)
+	until := time.Now().Add(duration)
+	if err := restrictUser(target, map[string]interface{}{
+		"suspended_until":    until,
+		"restriction_reason": truncate(reason, 500),
+	}); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛡️ User %d suspended user %d until %s: %s", actor.ID, target.ID, until.Format(time.RFC3339), reason)
+	result := toAdminUser(target)
+	return &result, nil
+}
+
+// BanUser permanently blocks an account and revokes its sessions. The
+// caller closes its live connections.
+func BanUser(actorID, targetID uint, reason string) (*AdminUser, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required")
+	}
+
+	actor, target, err := loadManagedUser(actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := restrictUser(target, map[string]interface{}{
+		"banned_at":          time.Now(),
+		"suspended_until":    nil,
+		"restriction_reason": truncate(reason, 500),
+	}); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛡️ Admin %d banned user %d: %s", actor.ID, target.ID, reason)
+	result := toAdminUser(target)
+	return &result, nil
+}
+
+// restrictUser applies a suspension or ban and revokes the user's sessions and
+// access tokens in one transaction
+func restrictUser(target *models.User, updates map[string]interface{}) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(target).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx.Where("user_id = ? AND revoked_at IS NULL", target.ID)).Error; err != nil {
+			return err
+		}
+		return revokeAccessTokens(tx, target.ID).Error
+	})
+	if err != nil {
+		return errors.New("failed to update account")
+	}
+
+	// Model().Updates doesn't copy nil values back
+	return database.DB.First(target, target.ID).Error
+}
+
+// ReinstateUser lifts a suspension or ban
+func ReinstateUser(actorID, targetID uint) (*AdminUser, error) {
+	actor, target, err := loadManagedUser(actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !target.IsBanned() && !target.IsSuspended() {
+		return nil, errors.New("user is not suspended or banned")
+	}
+
+	if err := database.DB.Model(target).Updates(map[string]interface{}{
+		"suspended_until":    nil,
+		"banned_at":          nil,
+		"restriction_reason": "",
+	}).Error; err != nil {
+		return nil, errors.New("failed to update account")
+	}
+	target.SuspendedUntil = nil
+	target.BannedAt = nil
+	target.RestrictionReason = ""
+
+	log.Printf("🛡️ Admin %d reinstated user %d", actor.ID, target.ID)
+	result := toAdminUser(target)
+	return &result, nil
+}
+
+// loadShadowBanTarget loads the actor and target of a shadow-ban and checks
+// the actor may shadow-ban the target
+func loadShadowBanTarget(actorID, targetID uint) (*models.User, *models.User, error) {
+	actor, err := loadActor(actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, targetID).Error; err != nil {
+		return nil, nil, errors.New("user not found")
+	}
+
+	if !policy.CanShadowBan(actor, &target) {
+		return nil, nil, errors.New("you don't have permission to shadow-ban this user")
+	}
+	return actor, &target, nil
+}
+
+// ShadowBanUser hides the account's posts, comments and messages from
+// everyone else without telling its owner. The reason only goes to the logs
+// and the audit trail.
+func ShadowBanUser(actorID, targetID uint, reason string) (*AdminUser, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required")
+	}
+
+	actor, target, err := loadShadowBanTarget(actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if target.IsShadowBanned() {
+		return nil, errors.New("user is already shadow-banned")
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(target).Update("shadow_banned_at", now).Error; err != nil {
+		return nil, errors.New("failed to update account")
+	}
+	target.ShadowBannedAt = &now
+
+	log.Printf("🛡️ Moderator %d shadow-banned user %d: %s", actor.ID, target.ID, reason)
+	result := toAdminUser(target)
+	return &result, nil
+}
+
+// LiftShadowBan makes the account's content visible to everyone again
+func LiftShadowBan(actorID, targetID uint) (*AdminUser, error) {
+	actor, target, err := loadShadowBanTarget(actorID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if !target.IsShadowBanned() {
+		return nil, errors.New("user is not shadow-banned")
+	}
+
+	if err := database.DB.Model(target).Update("shadow_banned_at", nil).Error; err != nil {
+		return nil, errors.New("failed to update account")
+	}
+	target.ShadowBannedAt = nil
+
+	log.Printf("🛡️ Moderator %d lifted the shadow-ban on user %d", actor.ID, target.ID)
+	result := toAdminUser(target)
+	return &result, nil
+}
+
+// ForceLogout revokes every session and personal access token of the user.
+// The caller closes their live connections.
+func ForceLogout(actorID, targetID uint) error {
+	actor, target, err := loadManagedUser(actorID, targetID)
+	if err != nil {
+		return err
+	}
+
+	if err := RevokeAllSessions(target.ID, 0); err != nil {
+		return err
+	}
+	if err := RevokeAllAccessTokens(target.ID); err != nil {
+		return err
+	}
+
+	log.Printf("🛡️ Admin %d logged out user %d everywhere", actor.ID, target.ID)
+	return nil
+}
+
+// ForcePasswordReset logs the user out everywhere, revokes their access
+// tokens and emails them a reset code through the normal forgot-password flow
+func ForcePasswordReset(actorID, targetID uint, ip string) error {
+	actor, target, err := loadManagedUser(actorID, targetID)
+	if err != nil {
+		return err
+	}
+
+	if err := RevokeAllSessions(target.ID, 0); err != nil {
+		return err
+	}
+	if err := RevokeAllAccessTokens(target.ID); err != nil {
+		return err
+	}
+
+	if err := InitiatePasswordReset(target.Email, ip); err != nil {
+		return err
+	}
+
+	log.Printf("🛡️ Admin %d forced a password reset for user %d", actor.ID, target.ID)
+	return nil
+}
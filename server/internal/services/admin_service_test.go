@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestAdminActionsRevokeAccessTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(actorID, targetID uint) error
+	}{
+		{name: "force logout", action: ForceLogout},
+		{name: "suspend", action: func(actorID, targetID uint) error {
+			_, err := SuspendUser(actorID, targetID, 24*time.Hour, "spam")
+			return err
+		}},
+		{name: "ban", action: func(actorID, targetID uint) error {
+			_, err := BanUser(actorID, targetID, "spam")
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			admin := createTestUser(t, "admin")
+			database.DB.Model(admin).Update("role", models.RoleAdmin)
+			target := createTestUser(t, "target")
+
+			if _, _, err := CreateAccessToken(target.ID, "script", []string{models.ScopePostsRead}, 30); err != nil {
+				t.Fatalf("create access token: %v", err)
+			}
+
+			if err := tt.action(admin.ID, target.ID); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+
+			var count int64
+			database.DB.Model(&models.PersonalAccessToken{}).Where("user_id = ?", target.ID).Count(&count)
+			if count != 0 {
+				t.Fatalf("%d access tokens left after %s", count, tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+)
+
+// AuditInfo describes who made the request an audited action came from.
+// ActorID is 0 for anonymous requests.
+type AuditInfo struct {
+	ActorID   uint
+	IPAddress string
+	UserAgent string
+}
+
+// RecordAudit appends an event to the audit log. A failure is logged rather
+// than returned, so auditing never undoes the action it records.
+func RecordAudit(action string, info AuditInfo, targetUserID uint, details map[string]interface{}) {
+	event := models.AuditEvent{
+		Action:    action,
+		IPAddress: truncate(info.IPAddress, 45),
+		UserAgent: truncate(info.UserAgent, 255),
+		Details:   details,
+	}
+	if info.ActorID != 0 {
+		actorID := info.ActorID
+		event.ActorID = &actorID
+	}
+	if targetUserID != 0 {
+		event.TargetUserID = &targetUserID
+	}
+
+	if err := database.DB.Create(&event).Error; err != nil {
+		log.Printf("❌ Failed to record audit event %s: %v", action, err)
+	}
+}
+
+// auditAppendOnlySQL installs triggers that reject changes to audit_events, so
+// the log stays append-only for raw SQL and other clients too, not just for
+// code that goes through the model's gorm hooks
+var auditAppendOnlySQL = map[string][]string{
+	"postgres": {
+		`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit log is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events`,
+		`CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+	FOR EACH ROW EXECUTE FUNCTION audit_events_append_only()`,
+		`DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events`,
+		`CREATE TRIGGER audit_events_no_truncate BEFORE TRUNCATE ON audit_events
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only()`,
+	},
+	"sqlite": {
+		`CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END`,
+	},
+}
+
+// EnforceAuditAppendOnly installs the database triggers that reject updates
+// and deletes on the audit log. It runs after every migration and is safe to
+// repeat.
+func EnforceAuditAppendOnly() error {
+	statements, ok := auditAppendOnlySQL[database.DB.Dialector.Name()]
+	if !ok {
+		return errors.New("append-only audit log is not supported for this database")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AuditUserIDByEmail resolves the account an anonymous request (like a password
+// reset) was about, or 0 when no account uses the email
+func AuditUserIDByEmail(email string) uint {
+	var user models.User
+	if err := database.DB.Select("id").Where("email = ?", email).First(&user).Error; err != nil {
+		return 0
+	}
+	return user.ID
+}
+
+// AuditFilter narrows the admin audit log. Action matches exactly, or as a
+// prefix when it ends with "*" (e.g. "admin.*"). Zero values don't filter.
+type AuditFilter struct {
+	Action       string
+	ActorID      uint
+	TargetUserID uint
+	IPAddress    string
+	Since        time.Time
+	Until        time.Time
+}
+
+// ListAuditEvents returns audit events matching filter, newest first
+func ListAuditEvents(filter AuditFilter, page, pageSize int) ([]models.AuditEvent, int64, error) {
+	db := database.DB.Model(&models.AuditEvent{})
+
+	if filter.Action != "" {
+		if prefix, ok := strings.CutSuffix(filter.Action, "*"); ok {
+			db = db.Where("action LIKE ?", prefix+"%")
+		} else {
+			db = db.Where("action = ?", filter.Action)
+		}
+	}
+	if filter.ActorID != 0 {
+		db = db.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetUserID != 0 {
+		db = db.Where("target_user_id = ?", filter.TargetUserID)
+	}
+	if filter.IPAddress != "" {
+		db = db.Where("ip_address = ?", filter.IPAddress)
+	}
+	if !filter.Since.IsZero() {
+		db = db.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		db = db.Where("created_at < ?", filter.Until)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count audit events")
+	}
+
+	var events []models.AuditEvent
+	if err := db.Order("created_at DESC, id DESC").Offset(page * pageSize).Limit(pageSize).Find(&events).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch audit events")
+	}
+	return events, total, nil
+}
+
+// SecurityEvent is an audit event as shown to the account owner. Actions by
+// staff don't reveal who took them or from where.
+type SecurityEvent struct {
+	ID        uint                   `json:"id"`
+	CreatedAt time.Time              `json:"created_at"`
+	Action    string                 `json:"action"`
+	IPAddress string                 `json:"ip_address,omitempty"`
+	Device    string                 `json:"device,omitempty"`
+	ByStaff   bool                   `json:"by_staff"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ListSecurityEvents returns the security events of the user's account,
+// newest first. Moderation bookkeeping about their content is left out.
+func ListSecurityEvents(userID uint, page, pageSize int) ([]SecurityEvent, bool, error) {
+	var events []models.AuditEvent
+	if err := database.DB.
+		Where("target_user_id = ? AND action NOT LIKE ?", userID, "moderation.%").
+		Order("created_at DESC, id DESC").
+		Offset(page * pageSize).
+		Limit(pageSize + 1).
+		Find(&events).Error; err != nil {
+		return nil, false, errors.New("failed to fetch security events")
+	}
+
+	hasMore := len(events) > pageSize
+	if hasMore {
+		events = events[:pageSize]
+	}
+
+	result := make([]SecurityEvent, 0, len(events))
+	for _, e := range events {
+		event := SecurityEvent{
+			ID:        e.ID,
+			CreatedAt: e.CreatedAt,
+			Action:    e.Action,
+			ByStaff:   e.IsStaffAction(),
+			Details:   e.Details,
+		}
+		if !event.ByStaff {
+			event.IPAddress = e.IPAddress
+			event.Device = describeDevice(e.UserAgent)
+		}
+		result = append(result, event)
+	}
+	return result, hasMore, nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestEnforceAuditAppendOnly(t *testing.T) {
+	newTestDB(t)
+	user := createTestUser(t, "alice")
+	RecordAudit(models.AuditLogin, AuditInfo{ActorID: user.ID}, user.ID, nil)
+
+	if err := EnforceAuditAppendOnly(); err != nil {
+		t.Fatalf("enforce append-only: %v", err)
+	}
+	// Runs on every start, so it has to be repeatable
+	if err := EnforceAuditAppendOnly(); err != nil {
+		t.Fatalf("enforce append-only twice: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		sql  string
+	}{
+		{name: "raw update", sql: "UPDATE audit_events SET action = 'auth.logout'"},
+		{name: "raw delete", sql: "DELETE FROM audit_events"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := database.DB.Exec(tt.sql).Error; err == nil {
+				t.Fatalf("%s should be rejected by the database", tt.name)
+			}
+		})
+	}
+
+	var events []models.AuditEvent
+	database.DB.Find(&events)
+	if len(events) != 1 || events[0].Action != models.AuditLogin {
+		t.Fatalf("audit log changed: %+v", events)
+	}
+
+	// Appending still works
+	RecordAudit(models.AuditLogout, AuditInfo{ActorID: user.ID}, user.ID, nil)
+	var count int64
+	database.DB.Model(&models.AuditEvent{}).Count(&count)
+	if count != 2 {
+		t.Fatalf("got %d audit events after appending, want 2", count)
+	}
+}
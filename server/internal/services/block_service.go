@@ -0,0 +1,156 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUserBlocked is returned when one of two users has blocked the other
+var ErrUserBlocked = errors.New("you can't message this user")
+
+// RelatedUser is an account on one of the current user's lists (blocks,
+// mutes, followers and follow requests)
+type RelatedUser struct {
+	ID       uint      `json:"id"`
+	Username string    `json:"username"`
+	ImageURL string    `json:"image_url,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+// loadOtherUser checks targetID is an existing account other than userID
+func loadOtherUser(userID, targetID uint) error {
+	if userID == targetID {
+		return errors.New("you can't do that to yourself")
+	}
+
+	var target models.User
+	if err := database.DB.Select("id").First(&target, targetID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// BlockUser blocks targetID for userID and ends any follows and close friend
+// listings between them. Blocking twice is a no-op.
+func BlockUser(userID, targetID uint) error {
+	if err := loadOtherUser(userID, targetID); err != nil {
+		return err
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		block := models.UserBlock{BlockerID: userID, BlockedID: targetID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+			userID, targetID, targetID, userID).Delete(&models.Follow{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("(user_id = ? AND friend_id = ?) OR (user_id = ? AND friend_id = ?)",
+			userID, targetID, targetID, userID).Delete(&models.CloseFriend{}).Error
+	})
+	if err != nil {
+		return errors.New("failed to block user")
+	}
+
+	log.Printf("✅ User %d blocked user %d", userID, targetID)
+	return nil
+}
+
+// UnblockUser removes userID's block on targetID
+func UnblockUser(userID, targetID uint) error {
+	result := database.DB.Where("blocker_id = ? AND blocked_id = ?", userID, targetID).Delete(&models.UserBlock{})
+	if result.Error != nil {
+		return errors.New("failed to unblock user")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user is not blocked")
+	}
+
+	log.Printf("✅ User %d unblocked user %d", userID, targetID)
+	return nil
+}
+
+// MuteUser mutes targetID for userID. Muting twice is a no-op.
+func MuteUser(userID, targetID uint) error {
+	if err := loadOtherUser(userID, targetID); err != nil {
+		return err
+	}
+
+	mute := models.UserMute{MuterID: userID, MutedID: targetID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error; err != nil {
+		return errors.New("failed to mute user")
+	}
+	return nil
+}
+
+// UnmuteUser removes userID's mute on targetID
+func UnmuteUser(userID, targetID uint) error {
+	result := database.DB.Where("muter_id = ? AND muted_id = ?", userID, targetID).Delete(&models.UserMute{})
+	if result.Error != nil {
+		return errors.New("failed to unmute user")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user is not muted")
+	}
+	return nil
+}
+
+// ListBlockedUsers returns the accounts userID has blocked, most recent first
+func ListBlockedUsers(userID uint) ([]RelatedUser, error) {
+	users := []RelatedUser{}
+	if err := database.DB.Table("user_blocks").
+		Select("users.id, users.username, users.image_url, user_blocks.created_at AS since").
+		Joins("JOIN users ON users.id = user_blocks.blocked_id AND users.deleted_at IS NULL").
+		Where("user_blocks.blocker_id = ?", userID).
+		Order("user_blocks.created_at DESC").
+		Scan(&users).Error; err != nil {
+		return nil, errors.New("failed to fetch blocked users")
+	}
+	return users, nil
+}
+
+// ListMutedUsers returns the accounts userID has muted, most recent first
+func ListMutedUsers(userID uint) ([]RelatedUser, error) {
+	users := []RelatedUser{}
+	if err := database.DB.Table("user_mutes").
+		Select("users.id, users.username, users.image_url, user_mutes.created_at AS since").
+		Joins("JOIN users ON users.id = user_mutes.muted_id AND users.deleted_at IS NULL").
+		Where("user_mutes.muter_id = ?", userID).
+		Order("user_mutes.created_at DESC").
+		Scan(&users).Error; err != nil {
+		return nil, errors.New("failed to fetch muted users")
+	}
+	return users, nil
+}
+
+// IsBlockedBetween reports whether either user has blocked the other
+func IsBlockedBetween(a, b uint) bool {
+	var count int64
+	database.DB.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count)
+	return count > 0
+}
+
+// BlockedUserIDs returns every account userID has blocked or been blocked by
+func BlockedUserIDs(userID uint) map[uint]bool {
+	var blocks []models.UserBlock
+	database.DB.Where("blocker_id = ? OR blocked_id = ?", userID, userID).Find(&blocks)
+
+	ids := make(map[uint]bool, len(blocks))
+	for _, b := range blocks {
+		if b.BlockerID == userID {
+			ids[b.BlockedID] = true
+		} else {
+			ids[b.BlockerID] = true
+		}
+	}
+	return ids
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+// createTestPost stores a public post by userID
+func createTestPost(t *testing.T, userID uint) *models.Post {
+	t.Helper()
+
+	post := models.Post{Title: "title", Content: "content", UserID: userID, Visibility: models.PostPublic}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatalf("create post: %v", err)
+	}
+	return &post
+}
+
+// feedHas reports whether the viewer's ranked feed contains postID
+func feedHas(t *testing.T, viewerID, postID uint) bool {
+	t.Helper()
+
+	posts, _, err := GetSmartFeed(viewerID, 0, 50)
+	if err != nil {
+		t.Fatalf("GetSmartFeed: %v", err)
+	}
+	for _, p := range posts {
+		if p["id"] == postID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBlockUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, blocker, target *models.User)
+		target  func(blocker, target *models.User) uint
+		wantErr bool
+	}{
+		{name: "yourself", target: func(blocker, _ *models.User) uint { return blocker.ID }, wantErr: true},
+		{name: "unknown user", target: func(*models.User, *models.User) uint { return 9999 }, wantErr: true},
+		{name: "no relationship"},
+		{
+			name: "ends follows both ways",
+			setup: func(t *testing.T, blocker, target *models.User) {
+				database.DB.Create(&models.Follow{FollowerID: blocker.ID, FollowingID: target.ID, Status: models.FollowAccepted})
+				database.DB.Create(&models.Follow{FollowerID: target.ID, FollowingID: blocker.ID, Status: models.FollowPending})
+			},
+		},
+		{
+			name: "ends close friend listings both ways",
+			setup: func(t *testing.T, blocker, target *models.User) {
+				database.DB.Create(&models.CloseFriend{UserID: blocker.ID, FriendID: target.ID})
+				database.DB.Create(&models.CloseFriend{UserID: target.ID, FriendID: blocker.ID})
+			},
+		},
+		{
+			name: "blocking twice",
+			setup: func(t *testing.T, blocker, target *models.User) {
+				if err := BlockUser(blocker.ID, target.ID); err != nil {
+					t.Fatalf("first block: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			blocker := createTestUser(t, "blocker")
+			target := createTestUser(t, "target")
+			if tt.setup != nil {
+				tt.setup(t, blocker, target)
+			}
+			targetID := target.ID
+			if tt.target != nil {
+				targetID = tt.target(blocker, target)
+			}
+
+			err := BlockUser(blocker.ID, targetID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BlockUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !IsBlockedBetween(blocker.ID, target.ID) || !IsBlockedBetween(target.ID, blocker.ID) {
+				t.Fatal("a block should apply in both directions")
+			}
+			var blocks, follows, friends int64
+			database.DB.Model(&models.UserBlock{}).Count(&blocks)
+			database.DB.Model(&models.Follow{}).Count(&follows)
+			database.DB.Model(&models.CloseFriend{}).Count(&friends)
+			if blocks != 1 || follows != 0 || friends != 0 {
+				t.Fatalf("got %d blocks, %d follows, %d close friends; want 1, 0, 0", blocks, follows, friends)
+			}
+		})
+	}
+}
+
+func TestUnblockUser(t *testing.T) {
+	newTestDB(t)
+	blocker := createTestUser(t, "blocker")
+	target := createTestUser(t, "target")
+
+	if err := UnblockUser(blocker.ID, target.ID); err == nil {
+		t.Fatal("unblocking a user who isn't blocked should fail")
+	}
+	if err := BlockUser(blocker.ID, target.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Only the blocker can lift the block
+	if err := UnblockUser(target.ID, blocker.ID); err == nil || !IsBlockedBetween(blocker.ID, target.ID) {
+		t.Fatal("the blocked user should not be able to lift the block")
+	}
+	if err := UnblockUser(blocker.ID, target.ID); err != nil {
+		t.Fatalf("UnblockUser: %v", err)
+	}
+	if IsBlockedBetween(blocker.ID, target.ID) {
+		t.Fatal("block still applies after unblocking")
+	}
+}
+
+func TestBlockEnforcement(t *testing.T) {
+	// Each action is taken by viewer against other, and reports whether it
+	// was allowed
+	actions := []struct {
+		name string
+		act  func(t *testing.T, viewer, other *models.User, otherPost *models.Post) bool
+	}{
+		{
+			name: "send message",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				_, err := SendMessage(&recordingNotifier{}, viewer.ID, other.ID, "hello", nil)
+				if err != nil && !errors.Is(err, ErrUserBlocked) {
+					t.Fatalf("SendMessage: %v", err)
+				}
+				return err == nil
+			},
+		},
+		{
+			name: "follow",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				_, err := FollowUser(&recordingNotifier{}, viewer.ID, other.ID)
+				return err == nil
+			},
+		},
+		{
+			name: "add close friend",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				return AddCloseFriend(viewer.ID, other.ID) == nil
+			},
+		},
+		{
+			name: "view content",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				return CanViewContent(viewer.ID, other.ID)
+			},
+		},
+		{
+			name: "view profile",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				_, err := GetPublicProfile(viewer.ID, other.Username)
+				return err == nil
+			},
+		},
+		{
+			name: "find in user search",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				var count int64
+				database.DB.Model(&models.User{}).Scopes(VisibleUsers(viewer.ID)).Where("users.id = ?", other.ID).Count(&count)
+				return count == 1
+			},
+		},
+		{
+			name: "open post",
+			act: func(t *testing.T, viewer, _ *models.User, otherPost *models.Post) bool {
+				_, err := GetPostByID(otherPost.ID, viewer.ID)
+				return err == nil
+			},
+		},
+		{
+			name: "see post in feed",
+			act: func(t *testing.T, viewer, _ *models.User, otherPost *models.Post) bool {
+				return feedHas(t, viewer.ID, otherPost.ID)
+			},
+		},
+		{
+			name: "like post",
+			act: func(t *testing.T, viewer, _ *models.User, otherPost *models.Post) bool {
+				_, _, err := ToggleLikeWithCount(viewer.ID, otherPost.ID)
+				return err == nil
+			},
+		},
+		{
+			name: "comment on post",
+			act: func(t *testing.T, viewer, _ *models.User, otherPost *models.Post) bool {
+				_, err := CreateComment(viewer.ID, otherPost.ID, "nice")
+				return err == nil
+			},
+		},
+		{
+			name: "see comment on a third post",
+			act: func(t *testing.T, viewer, other *models.User, _ *models.Post) bool {
+				third := createTestUser(t, "third")
+				post := createTestPost(t, third.ID)
+				if err := database.DB.Create(&models.Comment{Content: "hi", UserID: other.ID, PostID: post.ID}).Error; err != nil {
+					t.Fatal(err)
+				}
+				comments, err := GetPostComments(post.ID, viewer.ID)
+				if err != nil {
+					t.Fatalf("GetPostComments: %v", err)
+				}
+				return len(comments) == 1
+			},
+		},
+	}
+
+	blocks := []struct {
+		name  string
+		block func(t *testing.T, viewer, other *models.User)
+	}{
+		{name: "no block", block: func(*testing.T, *models.User, *models.User) {}},
+		{name: "viewer blocked other", block: func(t *testing.T, viewer, other *models.User) {
+			if err := BlockUser(viewer.ID, other.ID); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "other blocked viewer", block: func(t *testing.T, viewer, other *models.User) {
+			if err := BlockUser(other.ID, viewer.ID); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "block lifted", block: func(t *testing.T, viewer, other *models.User) {
+			if err := BlockUser(other.ID, viewer.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := UnblockUser(other.ID, viewer.ID); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+
+	for _, action := range actions {
+		for _, b := range blocks {
+			t.Run(action.name+"/"+b.name, func(t *testing.T) {
+				newTestDB(t)
+				reloadFilterRules()
+				t.Cleanup(reloadFilterRules)
+
+				viewer := createTestUser(t, "viewer")
+				other := createTestUser(t, "other")
+				otherPost := createTestPost(t, other.ID)
+				b.block(t, viewer, other)
+
+				wantAllowed := !IsBlockedBetween(viewer.ID, other.ID)
+				if allowed := action.act(t, viewer, other, otherPost); allowed != wantAllowed {
+					t.Fatalf("allowed = %v, want %v", allowed, wantAllowed)
+				}
+			})
+		}
+	}
+}
+
+func TestMuteEnforcement(t *testing.T) {
+	tests := []struct {
+		name string
+		// reports whether the muter (viewer) still gets what the muted user did
+		act  func(t *testing.T, muter, muted *models.User, mutedPost *models.Post) bool
+		want bool
+	}{
+		{
+			name: "muted posts leave the feed",
+			act: func(t *testing.T, muter, _ *models.User, mutedPost *models.Post) bool {
+				return feedHas(t, muter.ID, mutedPost.ID)
+			},
+		},
+		{
+			name: "muted posts leave trending",
+			act: func(t *testing.T, muter, _ *models.User, mutedPost *models.Post) bool {
+				posts, err := GetTrendingPosts(muter.ID, 10)
+				if err != nil {
+					t.Fatalf("GetTrendingPosts: %v", err)
+				}
+				for _, p := range posts {
+					if p["id"] == mutedPost.ID {
+						return true
+					}
+				}
+				return false
+			},
+		},
+		{
+			name: "muted posts still open directly",
+			act: func(t *testing.T, muter, _ *models.User, mutedPost *models.Post) bool {
+				_, err := GetPostByID(mutedPost.ID, muter.ID)
+				return err == nil
+			},
+			want: true,
+		},
+		{
+			name: "muted profile still visible",
+			act: func(t *testing.T, muter, muted *models.User, _ *models.Post) bool {
+				_, err := GetPublicProfile(muter.ID, muted.Username)
+				return err == nil
+			},
+			want: true,
+		},
+		{
+			name: "muted user can still message",
+			act: func(t *testing.T, muter, muted *models.User, _ *models.Post) bool {
+				_, err := SendMessage(&recordingNotifier{}, muted.ID, muter.ID, "hello", nil)
+				return err == nil
+			},
+			want: true,
+		},
+		{
+			name: "mute is one-way",
+			act: func(t *testing.T, muter, muted *models.User, _ *models.Post) bool {
+				muterPost := createTestPost(t, muter.ID)
+				return feedHas(t, muted.ID, muterPost.ID)
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			muter := createTestUser(t, "muter")
+			muted := createTestUser(t, "muted")
+			mutedPost := createTestPost(t, muted.ID)
+
+			// Without the mute the muter sees everything
+			if !feedHas(t, muter.ID, mutedPost.ID) {
+				t.Fatal("post missing from the feed before muting")
+			}
+			if err := MuteUser(muter.ID, muted.ID); err != nil {
+				t.Fatalf("MuteUser: %v", err)
+			}
+			if err := MuteUser(muter.ID, muted.ID); err != nil {
+				t.Fatalf("muting twice: %v", err)
+			}
+
+			if got := tt.act(t, muter, muted, mutedPost); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unmute", func(t *testing.T) {
+		newTestDB(t)
+		muter := createTestUser(t, "muter")
+		muted := createTestUser(t, "muted")
+		mutedPost := createTestPost(t, muted.ID)
+
+		if err := MuteUser(muter.ID, muter.ID); err == nil {
+			t.Fatal("muting yourself should fail")
+		}
+		if err := UnmuteUser(muter.ID, muted.ID); err == nil {
+			t.Fatal("unmuting a user who isn't muted should fail")
+		}
+		if err := MuteUser(muter.ID, muted.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := UnmuteUser(muter.ID, muted.ID); err != nil {
+			t.Fatalf("UnmuteUser: %v", err)
+		}
+		if !feedHas(t, muter.ID, mutedPost.ID) {
+			t.Fatal("post should return to the feed after unmuting")
+		}
+	})
+}
+
+func TestBlockedUserIDs(t *testing.T) {
+	newTestDB(t)
+	alice := createTestUser(t, "alice")
+	bob := createTestUser(t, "bob")
+	carol := createTestUser(t, "carol")
+	dave := createTestUser(t, "dave")
+
+	if err := BlockUser(alice.ID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := BlockUser(carol.ID, alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		user *models.User
+		want []uint
+	}{
+		{name: "blocked and blocked by", user: alice, want: []uint{bob.ID, carol.ID}},
+		{name: "only blocked by", user: bob, want: []uint{alice.ID}},
+		{name: "no blocks", user: dave},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BlockedUserIDs(tt.user.ID)
+			if len(got) != len(tt.want) {
+				t.Fatalf("BlockedUserIDs(%s) = %v, want %v", tt.user.Username, got, tt.want)
+			}
+			for _, id := range tt.want {
+				if !got[id] {
+					t.Fatalf("BlockedUserIDs(%s) = %v, missing %d", tt.user.Username, got, id)
+				}
+			}
+		})
+	}
+}
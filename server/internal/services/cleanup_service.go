@@ -0,0 +1,37 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// How often expired rows are swept from the database
+const cleanupInterval = time.Hour
+
+// StartCleanupJobs runs the periodic cleanups in the background, once at
+// startup and then every cleanupInterval
+func StartCleanupJobs() {
+	jobs := []struct {
+		name string
+		run  func() error
+	}{
+		{"sessions", CleanupExpiredSessions},
+		{"password reset codes", CleanupExpiredCodes},
+		{"login history", CleanupLoginHistory},
+		{"access tokens", CleanupExpiredAccessTokens},
+	}
+
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			for _, job := range jobs {
+				if err := job.run(); err != nil {
+					log.Printf("⚠️ Cleanup of expired %s failed: %v", job.name, err)
+				}
+			}
+			<-ticker.C
+		}
+	}()
+}
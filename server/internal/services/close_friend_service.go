@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// AddCloseFriend puts friendID on userID's close friends list. Adding twice
+// is a no-op.
+func AddCloseFriend(userID, friendID uint) error {
+	if err := loadOtherUser(userID, friendID); err != nil {
+		return err
+	}
+	if IsBlockedBetween(userID, friendID) {
+		return errors.New("you can't add this user to close friends")
+	}
+
+	friend := models.CloseFriend{UserID: userID, FriendID: friendID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&friend).Error; err != nil {
+		return errors.New("failed to add close friend")
+	}
+	return nil
+}
+
+// RemoveCloseFriend takes friendID off userID's close friends list
+func RemoveCloseFriend(userID, friendID uint) error {
+	result := database.DB.Where("user_id = ? AND friend_id = ?", userID, friendID).Delete(&models.CloseFriend{})
+	if result.Error != nil {
+		return errors.New("failed to remove close friend")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user is not on your close friends list")
+	}
+	return nil
+}
+
+// ListCloseFriends returns userID's close friends, most recently added first
+func ListCloseFriends(userID uint) ([]RelatedUser, error) {
+	users := []RelatedUser{}
+	if err := database.DB.Table("close_friends").
+		Select("users.id, users.username, users.image_url, close_friends.created_at AS since").
+		Joins("JOIN users ON users.id = close_friends.friend_id AND users.deleted_at IS NULL").
+		Where("close_friends.user_id = ?", userID).
+		Order("close_friends.created_at DESC").
+		Scan(&users).Error; err != nil {
+		return nil, errors.New("failed to fetch close friends")
+	}
+	return users, nil
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrContentRejected is returned when a filter rule refuses new content.
+// The rule itself isn't revealed, so spammers can't probe the filters.
+var ErrContentRejected = errors.New("your content contains words or links that aren't allowed")
+
+// Types of content the filters run on, as recorded in logs and held reports
+const (
+	filterContentPost    = models.ReportTargetPost
+	filterContentComment = models.ReportTargetComment
+	filterContentMessage = models.ReportTargetMessage
+)
+
+// linkPattern finds links and bare domain names, capturing the host
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://)?((?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,63})(?::\d+)?(?:[/?#]\S*)?`)
+
+// compiledFilterRule is a rule with its pattern ready to match
+type compiledFilterRule struct {
+	rule models.FilterRule
+	re   *regexp.Regexp // word and regex rules
+}
+
+// Enabled rules are compiled once and reused until an admin changes them
+var (
+	filterRules       []compiledFilterRule
+	filterRulesLoaded bool
+	filterMu          sync.RWMutex
+)
+
+// activeFilterRules returns the compiled enabled rules, loading them on first use
+func activeFilterRules() []compiledFilterRule {
+	filterMu.RLock()
+	if filterRulesLoaded {
+		rules := filterRules
+		filterMu.RUnlock()
+		return rules
+	}
+	filterMu.RUnlock()
+
+	filterMu.Lock()
+	defer filterMu.Unlock()
+	if filterRulesLoaded {
+		return filterRules
+	}
+
+	var rules []models.FilterRule
+	if err := database.DB.Where("enabled = ?", true).Order("id ASC").Find(&rules).Error; err != nil {
+		// Try again on the next request rather than running without filters for good
+		log.Printf("❌ Failed to load content filter rules: %v", err)
+		return nil
+	}
+
+	compiled := make([]compiledFilterRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileFilterRule(rule)
+		if err != nil {
+			log.Printf("⚠️ Skipping filter rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	filterRules = compiled
+	filterRulesLoaded = true
+	log.Printf("✅ Loaded %d content filter rules", len(compiled))
+	return filterRules
+}
+
+// reloadFilterRules drops the compiled rules so the next check reads them again
+func reloadFilterRules() {
+	filterMu.Lock()
+	filterRules = nil
+	filterRulesLoaded = false
+	filterMu.Unlock()
+}
+
+func compileFilterRule(rule models.FilterRule) (compiledFilterRule, error) {
+	c := compiledFilterRule{rule: rule}
+
+	switch rule.Kind {
+	case models.FilterKindWord:
+		// Only anchor on word boundaries where the pattern starts or ends
+		// with a letter or digit, so "$$$" or "c++" still match
+		expr := strings.Join(strings.Fields(regexp.QuoteMeta(rule.Pattern)), `\s+`)
+		first, _ := utf8.DecodeRuneInString(rule.Pattern)
+		last, _ := utf8.DecodeLastRuneInString(rule.Pattern)
+		if isWordRune(first) {
+			expr = `\b` + expr
+		}
+		if isWordRune(last) {
+			expr += `\b`
+		}
+		re, err := regexp.Compile(`(?i)` + expr)
+		if err != nil {
+			return c, err
+		}
+		c.re = re
+
+	case models.FilterKindRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return c, err
+		}
+		c.re = re
+
+	case models.FilterKindDomain:
+		// Matched against the hosts linkPattern finds
+
+	default:
+		return c, fmt.Errorf("unknown filter kind %q", rule.Kind)
+	}
+
+	return c, nil
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// matches returns the [start, end) byte ranges of text the rule matches
+func (c *compiledFilterRule) matches(text string) [][]int {
+	if c.re != nil {
+		return c.re.FindAllStringIndex(text, -1)
+	}
+
+	var spans [][]int
+	for _, m := range linkPattern.FindAllStringSubmatchIndex(text, -1) {
+		host := strings.ToLower(text[m[2]:m[3]])
+		if host == c.rule.Pattern || strings.HasSuffix(host, "."+c.rule.Pattern) {
+			spans = append(spans, []int{m[0], m[1]})
+		}
+	}
+	return spans
+}
+
+// filterMatch is a rule that matched one of the checked fields
+type filterMatch struct {
+	rule  models.FilterRule
+	field int
+	spans [][]int
+}
+
+// matchFilterRules runs every rule against every field
+func matchFilterRules(rules []compiledFilterRule, fields []string) []filterMatch {
+	var found []filterMatch
+	for i := range rules {
+		for field, text := range fields {
+			if spans := rules[i].matches(text); len(spans) > 0 {
+				found = append(found, filterMatch{rule: rules[i].rule, field: field, spans: spans})
+			}
+		}
+	}
+	return found
+}
+
+// maskSpans replaces each span of text with one asterisk per character
+func maskSpans(text string, spans [][]int) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span[0] < last {
+			// Overlaps an earlier span that's already masked
+			if span[1] <= last {
+				continue
+			}
+			span = []int{last, span[1]}
+		}
+		b.WriteString(text[last:span[0]])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[span[0]:span[1]])))
+		last = span[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// filterContent runs the filter rules over the fields of new or edited
+// content. Masked words are replaced in place. It returns ErrContentRejected
+// when a reject rule matches, or the rule that wants the content held for
+// review. Reject wins over hold, and hold over mask.
+func filterContent(contentType string, authorID uint, fields ...*string) (*models.FilterRule, error) {
+	rules := activeFilterRules()
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		texts[i] = *field
+	}
+
+	found := matchFilterRules(rules, texts)
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	var reject, hold *models.FilterRule
+	for i := range found {
+		rule := &found[i].rule
+		log.Printf("🧹 Filter rule %d (%s %q, %s) matched %s by user %d", rule.ID, rule.Kind, rule.Pattern, rule.Action, contentType, authorID)
+		recordFilterMatch(rule.ID)
+
+		switch rule.Action {
+		case models.FilterActionReject:
+			if reject == nil {
+				reject = rule
+			}
+		case models.FilterActionHold:
+			if hold == nil {
+				hold = rule
+			}
+		}
+	}
+
+	if reject != nil {
+		return nil, ErrContentRejected
+	}
+
+	// Masks are applied even when the content is held
+	for field := range fields {
+		var spans [][]int
+		for _, m := range found {
+			if m.field == field && m.rule.Action == models.FilterActionMask {
+				spans = append(spans, m.spans...)
+			}
+		}
+		if len(spans) > 0 {
+			sortSpans(spans)
+			*fields[field] = maskSpans(*fields[field], spans)
+		}
+	}
+
+	return hold, nil
+}
+
+// sortSpans orders spans by where they start, as maskSpans expects
+func sortSpans(spans [][]int) {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+}
+
+// recordFilterMatch bumps a rule's match counter for the admin list
+func recordFilterMatch(ruleID uint) {
+	database.DB.Model(&models.FilterRule{}).Where("id = ?", ruleID).Updates(map[string]interface{}{
+		"match_count":     gorm.Expr("match_count + 1"),
+		"last_matched_at": time.Now(),
+	})
+}
+
+// BackfillHeldPosts moves filter holds on posts from hidden_at to held_at,
+// where they live since moderator hides got their own column. A post is
+// taken as held when a filter report about it is still open. Run once, when
+// the column is first added.
+func BackfillHeldPosts() error {
+	return database.DB.Exec(`
+		UPDATE posts SET held_at = hidden_at, hidden_at = NULL
+		WHERE hidden_at IS NOT NULL AND id IN (
+			SELECT target_id FROM reports
+			WHERE reporter_id = 0 AND target_type = ? AND status = ?
+		)`, models.ReportTargetPost, models.ReportOpen).Error
+}
+
+// BackfillHeldComments moves filter holds on comments from hidden_at to
+// held_at. Until moderator hides got their own column only content filters
+// set hidden_at on comments, so every hidden comment is a hold. Run once,
+// when the column is first added.
+func BackfillHeldComments() error {
+	return database.DB.Exec(`
+		UPDATE comments SET held_at = hidden_at, hidden_at = NULL
+		WHERE hidden_at IS NOT NULL`).Error
+}
+
+// holdForReview puts held content in the moderation queue as a report
+// without a reporter. Content held again after an edit reopens its report.
+func holdForReview(contentType string, contentID, authorID uint, rule *models.FilterRule) {
+	details := truncate(fmt.Sprintf("Held by filter rule %d (%s %q)", rule.ID, rule.Kind, rule.Pattern), 1000)
+
+	var report models.Report
+	err := database.DB.Where("reporter_id = ? AND target_type = ? AND target_id = ?", 0, contentType, contentID).
+		First(&report).Error
+	if err == nil {
+		err = database.DB.Model(&report).Updates(map[string]interface{}{
+			"status":          models.ReportOpen,
+			"details":         details,
+			"assignee_id":     nil,
+			"resolution":      "",
+			"resolution_note": "",
+			"resolved_by_id":  nil,
+			"resolved_at":     nil,
+		}).Error
+	} else {
+		report = models.Report{
+			TargetType:   contentType,
+			TargetID:     contentID,
+			TargetUserID: authorID,
+			Reason:       models.ReportReasonFilter,
+			Details:      details,
+			Status:       models.ReportOpen,
+		}
+		err = database.DB.Create(&report).Error
+	}
+
+	if err != nil {
+		log.Printf("❌ Failed to queue held %s %d for review: %v", contentType, contentID, err)
+		return
+	}
+	log.Printf("🧹 Held %s %d by user %d for review (report %d)", contentType, contentID, authorID, report.ID)
+}
+
+// FilterRuleRequest creates or updates a filter rule. Fields left nil keep
+// their current value on update.
+type FilterRuleRequest struct {
+	Kind    *string `json:"kind"`
+	Pattern *string `json:"pattern"`
+	Action  *string `json:"action"`
+	Note    *string `json:"note"`
+	Enabled *bool   `json:"enabled"`
+}
+
+// ListFilterRules returns every filter rule, newest first
+func ListFilterRules() ([]models.FilterRule, error) {
+	var rules []models.FilterRule
+	if err := database.DB.Order("created_at DESC").Find(&rules).Error; err != nil {
+		return nil, errors.New("failed to fetch filter rules")
+	}
+	return rules, nil
+}
+
+// CreateFilterRule adds a rule; it applies to content saved from now on
+func CreateFilterRule(actorID uint, req FilterRuleRequest) (*models.FilterRule, error) {
+	if req.Kind == nil || req.Pattern == nil || req.Action == nil {
+		return nil, errors.New("kind, pattern and action are required")
+	}
+
+	rule := models.FilterRule{CreatedByID: actorID, Enabled: true}
+	if err := applyFilterRuleRequest(&rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Create(&rule).Error; err != nil {
+		return nil, errors.New("failed to create filter rule")
+	}
+	reloadFilterRules()
+
+	log.Printf("🛡️ Admin %d added filter rule %d (%s %q, %s)", actorID, rule.ID, rule.Kind, rule.Pattern, rule.Action)
+	return &rule, nil
+}
+
+// UpdateFilterRule changes a rule's pattern, action or whether it's enabled
+func UpdateFilterRule(actorID, ruleID uint, req FilterRuleRequest) (*models.FilterRule, error) {
+	var rule models.FilterRule
+	if err := database.DB.First(&rule, ruleID).Error; err != nil {
+		return nil, errors.New("filter rule not found")
+	}
+
+	if err := applyFilterRuleRequest(&rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Save(&rule).Error; err != nil {
+		return nil, errors.New("failed to update filter rule")
+	}
+	reloadFilterRules()
+
+	log.Printf("🛡️ Admin %d updated filter rule %d (%s %q, %s, enabled=%t)", actorID, rule.ID, rule.Kind, rule.Pattern, rule.Action, rule.Enabled)
+	return &rule, nil
+}
+
+// DeleteFilterRule removes a rule
+func DeleteFilterRule(actorID, ruleID uint) error {
+	result := database.DB.Delete(&models.FilterRule{}, ruleID)
+	if result.Error != nil {
+		return errors.New("failed to delete filter rule")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("filter rule not found")
+	}
+	reloadFilterRules()
+
+	log.Printf("🛡️ Admin %d deleted filter rule %d", actorID, ruleID)
+	return nil
+}
+
+// applyFilterRuleRequest validates the request and copies it onto rule
+func applyFilterRuleRequest(rule *models.FilterRule, req FilterRuleRequest) error {
+	if req.Kind != nil {
+		rule.Kind = strings.TrimSpace(*req.Kind)
+	}
+	if req.Action != nil {
+		rule.Action = strings.TrimSpace(*req.Action)
+	}
+	if req.Pattern != nil {
+		rule.Pattern = strings.TrimSpace(*req.Pattern)
+	}
+	if req.Note != nil {
+		rule.Note = truncate(strings.TrimSpace(*req.Note), 255)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	if !models.IsValidFilterKind(rule.Kind) {
+		return errors.New("kind must be word, regex or domain")
+	}
+	if !models.IsValidFilterAction(rule.Action) {
+		return errors.New("action must be reject, hold or mask")
+	}
+	if rule.Pattern == "" {
+		return errors.New("pattern is required")
+	}
+	if len(rule.Pattern) > 255 {
+		return errors.New("pattern must not exceed 255 characters")
+	}
+
+	if rule.Kind == models.FilterKindDomain {
+		domain, err := normalizeFilterDomain(rule.Pattern)
+		if err != nil {
+			return err
+		}
+		rule.Pattern = domain
+	}
+
+	if _, err := compileFilterRule(*rule); err != nil {
+		return fmt.Errorf("invalid pattern: %v", err)
+	}
+	return nil
+}
+
+// normalizeFilterDomain accepts "example.com", "www.example.com" or a full
+// URL and returns the lower-case host to block
+func normalizeFilterDomain(pattern string) (string, error) {
+	m := linkPattern.FindStringSubmatch(pattern)
+	if m == nil || m[0] != pattern {
+		return "", errors.New("pattern must be a domain name like example.com")
+	}
+	return strings.TrimPrefix(strings.ToLower(m[1]), "www."), nil
+}
+
+// FilterTestMatch is a rule that matched the sample text
+type FilterTestMatch struct {
+	RuleID  uint     `json:"rule_id"`
+	Kind    string   `json:"kind"`
+	Pattern string   `json:"pattern"`
+	Action  string   `json:"action"`
+	Matched []string `json:"matched"`
+}
+
+// TestContentFilter shows which enabled rules match text and what would be
+// saved, without recording matches
+func TestContentFilter(text string) (string, []FilterTestMatch) {
+	found := matchFilterRules(activeFilterRules(), []string{text})
+
+	matches := make([]FilterTestMatch, 0, len(found))
+	var masks [][]int
+	for _, m := range found {
+		match := FilterTestMatch{RuleID: m.rule.ID, Kind: m.rule.Kind, Pattern: m.rule.Pattern, Action: m.rule.Action}
+		for _, span := range m.spans {
+			match.Matched = append(match.Matched, text[span[0]:span[1]])
+		}
+		matches = append(matches, match)
+
+		if m.rule.Action == models.FilterActionMask {
+			masks = append(masks, m.spans...)
+		}
+	}
+
+	sortSpans(masks)
+	return maskSpans(text, masks), matches
+}
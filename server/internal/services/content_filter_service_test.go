@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestCompileWordRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		want    bool
+	}{
+		{name: "whole word", pattern: "spam", text: "this is spam", want: true},
+		{name: "case-insensitive", pattern: "spam", text: "SPAM!", want: true},
+		{name: "inside another word", pattern: "spam", text: "spammer", want: false},
+		{name: "phrase across extra spaces", pattern: "buy now", text: "buy   now please", want: true},
+		{name: "phrase split by a word", pattern: "buy now", text: "buy it now", want: false},
+		{name: "symbols at both ends", pattern: "$$$", text: "win$$$fast", want: true},
+		{name: "symbol at the end only", pattern: "c++", text: "I like c++ and go", want: true},
+		{name: "symbol at the end, word start anchored", pattern: "c++", text: "abc++", want: false},
+		{name: "regex characters are literal", pattern: "a.b", text: "axb", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := compileFilterRule(models.FilterRule{Kind: models.FilterKindWord, Pattern: tt.pattern})
+			if err != nil {
+				t.Fatalf("compile %q: %v", tt.pattern, err)
+			}
+			if got := len(c.matches(tt.text)) > 0; got != tt.want {
+				t.Fatalf("%q matching %q = %v, want %v", tt.pattern, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDomainRuleMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "bare domain", text: "visit spam.com today", want: []string{"spam.com"}},
+		{name: "link with path", text: "see https://spam.com/offer?id=1 now", want: []string{"https://spam.com/offer?id=1"}},
+		{name: "subdomain", text: "go to www.spam.com", want: []string{"www.spam.com"}},
+		{name: "uppercase host", text: "SPAM.COM", want: []string{"SPAM.COM"}},
+		{name: "different domain with the same suffix", text: "notspam.com", want: nil},
+		{name: "domain as a prefix of another", text: "spam.com.example.org", want: nil},
+		{name: "no links", text: "nothing to see", want: nil},
+	}
+
+	c, err := compileFilterRule(models.FilterRule{Kind: models.FilterKindDomain, Pattern: "spam.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, span := range c.matches(tt.text) {
+				got = append(got, tt.text[span[0]:span[1]])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("matches = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("matches = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMaskSpans(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		spans [][]int
+		want  string
+	}{
+		{name: "no spans", text: "hello", spans: nil, want: "hello"},
+		{name: "one span", text: "bad word", spans: [][]int{{0, 3}}, want: "*** word"},
+		{name: "two spans", text: "bad and bad", spans: [][]int{{0, 3}, {8, 11}}, want: "*** and ***"},
+		{name: "overlapping spans", text: "abcdef", spans: [][]int{{0, 3}, {2, 5}}, want: "*****f"},
+		{name: "span inside another", text: "abcdef", spans: [][]int{{0, 5}, {1, 3}}, want: "*****f"},
+		{name: "adjacent spans", text: "abcdef", spans: [][]int{{0, 2}, {2, 4}}, want: "****ef"},
+		{name: "multibyte runes count once", text: "héllo there", spans: [][]int{{0, 6}}, want: "***** there"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskSpans(tt.text, tt.spans); got != tt.want {
+				t.Fatalf("maskSpans(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterContentPrecedence(t *testing.T) {
+	tests := []struct {
+		name        string
+		actions     []string // one rule per action, all matching "bad"
+		wantErr     error
+		wantHold    bool
+		wantContent string
+	}{
+		{name: "mask only", actions: []string{models.FilterActionMask}, wantContent: "a *** post"},
+		{name: "hold only", actions: []string{models.FilterActionHold}, wantHold: true, wantContent: "a bad post"},
+		{name: "hold and mask", actions: []string{models.FilterActionMask, models.FilterActionHold}, wantHold: true, wantContent: "a *** post"},
+		{name: "reject and hold", actions: []string{models.FilterActionHold, models.FilterActionReject}, wantErr: ErrContentRejected},
+		{name: "reject, hold and mask", actions: []string{models.FilterActionMask, models.FilterActionHold, models.FilterActionReject}, wantErr: ErrContentRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			reloadFilterRules()
+			t.Cleanup(reloadFilterRules)
+
+			for _, action := range tt.actions {
+				rule := models.FilterRule{Kind: models.FilterKindWord, Pattern: "bad", Action: action, Enabled: true, CreatedByID: 1}
+				if err := database.DB.Create(&rule).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			content := "a bad post"
+			held, err := filterContent(filterContentPost, 1, &content)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("filterContent() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if (held != nil) != tt.wantHold {
+				t.Fatalf("held = %v, want hold %v", held, tt.wantHold)
+			}
+			if content != tt.wantContent {
+				t.Fatalf("content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestBackfillHeldPosts(t *testing.T) {
+	newTestDB(t)
+	author := createTestUser(t, "author")
+
+	now := time.Now()
+	held := models.Post{Title: "held", Content: "x", UserID: author.ID, Visibility: models.PostPublic, HiddenAt: &now}
+	hidden := models.Post{Title: "hidden", Content: "x", UserID: author.ID, Visibility: models.PostPublic, HiddenAt: &now}
+	database.DB.Create(&held)
+	database.DB.Create(&hidden)
+	database.DB.Create(&models.Report{TargetType: models.ReportTargetPost, TargetID: held.ID, TargetUserID: author.ID, Reason: models.ReportReasonFilter, Status: models.ReportOpen})
+
+	if err := BackfillHeldPosts(); err != nil {
+		t.Fatalf("BackfillHeldPosts: %v", err)
+	}
+
+	var stored models.Post
+	database.DB.First(&stored, held.ID)
+	if stored.HeldAt == nil || stored.HiddenAt != nil {
+		t.Fatalf("filter hold not moved: held_at %v, hidden_at %v", stored.HeldAt, stored.HiddenAt)
+	}
+	stored = models.Post{}
+	database.DB.First(&stored, hidden.ID)
+	if stored.HeldAt != nil || stored.HiddenAt == nil {
+		t.Fatalf("moderator hide was moved: held_at %v, hidden_at %v", stored.HeldAt, stored.HiddenAt)
+	}
+}
+
+func TestBackfillHeldComments(t *testing.T) {
+	newTestDB(t)
+	author := createTestUser(t, "author")
+
+	post := models.Post{Title: "title", Content: "x", UserID: author.ID, Visibility: models.PostPublic}
+	database.DB.Create(&post)
+	now := time.Now()
+	held := models.Comment{Content: "held", UserID: author.ID, PostID: post.ID, HiddenAt: &now}
+	visible := models.Comment{Content: "visible", UserID: author.ID, PostID: post.ID}
+	database.DB.Create(&held)
+	database.DB.Create(&visible)
+
+	if err := BackfillHeldComments(); err != nil {
+		t.Fatalf("BackfillHeldComments: %v", err)
+	}
+
+	var stored models.Comment
+	database.DB.First(&stored, held.ID)
+	if stored.HeldAt == nil || stored.HiddenAt != nil {
+		t.Fatalf("filter hold not moved: held_at %v, hidden_at %v", stored.HeldAt, stored.HiddenAt)
+	}
+	stored = models.Comment{}
+	database.DB.First(&stored, visible.ID)
+	if stored.HeldAt != nil || stored.HiddenAt != nil {
+		t.Fatalf("visible comment changed: held_at %v, hidden_at %v", stored.HeldAt, stored.HiddenAt)
+	}
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB points database.DB at a fresh in-memory SQLite database with the
+// app's models migrated, and puts the previous one back when the test ends
+func newTestDB(t *testing.T) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	// Every connection to ":memory:" is a separate database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Post{},
+		&models.Message{},
+		&models.Like{},
+		&models.Comment{},
+		&models.PasswordReset{},
+		&models.Session{},
+		&models.RecoveryCode{},
+		&models.EmailVerification{},
+		&models.LoginAttempt{},
+		&models.UserIdentity{},
+		&models.WebAuthnCredential{},
+		&models.PersonalAccessToken{},
+		&models.Report{},
+		&models.FilterRule{},
+		&models.AuditEvent{},
+		&models.UserBlock{},
+		&models.UserMute{},
+		&models.Follow{},
+		&models.CloseFriend{},
+	); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+		sqlDB.Close()
+	})
+}
+
+// createTestUser stores a verified local account
+func createTestUser(t *testing.T, username string) *models.User {
+	t.Helper()
+
+	user := models.User{
+		Username:      username,
+		Email:         username + "@example.com",
+		Password:      "not-a-real-hash",
+		EmailVerified: true,
+		HasPassword:   true,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create user %s: %v", username, err)
+	}
+	return &user
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	VerificationExpirationHours = 24
+	MaxVerificationAttempts     = 5
+
+	// Verification emails resent per address and per client IP within VerificationResendWindow
+	VerificationResendsPerEmail = 3
+	VerificationResendsPerIP    = 10
+	VerificationResendWindow    = time.Hour
+)
+
+var (
+	resendEmailThrottle = newRequestThrottle(VerificationResendsPerEmail, VerificationResendWindow)
+	resendIPThrottle    = newRequestThrottle(VerificationResendsPerIP, VerificationResendWindow)
+)
+
+// RegisterLocalUser creates a password account that must verify its email
+// before logging in, and sends the first verification code
+func RegisterLocalUser(user *models.User) error {
+	user.Provider = "local"
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		// email_verified defaults to true in the database, see models.User
+		return tx.Model(user).Update("email_verified", false).Error
+	})
+	if err != nil {
+		return errors.New("could not create user")
+	}
+
+	if err := SendEmailVerification(*user, user.Email, models.VerificationSignup); err != nil {
+		// The account exists; the user can ask for a new code
+		log.Printf("⚠️ Warning: Could not send verification email to %s: %v", user.Email, err)
+	}
+
+	return nil
+}
+
+// SendEmailVerification invalidates earlier codes for the same purpose and mails a new one
+func SendEmailVerification(user models.User, email, purpose string) error {
+	database.DB.Model(&models.EmailVerification{}).
+		Where("user_id = ? AND purpose = ? AND used = false", user.ID, purpose).
+		Update("used", true)
+
+	code, err := GenerateOTP()
+	if err != nil {
+		return fmt.Errorf("failed to generate OTP: %w", err)
+	}
+
+	verification := models.EmailVerification{
+		UserID:    user.ID,
+		Email:     email,
+		Code:      code,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(VerificationExpirationHours * time.Hour),
+	}
+
+	if err := database.DB.Create(&verification).Error; err != nil {
+		return fmt.Errorf("failed to create verification record: %w", err)
+	}
+
+	if err := utils.SendVerificationEmail(email, user.Username, code); err != nil {
+		database.DB.Model(&verification).Update("used", true)
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
+
+// ResendSignupVerification sends a new code to an unverified account.
+// Unknown or already verified addresses are ignored to prevent enumeration.
+// Returns ErrTooManyRequests when the email or IP is over its request limit.
+func ResendSignupVerification(email, ip string) error {
+	// Throttle before the user lookup so the limit doesn't reveal whether the email exists
+	if !resendIPThrottle.Allow(ip) {
+		log.Printf("⚠️ Verification resend throttled for IP: %s", ip)
+		return ErrTooManyRequests
+	}
+	if !resendEmailThrottle.Allow(strings.ToLower(email)) {
+		log.Printf("⚠️ Verification resend throttled for email: %s", email)
+		return ErrTooManyRequests
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if user.EmailVerified {
+		return nil
+	}
+
+	return SendEmailVerification(user, user.Email, models.VerificationSignup)
+}
+
+// VerifySignupEmail marks a new account as verified
+func VerifySignupEmail(email, code string) (*models.User, error) {
+	var user models.User
+	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, errors.New("invalid or expired code")
+	}
+
+	// Same answer as a wrong code, so this doesn't reveal which accounts exist
+	if user.EmailVerified {
+		return nil, errors.New("invalid or expired code")
+	}
+
+	verification, err := consumeVerificationCode(user.ID, models.VerificationSignup, code)
+	if err != nil {
+		return nil, err
+	}
+	if verification.Email != user.Email {
+		return nil, errors.New("invalid or expired code")
+	}
+
+	if err := database.DB.Model(&user).Update("email_verified", true).Error; err != nil {
+		return nil, errors.New("failed to verify email")
+	}
+
+	log.Printf("✅ Email verified for user %d", user.ID)
+	return &user, nil
+}
+
+// RequestEmailChange stores newEmail as pending and sends a code to it.
+// The current email keeps working (and receives password resets) until confirmed.
+func RequestEmailChange(user *models.User, newEmail string) error {
+	if _, err := mail.ParseAddress(newEmail); err != nil {
+		return errors.New("invalid email format")
+	}
+
+	if err := database.DB.Model(user).Update("pending_email", newEmail).Error; err != nil {
+		return errors.New("failed to save pending email")
+	}
+	user.PendingEmail = newEmail
+
+	if err := SendEmailVerification(*user, newEmail, models.VerificationEmailChange); err != nil {
+		log.Printf("❌ Failed to send email change verification: %v", err)
+		return errors.New("failed to send verification email")
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange applies the pending email once the code sent to it is confirmed
+func ConfirmEmailChange(userID uint, code string) (*models.User, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.PendingEmail == "" {
+		return nil, errors.New("no email change pending")
+	}
+
+	verification, err := consumeVerificationCode(user.ID, models.VerificationEmailChange, code)
+	if err != nil {
+		return nil, err
+	}
+	if verification.Email != user.PendingEmail {
+		return nil, errors.New("invalid or expired code")
+	}
+
+	// The address may have been taken while the change was pending
+	var existing models.User
+	if err := database.DB.Where("email = ? AND id != ?", user.PendingEmail, user.ID).First(&existing).Error; err == nil {
+		return nil, errors.New("email already exists")
+	}
+
+	oldEmail := user.Email
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.EmailVerified = true
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"email":          user.Email,
+		"pending_email":  "",
+		"email_verified": true,
+	}).Error; err != nil {
+		return nil, errors.New("failed to update email")
+	}
+
+	log.Printf("✅ Email changed for user %d: %s -> %s", user.ID, oldEmail, user.Email)
+	user.Password = ""
+	return &user, nil
+}
+
+// CancelEmailChange drops a pending email change
+func CancelEmailChange(userID uint) error {
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).
+		Update("pending_email", "").Error; err != nil {
+		return errors.New("failed to cancel email change")
+	}
+
+	database.DB.Model(&models.EmailVerification{}).
+		Where("user_id = ? AND purpose = ? AND used = false", userID, models.VerificationEmailChange).
+		Update("used", true)
+
+	return nil
+}
+
+// consumeVerificationCode checks code against the latest active verification,
+// counting failed attempts and locking the code after MaxVerificationAttempts
+func consumeVerificationCode(userID uint, purpose, code string) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	if err := database.DB.
+		Where("user_id = ? AND purpose = ? AND used = false", userID, purpose).
+		Order("created_at DESC").
+		First(&verification).Error; err != nil {
+		return nil, errors.New("invalid or expired code")
+	}
+
+	if verification.IsExpired() {
+		return nil, errors.New("code has expired")
+	}
+
+	if verification.Attempts >= MaxVerificationAttempts {
+		return nil, errors.New("too many attempts, request a new code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(verification.Code), []byte(code)) != 1 {
+		database.DB.Model(&verification).UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+		return nil, errors.New("invalid or expired code")
+	}
+
+	result := database.DB.Model(&models.EmailVerification{}).
+		Where("id = ? AND used = false", verification.ID).
+		Update("used", true)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired code")
+	}
+
+	return &verification, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/middleware"
+)
+
+// How long a client has to open the event stream with a ticket
+const eventTicketTTL = 30 * time.Second
+
+// EventSource can't send an Authorization header, so /api/events takes a
+// one-time ticket in the query string instead. Tickets are issued to an
+// authenticated request and only open a stream; leaking one from a log is
+// worth at most one connection within eventTicketTTL.
+var (
+	eventTicketMu sync.Mutex
+	eventTickets  = make(map[string]eventTicket)
+)
+
+// The credential the ticket was issued to is checked again on redemption, so
+// a suspension or forced logout in between still keeps the stream closed
+type eventTicket struct {
+	userID        uint
+	sessionID     uint
+	accessTokenID uint
+	expiresAt     time.Time
+}
+
+// IssueEventTicket returns a ticket that opens one event stream for userID.
+// sessionID or accessTokenID names the credential the request was made with.
+func IssueEventTicket(userID, sessionID, accessTokenID uint) (string, error) {
+	ticket, err := randomURLToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	eventTicketMu.Lock()
+	defer eventTicketMu.Unlock()
+
+	now := time.Now()
+	for k, t := range eventTickets {
+		if now.After(t.expiresAt) {
+			delete(eventTickets, k)
+		}
+	}
+	eventTickets[ticket] = eventTicket{
+		userID:        userID,
+		sessionID:     sessionID,
+		accessTokenID: accessTokenID,
+		expiresAt:     now.Add(eventTicketTTL),
+	}
+
+	return ticket, nil
+}
+
+// RedeemEventTicket returns the user a ticket was issued to. Each ticket works
+// once, and only while the credential it was issued to is still valid.
+func RedeemEventTicket(ticket string) (uint, error) {
+	eventTicketMu.Lock()
+	t, ok := eventTickets[ticket]
+	delete(eventTickets, ticket)
+	eventTicketMu.Unlock()
+
+	if !ok || time.Now().After(t.expiresAt) {
+		return 0, errors.New("event ticket is invalid or has expired")
+	}
+	if err := middleware.CheckCredential(t.userID, t.sessionID, t.accessTokenID); err != nil {
+		return 0, err
+	}
+	return t.userID, nil
+}
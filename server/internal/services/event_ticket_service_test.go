@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+// createTestSession stores an active login session for userID
+func createTestSession(t *testing.T, userID uint) *models.Session {
+	t.Helper()
+
+	session := models.Session{
+		UserID:           userID,
+		RefreshTokenHash: hashToken(time.Now().String()),
+		LastUsedAt:       time.Now(),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return &session
+}
+
+func TestEventTicket(t *testing.T) {
+	tests := []struct {
+		name     string
+		useToken bool // issue to a personal access token instead of a session
+		expire   bool
+		before   func(t *testing.T, userID uint) // runs between issuing and redeeming
+		redeems  int
+		wantErr  []bool
+	}{
+		{name: "single use", redeems: 2, wantErr: []bool{false, true}},
+		{name: "access token", useToken: true, redeems: 1, wantErr: []bool{false}},
+		{name: "expired", expire: true, redeems: 1, wantErr: []bool{true}},
+		{name: "session revoked", redeems: 1, wantErr: []bool{true}, before: func(t *testing.T, userID uint) {
+			if err := RevokeAllSessions(userID, 0); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "access token revoked", useToken: true, redeems: 1, wantErr: []bool{true}, before: func(t *testing.T, userID uint) {
+			if err := RevokeAllAccessTokens(userID); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{name: "account suspended", redeems: 1, wantErr: []bool{true}, before: func(t *testing.T, userID uint) {
+			database.DB.Model(&models.User{}).Where("id = ?", userID).Update("suspended_until", time.Now().Add(time.Hour))
+		}},
+		{name: "account banned", useToken: true, redeems: 1, wantErr: []bool{true}, before: func(t *testing.T, userID uint) {
+			database.DB.Model(&models.User{}).Where("id = ?", userID).Update("banned_at", time.Now())
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			user := createTestUser(t, "alice")
+
+			var sessionID, accessTokenID uint
+			if tt.useToken {
+				_, pat, err := CreateAccessToken(user.ID, "bot", []string{models.ScopeMessagesRead}, 30)
+				if err != nil {
+					t.Fatal(err)
+				}
+				accessTokenID = pat.ID
+			} else {
+				sessionID = createTestSession(t, user.ID).ID
+			}
+
+			ticket, err := IssueEventTicket(user.ID, sessionID, accessTokenID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.expire {
+				eventTicketMu.Lock()
+				issued := eventTickets[ticket]
+				issued.expiresAt = time.Now().Add(-time.Second)
+				eventTickets[ticket] = issued
+				eventTicketMu.Unlock()
+			}
+			if tt.before != nil {
+				tt.before(t, user.ID)
+			}
+
+			for i := 0; i < tt.redeems; i++ {
+				userID, err := RedeemEventTicket(ticket)
+				if (err != nil) != tt.wantErr[i] {
+					t.Fatalf("redeem %d: error = %v, wantErr %v", i+1, err, tt.wantErr[i])
+				}
+				if err == nil && userID != user.ID {
+					t.Fatalf("redeem %d: user = %d, want %d", i+1, userID, user.ID)
+				}
+			}
+		})
+	}
+
+	if _, err := RedeemEventTicket("made-up"); err == nil {
+		t.Fatal("an unknown ticket was accepted")
+	}
+}
@@ -19,6 +19,7 @@ type FeedPost struct {
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
 	UserID        uint                   `json:"user_id"`
+	Visibility    string                 `json:"visibility"`
 	User          map[string]interface{} `json:"user"`
 	LikesCount    int64                  `json:"likes_count"`
 	CommentsCount int64                  `json:"comments_count"`
@@ -38,6 +39,7 @@ func GetSmartFeed(currentUserID uint, page, pageSize int) ([]map[string]interfac
 
 	// FETCH ALL POSTS - No date filter, no limit!
 	if err := database.DB.
+		Scopes(feedPosts(currentUserID)).
 		Preload("User").
 		Order("created_at DESC"). // Get newest first for faster scoring
 		Find(&posts).Error; err != nil {
@@ -59,7 +61,7 @@ func GetSmartFeed(currentUserID uint, page, pageSize int) ([]map[string]interfac
 
 	for _, post := range posts {
 		likesCount, _ := GetLikesCount(post.ID)
-		commentsCount, _ := GetCommentsCount(post.ID)
+		commentsCount, _ := GetCommentsCount(post.ID, currentUserID)
 		isLiked, _ := IsPostLikedByUser(currentUserID, post.ID)
 
 		score := calculatePostScore(
@@ -72,13 +74,14 @@ func GetSmartFeed(currentUserID uint, page, pageSize int) ([]map[string]interfac
 		post.User.Password = ""
 
 		feedPost := FeedPost{
-			ID:        post.ID,
-			Title:     post.Title,
-			Content:   post.Content,
-			ImageURL:  post.ImageURL.String,
-			CreatedAt: post.CreatedAt,
-			UpdatedAt: post.UpdatedAt,
-			UserID:    post.UserID,
+			ID:         post.ID,
+			Title:      post.Title,
+			Content:    post.Content,
+			ImageURL:   post.ImageURL.String,
+			CreatedAt:  post.CreatedAt,
+			UpdatedAt:  post.UpdatedAt,
+			UserID:     post.UserID,
+			Visibility: post.Visibility,
 			User: map[string]interface{}{
 				"id":        post.User.ID,
 				"username":  post.User.Username,
@@ -130,6 +133,7 @@ func GetSmartFeed(currentUserID uint, page, pageSize int) ([]map[string]interfac
 			"created_at":     post.CreatedAt,
 			"updated_at":     post.UpdatedAt,
 			"user_id":        post.UserID,
+			"visibility":     post.Visibility,
 			"user":           post.User,
 			"likes_count":    post.LikesCount,
 			"comments_count": post.CommentsCount,
@@ -146,6 +150,7 @@ func GetTrendingPosts(currentUserID uint, limit int) ([]map[string]interface{},
 
 	var posts []models.Post
 	if err := database.DB.
+		Scopes(feedPosts(currentUserID)).
 		Preload("User").
 		Where("created_at > ?", twoDaysAgo).
 		Order("created_at DESC").
@@ -163,7 +168,7 @@ func GetTrendingPosts(currentUserID uint, limit int) ([]map[string]interface{},
 
 	for _, post := range posts {
 		likesCount, _ := GetLikesCount(post.ID)
-		commentsCount, _ := GetCommentsCount(post.ID)
+		commentsCount, _ := GetCommentsCount(post.ID, currentUserID)
 		isLiked, _ := IsPostLikedByUser(currentUserID, post.ID)
 
 		// Engagement-only score for trending
@@ -172,13 +177,14 @@ func GetTrendingPosts(currentUserID uint, limit int) ([]map[string]interface{},
 		post.User.Password = ""
 
 		feedPost := FeedPost{
-			ID:        post.ID,
-			Title:     post.Title,
-			Content:   post.Content,
-			ImageURL:  post.ImageURL.String,
-			CreatedAt: post.CreatedAt,
-			UpdatedAt: post.UpdatedAt,
-			UserID:    post.UserID,
+			ID:         post.ID,
+			Title:      post.Title,
+			Content:    post.Content,
+			ImageURL:   post.ImageURL.String,
+			CreatedAt:  post.CreatedAt,
+			UpdatedAt:  post.UpdatedAt,
+			UserID:     post.UserID,
+			Visibility: post.Visibility,
 			User: map[string]interface{}{
 				"id":        post.User.ID,
 				"username":  post.User.Username,
@@ -215,6 +221,7 @@ func GetTrendingPosts(currentUserID uint, limit int) ([]map[string]interface{},
 			"created_at":     post.CreatedAt,
 			"updated_at":     post.UpdatedAt,
 			"user_id":        post.UserID,
+			"visibility":     post.Visibility,
 			"user":           post.User,
 			"likes_count":    post.LikesCount,
 			"comments_count": post.CommentsCount,
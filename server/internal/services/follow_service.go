@@ -0,0 +1,212 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrPrivateAccount is returned when the viewer doesn't follow a private account
+var ErrPrivateAccount = errors.New("this account is private")
+
+// ErrFollowRequestNotFound is returned when there's no pending request to act on
+var ErrFollowRequestNotFound = errors.New("follow request not found")
+
+// CanViewContent reports whether viewerID (0 when anonymous) may see ownerID's
+// posts and connections: the owner themselves, anyone for a public account,
+// and approved followers for a private one. Blocks hide everything.
+func CanViewContent(viewerID, ownerID uint) bool {
+	if viewerID != 0 && viewerID == ownerID {
+		return true
+	}
+
+	var owner models.User
+	if err := database.DB.Select("id, is_private").First(&owner, ownerID).Error; err != nil {
+		return false
+	}
+	if viewerID != 0 && IsBlockedBetween(viewerID, ownerID) {
+		return false
+	}
+	return !owner.IsPrivate || IsFollowing(viewerID, ownerID)
+}
+
+// IsFollowing reports whether followerID is an approved follower of followingID
+func IsFollowing(followerID, followingID uint) bool {
+	if followerID == 0 {
+		return false
+	}
+
+	var count int64
+	database.DB.Model(&models.Follow{}).
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, followingID, models.FollowAccepted).
+		Count(&count)
+	return count > 0
+}
+
+// FollowUser follows targetID, or asks to when the account is private. Following
+// again returns the existing follow or request.
+func FollowUser(notifier MessageNotifier, followerID, targetID uint) (*models.Follow, error) {
+	if followerID == targetID {
+		return nil, errors.New("you can't follow yourself")
+	}
+
+	var target models.User
+	if err := database.DB.Select("id, is_private").First(&target, targetID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if IsBlockedBetween(followerID, targetID) {
+		return nil, errors.New("you can't follow this user")
+	}
+
+	var follow models.Follow
+	err := database.DB.Where("follower_id = ? AND following_id = ?", followerID, targetID).First(&follow).Error
+	if err == nil {
+		return &follow, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to check follow status")
+	}
+
+	follow = models.Follow{FollowerID: followerID, FollowingID: targetID, Status: models.FollowAccepted}
+	if target.IsPrivate {
+		follow.Status = models.FollowPending
+	}
+	if err := database.DB.Create(&follow).Error; err != nil {
+		return nil, errors.New("failed to follow user")
+	}
+
+	event := "new_follower"
+	if !follow.IsAccepted() {
+		event = "follow_request"
+	}
+	sendEvent(notifier, targetID, map[string]interface{}{
+		"type":    event,
+		"user_id": followerID,
+	})
+
+	log.Printf("✅ User %d followed user %d (%s)", followerID, targetID, follow.Status)
+	return &follow, nil
+}
+
+// UnfollowUser stops following targetID, or withdraws a pending request
+func UnfollowUser(followerID, targetID uint) error {
+	result := database.DB.Where("follower_id = ? AND following_id = ?", followerID, targetID).Delete(&models.Follow{})
+	if result.Error != nil {
+		return errors.New("failed to unfollow user")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("you don't follow this user")
+	}
+	return nil
+}
+
+// RemoveFollower takes followerID off userID's followers
+func RemoveFollower(userID, followerID uint) error {
+	result := database.DB.
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, userID, models.FollowAccepted).
+		Delete(&models.Follow{})
+	if result.Error != nil {
+		return errors.New("failed to remove follower")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("this user doesn't follow you")
+	}
+	return nil
+}
+
+// ApproveFollowRequest accepts requesterID's pending request to follow userID
+func ApproveFollowRequest(notifier MessageNotifier, userID, requesterID uint) error {
+	result := database.DB.Model(&models.Follow{}).
+		Where("follower_id = ? AND following_id = ? AND status = ?", requesterID, userID, models.FollowPending).
+		Update("status", models.FollowAccepted)
+	if result.Error != nil {
+		return errors.New("failed to approve follow request")
+	}
+	if result.RowsAffected == 0 {
+		return ErrFollowRequestNotFound
+	}
+
+	sendEvent(notifier, requesterID, map[string]interface{}{
+		"type":    "follow_request_approved",
+		"user_id": userID,
+	})
+	return nil
+}
+
+// DenyFollowRequest drops requesterID's pending request to follow userID.
+// The requester isn't told.
+func DenyFollowRequest(userID, requesterID uint) error {
+	result := database.DB.
+		Where("follower_id = ? AND following_id = ? AND status = ?", requesterID, userID, models.FollowPending).
+		Delete(&models.Follow{})
+	if result.Error != nil {
+		return errors.New("failed to deny follow request")
+	}
+	if result.RowsAffected == 0 {
+		return ErrFollowRequestNotFound
+	}
+	return nil
+}
+
+// SetAccountPrivacy makes the account private or public. Going public
+// approves every pending request, since they'd be accepted right away now.
+func SetAccountPrivacy(userID uint, private bool) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("is_private", private).Error; err != nil {
+			return errors.New("failed to update privacy")
+		}
+		if private {
+			return nil
+		}
+		if err := tx.Model(&models.Follow{}).
+			Where("following_id = ? AND status = ?", userID, models.FollowPending).
+			Update("status", models.FollowAccepted).Error; err != nil {
+			return errors.New("failed to approve pending follow requests")
+		}
+		return nil
+	})
+}
+
+// ListFollowRequests returns the pending requests to follow userID, newest first
+func ListFollowRequests(userID uint) ([]RelatedUser, error) {
+	return listFollows("follower_id", "following_id", userID, models.FollowPending)
+}
+
+// ListSentFollowRequests returns the accounts userID asked to follow, newest first
+func ListSentFollowRequests(userID uint) ([]RelatedUser, error) {
+	return listFollows("following_id", "follower_id", userID, models.FollowPending)
+}
+
+// ListFollowers returns ownerID's followers if viewerID may see them
+func ListFollowers(viewerID, ownerID uint) ([]RelatedUser, error) {
+	if !CanViewContent(viewerID, ownerID) {
+		return nil, ErrPrivateAccount
+	}
+	return listFollows("follower_id", "following_id", ownerID, models.FollowAccepted)
+}
+
+// ListFollowing returns the accounts ownerID follows if viewerID may see them
+func ListFollowing(viewerID, ownerID uint) ([]RelatedUser, error) {
+	if !CanViewContent(viewerID, ownerID) {
+		return nil, ErrPrivateAccount
+	}
+	return listFollows("following_id", "follower_id", ownerID, models.FollowAccepted)
+}
+
+// listFollows returns the users in userColumn of follows whose matchColumn is
+// userID and whose status is status
+func listFollows(userColumn, matchColumn string, userID uint, status string) ([]RelatedUser, error) {
+	users := []RelatedUser{}
+	if err := database.DB.Table("follows").
+		Select("users.id, users.username, users.image_url, follows.created_at AS since").
+		Joins("JOIN users ON users.id = follows."+userColumn+" AND users.deleted_at IS NULL").
+		Where("follows."+matchColumn+" = ? AND follows.status = ?", userID, status).
+		Order("follows.created_at DESC").
+		Scan(&users).Error; err != nil {
+		return nil, errors.New("failed to fetch users")
+	}
+	return users, nil
+}
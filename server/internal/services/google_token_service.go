@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"os"
+)
+
+const defaultGoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+// Google signs ID tokens with either issuer form
+var googleIssuers = []string{"accounts.google.com", "https://accounts.google.com"}
+
+var googleKeys = newJWKSCache()
+
+// VerifyGoogleIDToken checks the signature, audience, issuer and expiry of a
+// Google ID token and returns its claims.
+//
+// GOOGLE_JWKS_URL overrides where signing keys are fetched from (for a local
+// stand-in in tests); the audience is GOOGLE_CLIENT_ID.
+func VerifyGoogleIDToken(idToken string) (*IDTokenClaims, error) {
+	clientID := os.Getenv("GOOGLE_CLIENT_ID")
+	if clientID == "" {
+		return nil, errors.New("google sign-in is not configured")
+	}
+
+	jwksURL := os.Getenv("GOOGLE_JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = defaultGoogleJWKSURL
+	}
+
+	claims, err := verifyIDToken(idToken, googleKeys, jwksURL, clientID, googleIssuers)
+	if err != nil {
+		return nil, errors.New("invalid Google ID token")
+	}
+	return claims, nil
+}
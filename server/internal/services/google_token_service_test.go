@@ -0,0 +1,135 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testGoogleClientID = "test-client.apps.googleusercontent.com"
+
+// newGoogleJWKSServer serves key's public half as a JWKS under kid and points
+// VerifyGoogleIDToken at it
+func newGoogleJWKSServer(t *testing.T, kid string, key *rsa.PrivateKey) {
+	t.Helper()
+
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("GOOGLE_JWKS_URL", server.URL)
+	t.Setenv("GOOGLE_CLIENT_ID", testGoogleClientID)
+}
+
+func TestVerifyGoogleIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validClaims := func() jwt.MapClaims {
+		now := time.Now()
+		return jwt.MapClaims{
+			"iss":            "https://accounts.google.com",
+			"aud":            testGoogleClientID,
+			"sub":            "1234567890",
+			"email":          "alice@gmail.com",
+			"email_verified": true,
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(jwt.MapClaims)
+		kid     string
+		signer  *rsa.PrivateKey
+		wantErr bool
+	}{
+		{name: "valid token", kid: "key-1", signer: key},
+		{name: "issuer without scheme", kid: "key-1", signer: key, edit: func(c jwt.MapClaims) { c["iss"] = "accounts.google.com" }},
+		{name: "wrong audience", kid: "key-1", signer: key, edit: func(c jwt.MapClaims) { c["aud"] = "someone-else" }, wantErr: true},
+		{name: "wrong issuer", kid: "key-1", signer: key, edit: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: true},
+		{name: "expired", kid: "key-1", signer: key, edit: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: true},
+		{name: "no expiry", kid: "key-1", signer: key, edit: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: true},
+		{name: "no subject", kid: "key-1", signer: key, edit: func(c jwt.MapClaims) { delete(c, "sub") }, wantErr: true},
+		{name: "signed by another key", kid: "key-1", signer: otherKey, wantErr: true},
+		{name: "unknown kid", kid: "key-2", signer: key, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newGoogleJWKSServer(t, "key-1", key)
+
+			claims := validClaims()
+			if tt.edit != nil {
+				tt.edit(claims)
+			}
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+			token.Header["kid"] = tt.kid
+			signed, err := token.SignedString(tt.signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := VerifyGoogleIDToken(signed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyGoogleIDToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.Subject != "1234567890" || got.Email != "alice@gmail.com" || !got.EmailVerified) {
+				t.Fatalf("claims = %+v", got)
+			}
+		})
+	}
+}
+
+func TestVerifyGoogleIDTokenRejectsHMAC(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newGoogleJWKSServer(t, "key-1", key)
+
+	// The classic alg confusion: an HMAC token keyed with the public modulus
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": "https://accounts.google.com",
+		"aud": testGoogleClientID,
+		"sub": "1234567890",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(key.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyGoogleIDToken(signed); err == nil {
+		t.Fatal("an HS256 token was accepted")
+	}
+}
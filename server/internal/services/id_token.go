@@ -0,0 +1,212 @@
+package services
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Used when the JWKS response has no Cache-Control max-age
+	defaultJWKSCacheTTL = time.Hour
+	// Minimum time between JWKS fetches, so unknown kids can't force a fetch per request
+	jwksRefetchInterval = time.Minute
+)
+
+// IDTokenClaims are the fields read from a verified OpenID Connect ID token
+type IDTokenClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+	Nonce             string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// jwksCache holds the RSA keys from a JWKS endpoint, refreshed when stale
+// or when a token names a kid that isn't cached yet
+type jwksCache struct {
+	mu        sync.Mutex
+	url       string
+	keys      map[string]*rsa.PublicKey
+	expiresAt time.Time
+	fetchedAt time.Time
+	client    *http.Client
+}
+
+func newJWKSCache() *jwksCache {
+	return &jwksCache{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// verifyIDToken checks an RS256 ID token's signature against the JWKS at
+// jwksURL, and its audience, issuer and expiry
+func verifyIDToken(rawToken string, keys *jwksCache, jwksURL, audience string, issuers []string) (*IDTokenClaims, error) {
+	claims := &IDTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid")
+		}
+		return keys.key(jwksURL, kid)
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		log.Printf("❌ ID token rejected: %v", err)
+		return nil, errors.New("invalid ID token")
+	}
+
+	validIssuer := false
+	for _, iss := range issuers {
+		if claims.Issuer == iss {
+			validIssuer = true
+			break
+		}
+	}
+	if !validIssuer {
+		log.Printf("❌ ID token has unexpected issuer %q", claims.Issuer)
+		return nil, errors.New("invalid ID token")
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("invalid ID token")
+	}
+
+	return claims, nil
+}
+
+// key returns the public key for kid, fetching the JWKS when the cache is
+// stale or doesn't know the kid. Fetches are at most once per jwksRefetchInterval.
+func (c *jwksCache) key(url, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.url != url {
+		// Configuration changed; drop keys from the old endpoint
+		c.url = url
+		c.keys = nil
+		c.fetchedAt = time.Time{}
+	}
+
+	if key, ok := c.keys[kid]; ok && now.Before(c.expiresAt) {
+		return key, nil
+	}
+
+	var refreshErr error
+	if now.Sub(c.fetchedAt) >= jwksRefetchInterval {
+		refreshErr = c.refresh(now)
+	}
+
+	key, ok := c.keys[kid]
+	if ok {
+		if refreshErr != nil {
+			// Keep serving cached keys while the endpoint is briefly unavailable
+			log.Printf("⚠️ Warning: Using cached signing keys, refresh failed: %v", refreshErr)
+		}
+		return key, nil
+	}
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// refresh fetches the JWKS. Callers must hold c.mu.
+func (c *jwksCache) refresh(now time.Time) error {
+	c.fetchedAt = now
+
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || k.Kid == "" {
+			continue
+		}
+		key, err := rsaPublicKeyFromJWK(k.N, k.E)
+		if err != nil {
+			log.Printf("⚠️ Warning: Skipping JWKS key %s: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return errors.New("JWKS contains no usable RSA keys")
+	}
+
+	c.keys = keys
+	c.expiresAt = now.Add(cacheMaxAge(resp.Header.Get("Cache-Control")))
+	log.Printf("✅ Loaded %d signing keys from %s", len(keys), c.url)
+	return nil
+}
+
+func rsaPublicKeyFromJWK(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(eBytes)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("unsupported exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// cacheMaxAge reads max-age from a Cache-Control header
+func cacheMaxAge(header string) time.Duration {
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.TrimSpace(directive)
+		if value, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return defaultJWKSCacheTTL
+}
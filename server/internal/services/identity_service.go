@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How long a verified identity waits for the user to confirm the link
+const identityLinkTTL = 10 * time.Minute
+
+// ErrIdentityNotFound is returned when no account is linked to a provider identity
+var ErrIdentityNotFound = errors.New("no account is linked to this login")
+
+var errLastLoginMethod = errors.New("set a password, add a passkey or link another account before removing your last login method")
+
+// Identities verified by a provider and waiting for ConfirmIdentityLink, keyed by link token
+var (
+	pendingLinksMu sync.Mutex
+	pendingLinks   = make(map[string]pendingIdentityLink)
+)
+
+type pendingIdentityLink struct {
+	userID    uint
+	identity  ExternalIdentity
+	expiresAt time.Time
+}
+
+// FindUserByIdentity returns the account linked to a provider identity
+func FindUserByIdentity(provider, subject string) (*models.User, error) {
+	var identity models.UserIdentity
+	if err := database.DB.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, errors.New("database error")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, identity.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIdentityNotFound
+		}
+		return nil, errors.New("database error")
+	}
+
+	database.DB.Model(&identity).UpdateColumn("last_used_at", time.Now())
+	return &user, nil
+}
+
+// ListIdentities returns the external logins linked to the user
+func ListIdentities(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error; err != nil {
+		return nil, errors.New("failed to fetch linked accounts")
+	}
+	return identities, nil
+}
+
+// LinkIdentity attaches a verified provider identity to the user
+func LinkIdentity(userID uint, identity *ExternalIdentity) (*models.UserIdentity, error) {
+	var existing models.UserIdentity
+	err := database.DB.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID == userID {
+			return nil, errors.New("this account is already linked")
+		}
+		return nil, errors.New("this account is already linked to another user")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("database error")
+	}
+
+	linked := models.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    truncate(identity.Email, 255),
+	}
+	if err := database.DB.Create(&linked).Error; err != nil {
+		// Lost a race with another link of the same identity
+		return nil, errors.New("failed to link account")
+	}
+
+	log.Printf("✅ Linked %s login to user %d", identity.Provider, userID)
+	return &linked, nil
+}
+
+// createPendingLink holds a provider-verified identity until the user who
+// started the link confirms it, and returns the one-time link token
+func createPendingLink(userID uint, identity *ExternalIdentity) (string, error) {
+	token, err := randomURLToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	pendingLinksMu.Lock()
+	defer pendingLinksMu.Unlock()
+
+	now := time.Now()
+	for k, link := range pendingLinks {
+		if now.After(link.expiresAt) {
+			delete(pendingLinks, k)
+		}
+	}
+	pendingLinks[token] = pendingIdentityLink{
+		userID:    userID,
+		identity:  *identity,
+		expiresAt: now.Add(identityLinkTTL),
+	}
+
+	return token, nil
+}
+
+// ConfirmIdentityLink links the identity behind linkToken. It must be confirmed
+// by the same user who started the link, so a link started or completed in
+// someone else's browser can't attach their login to this account or vice versa.
+func ConfirmIdentityLink(userID uint, linkToken string) (*models.UserIdentity, error) {
+	pendingLinksMu.Lock()
+	link, ok := pendingLinks[linkToken]
+	delete(pendingLinks, linkToken)
+	pendingLinksMu.Unlock()
+
+	if !ok || time.Now().After(link.expiresAt) || link.userID != userID {
+		return nil, errors.New("link request is invalid or has expired, please try again")
+	}
+
+	return LinkIdentity(userID, &link.identity)
+}
+
+// UnlinkIdentity removes a linked login, refusing to remove the account's last way to log in
+func UnlinkIdentity(userID, identityID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the user so two concurrent unlinks can't both pass the check
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+
+		var identity models.UserIdentity
+		if err := tx.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+			return errors.New("linked account not found")
+		}
+
+		methods, err := countLoginMethods(tx, user)
+		if err != nil {
+			return err
+		}
+		if methods <= 1 {
+			return errLastLoginMethod
+		}
+
+		if err := tx.Delete(&identity).Error; err != nil {
+			return errors.New("failed to unlink account")
+		}
+
+		log.Printf("✅ Unlinked %s login from user %d", identity.Provider, userID)
+		return nil
+	})
+}
+
+// countLoginMethods counts the ways the user can log in: a password, linked
+// logins and passkeys. Lock the user row first so concurrent removals can't
+// both see a spare method.
+func countLoginMethods(tx *gorm.DB, user models.User) (int64, error) {
+	var identities, passkeys int64
+	if err := tx.Model(&models.UserIdentity{}).Where("user_id = ?", user.ID).Count(&identities).Error; err != nil {
+		return 0, errors.New("database error")
+	}
+	if err := tx.Model(&models.WebAuthnCredential{}).Where("user_id = ?", user.ID).Count(&passkeys).Error; err != nil {
+		return 0, errors.New("database error")
+	}
+
+	methods := identities + passkeys
+	if user.HasPassword {
+		methods++
+	}
+	return methods, nil
+}
+
+// SetPassword adds password login to an account that was created through a provider
+func SetPassword(userID uint, newPassword string) error {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.HasPassword {
+		return errors.New("account already has a password, change it instead")
+	}
+
+	if err := validatePasswordStrength(newPassword); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to process new password")
+	}
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"password":     string(hashed),
+		"has_password": true,
+	}).Error; err != nil {
+		return errors.New("failed to set password")
+	}
+
+	return nil
+}
+
+// BackfillUserIdentities copies the single provider login stored on users into
+// user_identities. Run once, when the table is first created.
+func BackfillUserIdentities() error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO user_identities (user_id, provider, subject, email, created_at, updated_at)
+			SELECT id, provider, provider_id, email, NOW(), NOW()
+			FROM users
+			WHERE provider <> 'local' AND provider_id <> '' AND deleted_at IS NULL
+			ON CONFLICT DO NOTHING`).Error; err != nil {
+			return err
+		}
+
+		// Provider accounts were given a random password nobody knows
+		return tx.Model(&models.User{}).
+			Where("provider <> 'local'").
+			Update("has_password", false).Error
+	})
+}
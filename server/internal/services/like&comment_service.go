@@ -2,10 +2,13 @@ package services
 
 import (
 	"errors"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/Bauka07/SocialApp/internal/database"
 	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/policy"
 	"gorm.io/gorm"
 )
 
@@ -13,9 +16,9 @@ import (
 func ToggleLikeWithCount(userID, postID uint) (bool, int64, error) {
 	db := database.DB
 
-	// Check if post exists
+	// Check if post exists and the user can see it
 	var post models.Post
-	if err := db.First(&post, postID).Error; err != nil {
+	if err := db.Scopes(visiblePosts(userID)).First(&post, postID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false, 0, errors.New("post not found")
 		}
@@ -74,8 +77,30 @@ func ToggleLike(userID, postID uint) (bool, error) {
 	return liked, err
 }
 
-// GetPostLikes - Get all likes for a post
-func GetPostLikes(postID uint) ([]models.Like, error) {
+// ErrPostNotFound is returned when a post doesn't exist or the viewer can't see it
+var ErrPostNotFound = errors.New("post not found")
+
+// ensurePostVisible returns ErrPostNotFound unless viewerID can see the post
+func ensurePostVisible(postID, viewerID uint) error {
+	var count int64
+	if err := database.DB.Model(&models.Post{}).
+		Scopes(visiblePosts(viewerID)).
+		Where("posts.id = ?", postID).
+		Count(&count).Error; err != nil {
+		return errors.New("failed to fetch post")
+	}
+	if count == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// GetPostLikes - Get all likes for a post as seen by viewerID
+func GetPostLikes(postID, viewerID uint) ([]models.Like, error) {
+	if err := ensurePostVisible(postID, viewerID); err != nil {
+		return nil, err
+	}
+
 	var likes []models.Like
 	if err := database.DB.Where("post_id = ?", postID).
 		Preload("User").
@@ -132,26 +157,40 @@ func CreateComment(userID, postID uint, content string) (*models.Comment, error)
 		return nil, errors.New("comment must not exceed 1000 characters")
 	}
 
-	// Check if post exists
+	// Check if post exists and the user can see it (blocked users can't
+	// comment on each other's posts)
 	var post models.Post
-	if err := db.First(&post, postID).Error; err != nil {
+	if err := db.Scopes(visiblePosts(userID)).First(&post, postID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("post not found")
 		}
 		return nil, errors.New("failed to fetch post")
 	}
 
+	held, err := filterContent(filterContentComment, userID, &content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create comment
 	comment := models.Comment{
 		Content: content,
 		UserID:  userID,
 		PostID:  postID,
 	}
+	if held != nil {
+		now := time.Now()
+		comment.HeldAt = &now
+	}
 
 	if err := db.Create(&comment).Error; err != nil {
 		return nil, errors.New("failed to create comment")
 	}
 
+	if held != nil {
+		holdForReview(filterContentComment, comment.ID, userID, held)
+	}
+
 	// Load user data
 	if err := db.Preload("User").First(&comment, comment.ID).Error; err != nil {
 		return nil, errors.New("failed to load comment with user")
@@ -161,10 +200,15 @@ func CreateComment(userID, postID uint, content string) (*models.Comment, error)
 	return &comment, nil
 }
 
-// GetPostComments - Get all comments for a post
-func GetPostComments(postID uint) ([]models.Comment, error) {
+// GetPostComments - Get all comments for a post as seen by viewerID
+func GetPostComments(postID, viewerID uint) ([]models.Comment, error) {
+	if err := ensurePostVisible(postID, viewerID); err != nil {
+		return nil, err
+	}
+
 	var comments []models.Comment
-	if err := database.DB.Where("post_id = ?", postID).
+	if err := database.DB.Scopes(visibleComments(viewerID)).
+		Where("post_id = ?", postID).
 		Preload("User").
 		Order("created_at DESC").
 		Find(&comments).Error; err != nil {
@@ -179,10 +223,11 @@ func GetPostComments(postID uint) ([]models.Comment, error) {
 	return comments, nil
 }
 
-// GetCommentsCount - Get total comments for a post
-func GetCommentsCount(postID uint) (int64, error) {
+// GetCommentsCount - Get total comments for a post as seen by viewerID
+func GetCommentsCount(postID, viewerID uint) (int64, error) {
 	var count int64
 	if err := database.DB.Model(&models.Comment{}).
+		Scopes(visibleComments(viewerID)).
 		Where("post_id = ?", postID).
 		Count(&count).Error; err != nil {
 		return 0, errors.New("failed to count comments")
@@ -202,8 +247,11 @@ func DeleteComment(commentID, userID uint) error {
 		return errors.New("failed to fetch comment")
 	}
 
-	// Check ownership
-	if comment.UserID != userID {
+	actor, err := loadActor(userID)
+	if err != nil {
+		return err
+	}
+	if !policy.CanDeleteComment(actor, &comment) {
 		return errors.New("you don't have permission to delete this comment")
 	}
 
@@ -212,6 +260,10 @@ func DeleteComment(commentID, userID uint) error {
 		return errors.New("failed to delete comment")
 	}
 
+	if policy.IsModeratorAction(actor, comment.UserID) {
+		log.Printf("🛡️ Moderator %d removed comment %d by user %d", actor.ID, comment.ID, comment.UserID)
+	}
+
 	return nil
 }
 
@@ -227,8 +279,11 @@ func UpdateComment(commentID, userID uint, content string) (*models.Comment, err
 		return nil, errors.New("failed to fetch comment")
 	}
 
-	// Check ownership
-	if comment.UserID != userID {
+	actor, err := loadActor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanEditComment(actor, &comment) {
 		return nil, errors.New("you don't have permission to edit this comment")
 	}
 
@@ -242,13 +297,26 @@ func UpdateComment(commentID, userID uint, content string) (*models.Comment, err
 		return nil, errors.New("comment must not exceed 1000 characters")
 	}
 
+	held, err := filterContent(filterContentComment, comment.UserID, &content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update
 	comment.Content = content
+	if held != nil && comment.HeldAt == nil {
+		now := time.Now()
+		comment.HeldAt = &now
+	}
 
 	if err := database.DB.Save(&comment).Error; err != nil {
 		return nil, errors.New("failed to update comment")
 	}
 
+	if held != nil {
+		holdForReview(filterContentComment, comment.ID, comment.UserID, held)
+	}
+
 	// Reload with user
 	if err := database.DB.Preload("User").First(&comment, comment.ID).Error; err != nil {
 		return nil, errors.New("failed to reload comment")
@@ -263,6 +331,7 @@ func GetUserLikedPosts(userID uint) ([]map[string]interface{}, error) {
 	var posts []models.Post
 
 	if err := database.DB.
+		Scopes(visiblePosts(userID)).
 		Joins("JOIN likes ON likes.post_id = posts.id").
 		Where("likes.user_id = ?", userID).
 		Preload("User").
@@ -279,7 +348,7 @@ func GetUserLikedPosts(userID uint) ([]map[string]interface{}, error) {
 		likesCount, _ := GetLikesCount(post.ID)
 
 		// Get comments count
-		commentsCount, _ := GetCommentsCount(post.ID)
+		commentsCount, _ := GetCommentsCount(post.ID, userID)
 
 		// Clear password
 		post.User.Password = ""
@@ -292,6 +361,7 @@ func GetUserLikedPosts(userID uint) ([]map[string]interface{}, error) {
 			"created_at":     post.CreatedAt,
 			"updated_at":     post.UpdatedAt,
 			"user_id":        post.UserID,
+			"visibility":     post.Visibility,
 			"user":           post.User,
 			"likes_count":    likesCount,
 			"comments_count": commentsCount,
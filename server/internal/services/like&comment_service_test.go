@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestHeldCommentVisibility(t *testing.T) {
+	newTestDB(t)
+	reloadFilterRules()
+	t.Cleanup(reloadFilterRules)
+
+	author := createTestUser(t, "author")
+	commenter := createTestUser(t, "commenter")
+	other := createTestUser(t, "other")
+
+	post := models.Post{Title: "title", Content: "content", UserID: author.ID, Visibility: models.PostPublic}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+	rule := models.FilterRule{Kind: models.FilterKindWord, Pattern: "bad", Action: models.FilterActionHold, Enabled: true, CreatedByID: 1}
+	if err := database.DB.Create(&rule).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	comment, err := CreateComment(commenter.ID, post.ID, "a bad comment")
+	if err != nil {
+		t.Fatalf("create comment: %v", err)
+	}
+	var stored models.Comment
+	database.DB.First(&stored, comment.ID)
+	if stored.HeldAt == nil || stored.HiddenAt != nil {
+		t.Fatalf("filter hold should set held_at only: held_at %v, hidden_at %v", stored.HeldAt, stored.HiddenAt)
+	}
+
+	tests := []struct {
+		name     string
+		viewerID uint
+		want     int
+	}{
+		{name: "comment author", viewerID: commenter.ID, want: 1},
+		{name: "post author", viewerID: author.ID, want: 0},
+		{name: "other user", viewerID: other.ID, want: 0},
+		{name: "anonymous", viewerID: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments, err := GetPostComments(post.ID, tt.viewerID)
+			if err != nil {
+				t.Fatalf("get comments: %v", err)
+			}
+			if len(comments) != tt.want {
+				t.Fatalf("got %d comments, want %d", len(comments), tt.want)
+			}
+		})
+	}
+}
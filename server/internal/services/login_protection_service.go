@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Failed logins are counted per email and per client IP over LoginFailureWindow.
+// Past the backoff threshold each failure doubles the wait before the next
+// attempt, starting at LoginBackoffBase; past the lockout threshold the email
+// or IP is locked for LoginLockoutDuration.
+const (
+	LoginFailureWindow   = time.Hour
+	LoginBackoffBase     = time.Second
+	LoginLockoutDuration = 15 * time.Minute
+
+	AccountBackoffAfter = 3
+	AccountLockoutAfter = 10
+	IPBackoffAfter      = 10
+	IPLockoutAfter      = 50
+
+	loginHistoryLimit = 50
+)
+
+// dummyPasswordHash is compared against when the email is unknown
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+
+// ErrInvalidCredentials is returned for both unknown emails and wrong passwords
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// LoginLockedError is returned while an email or IP is backing off or locked
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	wait := e.RetryAfter.Round(time.Second)
+	if wait < time.Second {
+		wait = time.Second
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %s", wait)
+}
+
+// Reasons counted as failures; blocked attempts, pending second steps and
+// rejected passkey assertions (which can't be guessed) are not
+var countedLoginFailures = []string{models.LoginInvalidCredentials, models.LoginInvalidSecondStep}
+
+// LoginAttemptInfo describes where a login attempt came from
+type LoginAttemptInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// checkLoginAllowed rejects the attempt while the email or the IP is locked.
+// Unknown emails are throttled the same way so a lockout doesn't reveal which accounts exist.
+func checkLoginAllowed(email string, info LoginAttemptInfo) error {
+	now := time.Now()
+
+	if wait := lockRemaining(now, "email = ?", normalizeLoginEmail(email), AccountBackoffAfter, AccountLockoutAfter, true); wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	if wait := lockRemaining(now, "ip_address = ?", info.IPAddress, IPBackoffAfter, IPLockoutAfter, false); wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// lockRemaining returns how long attempts matching where must still wait
+func lockRemaining(now time.Time, where string, value string, backoffAfter, lockoutAfter int, resetOnSuccess bool) time.Duration {
+	failures, last := recentLoginFailures(where, value, resetOnSuccess)
+	if last == nil {
+		return 0
+	}
+
+	delay := loginDelay(failures, backoffAfter, lockoutAfter)
+	return last.Add(delay).Sub(now)
+}
+
+// recentLoginFailures counts failures matching where within LoginFailureWindow
+// and returns the time of the latest one. With resetOnSuccess, only failures
+// after the last successful login count.
+func recentLoginFailures(where string, value string, resetOnSuccess bool) (int, *time.Time) {
+	since := time.Now().Add(-LoginFailureWindow)
+
+	if resetOnSuccess {
+		var lastSuccess models.LoginAttempt
+		if err := database.DB.Where(where, value).
+			Where("success = true AND created_at > ?", since).
+			Order("created_at DESC").
+			First(&lastSuccess).Error; err == nil {
+			since = lastSuccess.CreatedAt
+		}
+	}
+
+	failures := database.DB.Model(&models.LoginAttempt{}).
+		Where(where, value).
+		Where("reason IN ? AND created_at > ?", countedLoginFailures, since)
+
+	// The latest failure is loaded as a row rather than MAX(created_at),
+	// which not every driver scans back into a time
+	var count int64
+	if err := failures.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+		log.Printf("⚠️ Warning: Could not count login failures: %v", err)
+		return 0, nil
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	var last models.LoginAttempt
+	if err := failures.Select("created_at").Order("created_at DESC").Take(&last).Error; err != nil {
+		log.Printf("⚠️ Warning: Could not count login failures: %v", err)
+		return 0, nil
+	}
+
+	return int(count), &last.CreatedAt
+}
+
+// loginDelay is the wait imposed after the given number of failures
+func loginDelay(failures, backoffAfter, lockoutAfter int) time.Duration {
+	switch {
+	case failures >= lockoutAfter:
+		return LoginLockoutDuration
+	case failures >= backoffAfter:
+		shift := failures - backoffAfter
+		if shift > 30 {
+			return LoginLockoutDuration
+		}
+		delay := LoginBackoffBase << uint(shift)
+		if delay > LoginLockoutDuration {
+			return LoginLockoutDuration
+		}
+		return delay
+	default:
+		return 0
+	}
+}
+
+// recordLoginAttempt stores an entry in the login history
+func recordLoginAttempt(user *models.User, email string, info LoginAttemptInfo, reason string) {
+	attempt := models.LoginAttempt{
+		Email:     normalizeLoginEmail(email),
+		IPAddress: truncate(info.IPAddress, 45),
+		Device:    describeDevice(info.UserAgent),
+		UserAgent: truncate(info.UserAgent, 255),
+		Success:   reason == models.LoginSucceeded,
+		Reason:    reason,
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+
+	if err := database.DB.Create(&attempt).Error; err != nil {
+		log.Printf("⚠️ Warning: Could not record login attempt: %v", err)
+	}
+
+	auditLoginAttempt(user, email, info, reason)
+}
+
+// auditLoginAttempt adds a finished login attempt to the audit log. Asking
+// for the second factor isn't an outcome yet, so it's skipped.
+func auditLoginAttempt(user *models.User, email string, info LoginAttemptInfo, reason string) {
+	if reason == models.LoginSecondStepRequired {
+		return
+	}
+
+	audit := AuditInfo{IPAddress: info.IPAddress, UserAgent: info.UserAgent}
+	var userID uint
+	if user != nil {
+		userID = user.ID
+	}
+
+	if reason == models.LoginSucceeded {
+		audit.ActorID = userID
+		RecordAudit(models.AuditLogin, audit, userID, nil)
+		return
+	}
+	RecordAudit(models.AuditLoginFailed, audit, userID, map[string]interface{}{
+		"email":  normalizeLoginEmail(email),
+		"reason": reason,
+	})
+}
+
+// recordLoginFailure stores a counted failure and, when it locks the account,
+// tells the owner by email
+func recordLoginFailure(user *models.User, email string, info LoginAttemptInfo, reason string) {
+	recordLoginAttempt(user, email, info, reason)
+
+	if user == nil {
+		return
+	}
+
+	failures, _ := recentLoginFailures("email = ?", normalizeLoginEmail(email), true)
+
+	// Only the failure that crosses the threshold sends an email
+	if failures != AccountLockoutAfter {
+		return
+	}
+
+	log.Printf("⚠️ Account locked after %d failed logins: user %d", failures, user.ID)
+	go func(to, username, ip string) {
+		if err := utils.SendAccountLockedEmail(to, username, ip, LoginLockoutDuration); err != nil {
+			log.Printf("❌ Failed to send lockout email: %v", err)
+		}
+	}(user.Email, user.Username, info.IPAddress)
+}
+
+// GetLoginHistory returns the user's most recent login attempts
+func GetLoginHistory(userID uint) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	if err := database.DB.
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(loginHistoryLimit).
+		Find(&attempts).Error; err != nil {
+		return nil, errors.New("failed to fetch login history")
+	}
+	return attempts, nil
+}
+
+// CleanupLoginHistory removes old login attempts (see StartCleanupJobs)
+func CleanupLoginHistory() error {
+	result := database.DB.Where("created_at < ?", time.Now().Add(-90*24*time.Hour)).
+		Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		return fmt.Errorf("cleanup failed: %w", result.Error)
+	}
+	return nil
+}
+
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
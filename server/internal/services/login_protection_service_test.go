@@ -0,0 +1,266 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// seedLoginAttempts stores n attempts for email from ip that happened ago
+func seedLoginAttempts(t *testing.T, email, ip, reason string, n int, ago time.Duration) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		attempt := models.LoginAttempt{
+			CreatedAt: time.Now().Add(-ago),
+			Email:     email,
+			IPAddress: ip,
+			Success:   reason == models.LoginSucceeded,
+			Reason:    reason,
+		}
+		if err := database.DB.Create(&attempt).Error; err != nil {
+			t.Fatalf("seed login attempt: %v", err)
+		}
+	}
+}
+
+func TestLoginDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "below backoff", failures: AccountBackoffAfter - 1, want: 0},
+		{name: "first backoff", failures: AccountBackoffAfter, want: LoginBackoffBase},
+		{name: "backoff doubles", failures: AccountBackoffAfter + 2, want: 4 * LoginBackoffBase},
+		{name: "lockout", failures: AccountLockoutAfter, want: LoginLockoutDuration},
+		{name: "past lockout", failures: AccountLockoutAfter + 5, want: LoginLockoutDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := loginDelay(tt.failures, AccountBackoffAfter, AccountLockoutAfter); got != tt.want {
+				t.Fatalf("loginDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+
+	// A high backoff threshold never doubles past the lockout, or overflows
+	if got := loginDelay(IPLockoutAfter-1, 0, IPLockoutAfter); got != LoginLockoutDuration {
+		t.Fatalf("long backoff = %v, want it capped at %v", got, LoginLockoutDuration)
+	}
+}
+
+func TestCheckLoginAllowed(t *testing.T) {
+	const email, ip = "alice@example.com", "203.0.113.7"
+
+	tests := []struct {
+		name string
+		seed func(t *testing.T)
+		// checked with email and ip unless set
+		checkEmail string
+		locked     bool
+		maxWait    time.Duration
+	}{
+		{name: "no history", seed: func(t *testing.T) {}},
+		{
+			name: "below account backoff",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountBackoffAfter-1, 0)
+			},
+		},
+		{
+			name: "account backoff",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountBackoffAfter, 0)
+			},
+			locked:  true,
+			maxWait: LoginBackoffBase,
+		},
+		{
+			name: "account backoff elapsed",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountBackoffAfter, 2*LoginBackoffBase)
+			},
+		},
+		{
+			name: "backoff doubles with each failure",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountBackoffAfter+2, 2*LoginBackoffBase)
+			},
+			locked:  true,
+			maxWait: 2 * LoginBackoffBase,
+		},
+		{
+			name: "account lockout",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidSecondStep, AccountLockoutAfter, time.Minute)
+			},
+			locked:  true,
+			maxWait: LoginLockoutDuration - time.Minute,
+		},
+		{
+			name: "account lockout elapsed",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountLockoutAfter, LoginLockoutDuration+time.Minute)
+			},
+		},
+		{
+			name: "failures outside the window",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountLockoutAfter, LoginFailureWindow+time.Minute)
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, 1, 0)
+			},
+		},
+		{
+			name: "uncounted reasons",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginBlocked, AccountLockoutAfter, 0)
+				seedLoginAttempts(t, email, ip, models.LoginEmailUnverified, AccountLockoutAfter, 0)
+				seedLoginAttempts(t, email, ip, models.LoginInvalidPasskey, AccountLockoutAfter, 0)
+			},
+		},
+		{
+			name: "success resets the account count",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountLockoutAfter, 2*time.Minute)
+				seedLoginAttempts(t, email, ip, models.LoginSucceeded, 1, time.Minute)
+			},
+		},
+		{
+			name: "email is matched case-insensitively",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, email, ip, models.LoginInvalidCredentials, AccountLockoutAfter, 0)
+			},
+			checkEmail: "  Alice@Example.COM ",
+			locked:     true,
+			maxWait:    LoginLockoutDuration,
+		},
+		{
+			name: "other emails are unaffected",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, "bob@example.com", "198.51.100.1", models.LoginInvalidCredentials, AccountLockoutAfter, 0)
+			},
+		},
+		{
+			name: "unknown email is locked the same way",
+			seed: func(t *testing.T) {
+				seedLoginAttempts(t, "ghost@example.com", ip, models.LoginInvalidCredentials, AccountLockoutAfter, 0)
+			},
+			checkEmail: "ghost@example.com",
+			locked:     true,
+			maxWait:    LoginLockoutDuration,
+		},
+		{
+			name: "ip backoff across emails",
+			seed: func(t *testing.T) {
+				for i := 0; i < IPBackoffAfter; i++ {
+					seedLoginAttempts(t, fmt.Sprintf("user%d@example.com", i), ip, models.LoginInvalidCredentials, 1, 0)
+				}
+			},
+			locked:  true,
+			maxWait: LoginBackoffBase,
+		},
+		{
+			name: "success doesn't reset the ip count",
+			seed: func(t *testing.T) {
+				for i := 0; i < IPLockoutAfter; i++ {
+					seedLoginAttempts(t, fmt.Sprintf("user%d@example.com", i), ip, models.LoginInvalidCredentials, 1, 2*time.Minute)
+				}
+				seedLoginAttempts(t, email, ip, models.LoginSucceeded, 1, time.Minute)
+			},
+			locked:  true,
+			maxWait: LoginLockoutDuration - 2*time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			tt.seed(t)
+
+			checkEmail := tt.checkEmail
+			if checkEmail == "" {
+				checkEmail = email
+			}
+			err := checkLoginAllowed(checkEmail, LoginAttemptInfo{IPAddress: ip})
+
+			var locked *LoginLockedError
+			if errors.As(err, &locked) != tt.locked {
+				t.Fatalf("checkLoginAllowed() = %v, locked want %v", err, tt.locked)
+			}
+			if tt.locked && (locked.RetryAfter <= 0 || locked.RetryAfter > tt.maxWait) {
+				t.Fatalf("retry after %v, want between 0 and %v", locked.RetryAfter, tt.maxWait)
+			}
+		})
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := LoginAttemptInfo{IPAddress: "203.0.113.7", UserAgent: "test"}
+
+	tests := []struct {
+		name    string
+		email   string
+		elapsed bool // whether the backoff has passed before the last attempt
+		wantErr error
+	}{
+		{name: "right password during backoff", email: "alice@example.com", wantErr: &LoginLockedError{}},
+		{name: "right password after backoff", email: "alice@example.com", elapsed: true},
+		{name: "unknown email during backoff", email: "ghost@example.com", wantErr: &LoginLockedError{}},
+		{name: "unknown email after backoff", email: "ghost@example.com", elapsed: true, wantErr: ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			user := createTestUser(t, "alice")
+			database.DB.Model(user).Update("password", string(hash))
+
+			for i := 0; i < AccountBackoffAfter; i++ {
+				_, err := LEH(&models.User{Email: tt.email, Password: "wrong"}, info)
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Fatalf("failure %d: got %v, want invalid credentials", i+1, err)
+				}
+			}
+			if tt.elapsed {
+				database.DB.Model(&models.LoginAttempt{}).Where("1 = 1").
+					Update("created_at", time.Now().Add(-2*LoginBackoffBase))
+			}
+
+			_, err := LEH(&models.User{Email: tt.email, Password: "correct horse"}, info)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("login after backoff: %v", err)
+				}
+			case *LoginLockedError:
+				var locked *LoginLockedError
+				if !errors.As(err, &locked) {
+					t.Fatalf("got %v, want a lockout", err)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Fatalf("got %v, want %v", err, want)
+				}
+			}
+
+			// Blocked attempts are recorded but don't extend the backoff
+			var blocked int64
+			database.DB.Model(&models.LoginAttempt{}).Where("reason = ?", models.LoginBlocked).Count(&blocked)
+			if wantBlocked := tt.wantErr != nil && !tt.elapsed; (blocked == 1) != wantBlocked {
+				t.Fatalf("recorded %d blocked attempts", blocked)
+			}
+		})
+	}
+}
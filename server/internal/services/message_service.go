@@ -0,0 +1,267 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/policy"
+	"gorm.io/gorm"
+)
+
+// MessageNotifier delivers real-time events to connected users (implemented by the hub)
+type MessageNotifier interface {
+	SendToUser(userID uint, message []byte)
+}
+
+// SendMessage validates and stores a chat message, then delivers a
+// new_message event to every connection of the sender and the receiver.
+// Used by both the WebSocket send_message handler and POST /api/messages.
+func SendMessage(notifier MessageNotifier, senderID, receiverID uint, content string, replyToID *uint) (*models.Message, error) {
+	db := database.DB
+
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("message content is required")
+	}
+
+	if receiverID == 0 {
+		return nil, errors.New("invalid receiver ID")
+	}
+
+	if receiverID == senderID {
+		return nil, errors.New("you cannot send a message to yourself")
+	}
+
+	var receiver models.User
+	if err := db.Select("id").First(&receiver, receiverID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("receiver not found")
+		}
+		return nil, errors.New("failed to fetch receiver")
+	}
+
+	if IsBlockedBetween(senderID, receiverID) {
+		return nil, ErrUserBlocked
+	}
+
+	// If replying to a message, verify it exists and isn't deleted
+	if replyToID != nil {
+		var replyToMsg models.Message
+		if err := db.First(&replyToMsg, *replyToID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("reply message not found")
+			}
+			return nil, errors.New("failed to fetch reply message")
+		}
+
+		// Check if message is deleted for the sender
+		if (replyToMsg.SenderID == senderID && replyToMsg.DeletedForSender) ||
+			(replyToMsg.ReceiverID == senderID && replyToMsg.DeletedForReceiver) {
+			return nil, errors.New("cannot reply to a deleted message")
+		}
+
+		// Verify the reply message is part of this conversation
+		if !((replyToMsg.SenderID == senderID && replyToMsg.ReceiverID == receiverID) ||
+			(replyToMsg.SenderID == receiverID && replyToMsg.ReceiverID == senderID)) {
+			return nil, errors.New("reply message is not part of this conversation")
+		}
+	}
+
+	held, err := filterContent(filterContentMessage, senderID, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	message := models.Message{
+		Content:    content,
+		SenderID:   senderID,
+		ReceiverID: receiverID,
+		IsRead:     false,
+		ReplyToID:  replyToID,
+	}
+	if held != nil {
+		now := time.Now()
+		message.HiddenAt = &now
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&message).Error
+	}); err != nil {
+		log.Printf("❌ Error saving message: %v", err)
+		return nil, errors.New("failed to send message")
+	}
+
+	// Load relations (including reply_to)
+	db.Preload("Sender").Preload("Receiver").Preload("ReplyTo").First(&message, message.ID)
+
+	log.Printf("✅ Message saved: ID=%d, From=%d, To=%d", message.ID, message.SenderID, message.ReceiverID)
+
+	if held != nil {
+		holdForReview(filterContentMessage, message.ID, senderID, held)
+	}
+
+	if notifier != nil {
+		response := map[string]interface{}{
+			"type":    "new_message",
+			"message": message,
+		}
+
+		responseJSON, err := json.Marshal(response)
+		if err != nil {
+			log.Printf("❌ Error marshaling response: %v", err)
+			return &message, nil
+		}
+
+		// Sender gets a confirmation on all of their connections; a held
+		// message reaches the receiver once a moderator approves it, and a
+		// shadow-banned sender's never does
+		notifier.SendToUser(senderID, responseJSON)
+		if held == nil && !IsShadowBanned(senderID) {
+			notifier.SendToUser(receiverID, responseJSON)
+		}
+	}
+
+	return &message, nil
+}
+
+// ErrMessageNotFound is returned when a message doesn't exist
+var ErrMessageNotFound = errors.New("message not found")
+
+// ErrMessageForbidden is returned when the user may not change a message
+var ErrMessageForbidden = errors.New("you can only change your own messages")
+
+// EditMessage replaces a message's content and delivers a message_edited
+// event to both participants
+func EditMessage(notifier MessageNotifier, userID, messageID uint, content string) (*models.Message, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, errors.New("message content is required")
+	}
+
+	var message models.Message
+	if err := database.DB.First(&message, messageID).Error; err != nil {
+		return nil, ErrMessageNotFound
+	}
+
+	actor, err := loadActor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanEditMessage(actor, &message) {
+		return nil, ErrMessageForbidden
+	}
+
+	held, err := filterContent(filterContentMessage, message.SenderID, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	wasVisible := message.HiddenAt == nil
+	message.Content = content
+	if held != nil && wasVisible {
+		now := time.Now()
+		message.HiddenAt = &now
+	}
+	if err := database.DB.Save(&message).Error; err != nil {
+		return nil, errors.New("failed to update message")
+	}
+
+	if held != nil {
+		holdForReview(filterContentMessage, message.ID, message.SenderID, held)
+
+		// Take the message back from the receiver until it's approved
+		sendEvent(notifier, message.SenderID, map[string]interface{}{
+			"type":    "message_edited",
+			"message": message,
+		})
+		if wasVisible {
+			sendEvent(notifier, message.ReceiverID, map[string]interface{}{
+				"type":       "message_deleted",
+				"message_id": message.ID,
+			})
+		}
+		return &message, nil
+	}
+
+	if message.HiddenAt != nil {
+		// Still waiting for review from an earlier edit
+		sendEvent(notifier, message.SenderID, map[string]interface{}{
+			"type":    "message_edited",
+			"message": message,
+		})
+		return &message, nil
+	}
+
+	notifyParticipants(notifier, &message, map[string]interface{}{
+		"type":    "message_edited",
+		"message": message,
+	})
+
+	return &message, nil
+}
+
+// DeleteMessage hides a message for its sender, or for both participants when
+// deleteFor is "all". A moderator removing someone else's message always
+// removes it for both.
+func DeleteMessage(notifier MessageNotifier, userID, messageID uint, deleteFor string) error {
+	var message models.Message
+	if err := database.DB.First(&message, messageID).Error; err != nil {
+		return ErrMessageNotFound
+	}
+
+	actor, err := loadActor(userID)
+	if err != nil {
+		return err
+	}
+	if !policy.CanDeleteMessage(actor, &message) {
+		return ErrMessageForbidden
+	}
+
+	moderated := policy.IsModeratorAction(actor, message.SenderID)
+	if deleteFor == "all" || moderated {
+		message.DeletedForSender = true
+		message.DeletedForReceiver = true
+	} else {
+		message.DeletedForSender = true
+	}
+
+	if err := database.DB.Save(&message).Error; err != nil {
+		return errors.New("failed to delete message")
+	}
+
+	if message.DeletedForReceiver {
+		notifyParticipants(notifier, &message, map[string]interface{}{
+			"type":       "message_deleted",
+			"message_id": message.ID,
+		})
+	}
+
+	if moderated {
+		log.Printf("🛡️ Moderator %d removed message %d from user %d", actor.ID, message.ID, message.SenderID)
+	}
+
+	return nil
+}
+
+// notifyParticipants sends an event to the sender and the receiver of
+// message. The receiver never sees a shadow-banned sender's messages, so
+// they get nothing.
+func notifyParticipants(notifier MessageNotifier, message *models.Message, event map[string]interface{}) {
+	if notifier == nil {
+		return
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error marshaling notification: %v", err)
+		return
+	}
+
+	notifier.SendToUser(message.SenderID, eventJSON)
+	if !IsShadowBanned(message.SenderID) {
+		notifier.SendToUser(message.ReceiverID, eventJSON)
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+)
+
+// recordingNotifier keeps every event sent through it
+type recordingNotifier struct {
+	sent map[uint][][]byte
+}
+
+func (n *recordingNotifier) SendToUser(userID uint, message []byte) {
+	if n.sent == nil {
+		n.sent = make(map[uint][][]byte)
+	}
+	n.sent[userID] = append(n.sent[userID], message)
+}
+
+func TestSendMessageTrimsParticipants(t *testing.T) {
+	newTestDB(t)
+	sender := createTestUser(t, "sender")
+	receiver := createTestUser(t, "receiver")
+	database.DB.Model(receiver).Update("pending_email", "new@example.com")
+
+	notifier := &recordingNotifier{}
+	message, err := SendMessage(notifier, sender.ID, receiver.ID, "hello", nil)
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	returned, err := json.Marshal(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifier.sent[receiver.ID]) != 1 {
+		t.Fatalf("receiver got %d events, want 1", len(notifier.sent[receiver.ID]))
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+	}{
+		{name: "REST response", payload: returned},
+		{name: "sender event", payload: notifier.sent[sender.ID][0]},
+		{name: "receiver event", payload: notifier.sent[receiver.ID][0]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := string(tt.payload)
+			if !strings.Contains(body, `"username":"sender"`) || !strings.Contains(body, `"username":"receiver"`) {
+				t.Fatalf("participants missing from %s", body)
+			}
+			for _, field := range []string{`"email"`, `"pending_email"`, `"password"`, `"role"`} {
+				if strings.Contains(body, field) {
+					t.Fatalf("payload exposes %s: %s", field, body)
+				}
+			}
+		})
+	}
+}
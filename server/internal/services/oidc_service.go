@@ -0,0 +1,591 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	// How long a started login may take before its state expires
+	oidcStateTTL = 10 * time.Minute
+	// Minimum time between discovery attempts after a failure
+	oidcDiscoveryRetry = time.Minute
+)
+
+// OIDCProvider is a configured login provider with its discovered endpoints
+type OIDCProvider struct {
+	config.OIDCProviderConfig
+
+	mu            sync.Mutex
+	endpoints     *oidcEndpoints
+	lastDiscovery time.Time
+	keys          *jwksCache
+}
+
+type oidcEndpoints struct {
+	Issuer      string `json:"issuer"`
+	AuthURL     string `json:"authorization_endpoint"`
+	TokenURL    string `json:"token_endpoint"`
+	UserInfoURL string `json:"userinfo_endpoint"`
+	JWKSURL     string `json:"jwks_uri"`
+}
+
+// Plain OAuth2 providers without discovery
+var githubEndpoints = &oidcEndpoints{
+	AuthURL:     "https://github.com/login/oauth/authorize",
+	TokenURL:    "https://github.com/login/oauth/access_token",
+	UserInfoURL: "https://api.github.com/user",
+}
+
+// ExternalIdentity is what a provider tells us about the user who logged in
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	Picture       string
+}
+
+// LoginProviderInfo is the public description of a provider, for login buttons
+type LoginProviderInfo struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+var (
+	oidcProviders     = make(map[string]*OIDCProvider)
+	oidcProviderOrder []string
+
+	oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// InitOIDCProviders builds the provider registry from config.OIDCProviders.
+// Discovery happens on first use so a provider being down doesn't block startup.
+func InitOIDCProviders() {
+	oidcProviders = make(map[string]*OIDCProvider)
+	oidcProviderOrder = nil
+
+	for _, cfg := range config.OIDCProviders {
+		oidcProviders[cfg.Name] = &OIDCProvider{OIDCProviderConfig: cfg, keys: newJWKSCache()}
+		oidcProviderOrder = append(oidcProviderOrder, cfg.Name)
+	}
+}
+
+// ListLoginProviders returns the configured providers in configuration order
+func ListLoginProviders() []LoginProviderInfo {
+	providers := make([]LoginProviderInfo, 0, len(oidcProviderOrder))
+	for _, name := range oidcProviderOrder {
+		p := oidcProviders[name]
+		providers = append(providers, LoginProviderInfo{Name: p.Name, DisplayName: p.DisplayName})
+	}
+	return providers
+}
+
+func getOIDCProvider(name string) (*OIDCProvider, error) {
+	p, ok := oidcProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown login provider %q", name)
+	}
+	return p, nil
+}
+
+// discover returns the provider's endpoints, fetching the discovery document once
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcEndpoints, error) {
+	if p.Type == "github" {
+		return githubEndpoints, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+	if time.Since(p.lastDiscovery) < oidcDiscoveryRetry {
+		return nil, fmt.Errorf("login provider %s is unavailable", p.Name)
+	}
+	p.lastDiscovery = time.Now()
+
+	discoveryURL := strings.TrimRight(p.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		log.Printf("❌ OIDC discovery failed for %s: %v", p.Name, err)
+		return nil, fmt.Errorf("login provider %s is unavailable", p.Name)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("❌ OIDC discovery for %s returned status %d", p.Name, resp.StatusCode)
+		return nil, fmt.Errorf("login provider %s is unavailable", p.Name)
+	}
+
+	var endpoints oidcEndpoints
+	if err := json.NewDecoder(resp.Body).Decode(&endpoints); err != nil {
+		log.Printf("❌ OIDC discovery document for %s is invalid: %v", p.Name, err)
+		return nil, fmt.Errorf("login provider %s is unavailable", p.Name)
+	}
+
+	// The document must describe the issuer we were configured with
+	if strings.TrimRight(endpoints.Issuer, "/") != strings.TrimRight(p.Issuer, "/") ||
+		endpoints.AuthURL == "" || endpoints.TokenURL == "" || endpoints.JWKSURL == "" {
+		log.Printf("❌ OIDC discovery document for %s doesn't match issuer %s", p.Name, p.Issuer)
+		return nil, fmt.Errorf("login provider %s is misconfigured", p.Name)
+	}
+
+	p.endpoints = &endpoints
+	log.Printf("✅ Discovered login provider %s (%s)", p.Name, endpoints.Issuer)
+	return p.endpoints, nil
+}
+
+func (p *OIDCProvider) oauth2Config(endpoints *oidcEndpoints) *oauth2.Config {
+	scopes := p.Scopes
+	if len(scopes) == 0 {
+		if p.Type == "github" {
+			scopes = []string{"read:user", "user:email"}
+		} else {
+			scopes = []string{"openid", "email", "profile"}
+		}
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  p.RedirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  endpoints.AuthURL,
+			TokenURL: endpoints.TokenURL,
+		},
+	}
+}
+
+// Logins that have been started but not completed, keyed by state
+var (
+	oidcPendingMu sync.Mutex
+	oidcPending   = make(map[string]oidcPendingLogin)
+)
+
+type oidcPendingLogin struct {
+	provider  string
+	verifier  string
+	nonce     string
+	expiresAt time.Time
+	// Set when an authenticated user is linking the provider to their account
+	linkUserID uint
+}
+
+// OIDCCallbackResult is the outcome of a provider callback: a user to log in,
+// or a link token the linking user must confirm
+type OIDCCallbackResult struct {
+	User      *models.User
+	LinkToken string
+}
+
+// BeginOIDCLogin starts a login with the named provider and returns the URL to
+// send the browser to, plus the state the callback must present
+func BeginOIDCLogin(ctx context.Context, providerName string) (authURL string, state string, err error) {
+	return beginOIDC(ctx, providerName, 0)
+}
+
+// BeginOIDCLink starts linking the named provider to the user's account
+func BeginOIDCLink(ctx context.Context, providerName string, userID uint) (authURL string, err error) {
+	authURL, _, err = beginOIDC(ctx, providerName, userID)
+	return authURL, err
+}
+
+func beginOIDC(ctx context.Context, providerName string, linkUserID uint) (authURL string, state string, err error) {
+	p, err := getOIDCProvider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	endpoints, err := p.discover(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err = randomURLToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomURLToken(16)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	oidcPendingMu.Lock()
+	now := time.Now()
+	for k, pending := range oidcPending {
+		if now.After(pending.expiresAt) {
+			delete(oidcPending, k)
+		}
+	}
+	oidcPending[state] = oidcPendingLogin{
+		provider:   p.Name,
+		verifier:   verifier,
+		nonce:      nonce,
+		expiresAt:  now.Add(oidcStateTTL),
+		linkUserID: linkUserID,
+	}
+	oidcPendingMu.Unlock()
+
+	opts := []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)}
+	if p.Type == "oidc" {
+		opts = append(opts, oauth2.SetAuthURLParam("nonce", nonce))
+	}
+
+	return p.oauth2Config(endpoints).AuthCodeURL(state, opts...), state, nil
+}
+
+// CompleteOIDCCallback exchanges the authorization code from the callback.
+// For a login it returns the local user for the provider account, creating it
+// on first login; browserBound reports whether the state cookie matched, which
+// logins require. For a link it returns a link token instead.
+func CompleteOIDCCallback(ctx context.Context, providerName, state, code string, browserBound bool) (*OIDCCallbackResult, error) {
+	oidcPendingMu.Lock()
+	pending, ok := oidcPending[state]
+	delete(oidcPending, state) // single use
+	oidcPendingMu.Unlock()
+
+	if !ok || time.Now().After(pending.expiresAt) || pending.provider != providerName {
+		return nil, errors.New("login request is invalid or has expired, please try again")
+	}
+
+	// Links are bound to the user instead, see ConfirmIdentityLink
+	if pending.linkUserID == 0 && !browserBound {
+		return nil, errors.New("login request is invalid or has expired, please try again")
+	}
+
+	p, err := getOIDCProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, oidcHTTPClient)
+	token, err := p.oauth2Config(endpoints).Exchange(ctx, code, oauth2.VerifierOption(pending.verifier))
+	if err != nil {
+		log.Printf("❌ Code exchange with %s failed: %v", p.Name, err)
+		return nil, errors.New("failed to complete login with provider")
+	}
+
+	var identity *ExternalIdentity
+	if p.Type == "github" {
+		identity, err = githubIdentity(ctx, token)
+	} else {
+		identity, err = p.oidcIdentity(ctx, endpoints, token, pending.nonce)
+	}
+	if err != nil {
+		return nil, err
+	}
+	identity.Provider = p.Name
+
+	if pending.linkUserID != 0 {
+		linkToken, err := createPendingLink(pending.linkUserID, identity)
+		if err != nil {
+			return nil, err
+		}
+		return &OIDCCallbackResult{LinkToken: linkToken}, nil
+	}
+
+	user, err := findOrCreateExternalUser(identity)
+	if err != nil {
+		return nil, err
+	}
+	return &OIDCCallbackResult{User: user}, nil
+}
+
+// oidcIdentity verifies the ID token from the token response, falling back to
+// the userinfo endpoint when the ID token carries no email
+func (p *OIDCProvider) oidcIdentity(ctx context.Context, endpoints *oidcEndpoints, token *oauth2.Token, nonce string) (*ExternalIdentity, error) {
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New("provider did not return an ID token")
+	}
+
+	issuers := []string{endpoints.Issuer}
+	if endpoints.Issuer == "https://accounts.google.com" {
+		issuers = googleIssuers
+	}
+
+	claims, err := verifyIDToken(rawIDToken, p.keys, endpoints.JWKSURL, p.ClientID, issuers)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != nonce {
+		log.Printf("❌ ID token nonce mismatch for %s", p.Name)
+		return nil, errors.New("invalid ID token")
+	}
+
+	identity := &ExternalIdentity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified || p.TrustEmail,
+		Username:      claims.PreferredUsername,
+		Picture:       claims.Picture,
+	}
+
+	if identity.Email == "" && endpoints.UserInfoURL != "" {
+		var info struct {
+			Subject       string `json:"sub"`
+			Email         string `json:"email"`
+			EmailVerified bool   `json:"email_verified"`
+		}
+		if err := fetchJSON(ctx, endpoints.UserInfoURL, token, &info); err == nil && info.Subject == claims.Subject {
+			identity.Email = info.Email
+			identity.EmailVerified = info.EmailVerified || p.TrustEmail
+		}
+	}
+
+	return identity, nil
+}
+
+// githubIdentity reads the profile and primary verified email from the GitHub API
+func githubIdentity(ctx context.Context, token *oauth2.Token) (*ExternalIdentity, error) {
+	var profile struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := fetchJSON(ctx, githubEndpoints.UserInfoURL, token, &profile); err != nil || profile.ID == 0 {
+		return nil, errors.New("failed to fetch GitHub profile")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := fetchJSON(ctx, githubEndpoints.UserInfoURL+"/emails", token, &emails); err != nil {
+		return nil, errors.New("failed to fetch GitHub email")
+	}
+
+	identity := &ExternalIdentity{
+		Subject:  strconv.FormatInt(profile.ID, 10),
+		Username: profile.Login,
+		Picture:  profile.AvatarURL,
+	}
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			identity.Email = e.Email
+			identity.EmailVerified = true
+			break
+		}
+	}
+
+	return identity, nil
+}
+
+func fetchJSON(ctx context.Context, url string, token *oauth2.Token, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	token.SetAuthHeader(req)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := oidcHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// findOrCreateExternalUser returns the user linked to the provider account,
+// or registers a new one from the identity
+func findOrCreateExternalUser(identity *ExternalIdentity) (*models.User, error) {
+	user, err := FindUserByIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	return CreateExternalUser(identity)
+}
+
+// CreateExternalUser registers a new account for a provider identity.
+// The email must be verified by the provider and not belong to another account.
+func CreateExternalUser(identity *ExternalIdentity) (*models.User, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, fmt.Errorf("your %s account has no verified email address", identity.Provider)
+	}
+
+	var existing models.User
+	if err := database.DB.Where("email = ?", identity.Email).First(&existing).Error; err == nil {
+		return nil, errors.New("email already registered with another account, log in and link it from your settings")
+	}
+
+	base := identity.Username
+	if base == "" {
+		base = strings.Split(identity.Email, "@")[0]
+	}
+	username, err := uniqueUsername(base)
+	if err != nil {
+		return nil, err
+	}
+
+	// Password login stays off until the user sets one; store an unguessable placeholder
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(GenerateRandomPassword(16)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to create user")
+	}
+
+	user := models.User{
+		Username:   username,
+		Email:      identity.Email,
+		Password:   string(hashedPassword),
+		ImageURL:   truncate(identity.Picture, 255),
+		Provider:   identity.Provider,
+		ProviderID: identity.Subject,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		// has_password defaults to true in the database, see models.User
+		if err := tx.Model(&user).Update("has_password", false).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    truncate(identity.Email, 255),
+		}).Error
+	})
+	if err != nil {
+		log.Printf("❌ Failed to create %s user: %v", identity.Provider, err)
+		return nil, errors.New("failed to create user")
+	}
+
+	log.Printf("✅ Created user %d from %s login", user.ID, identity.Provider)
+	return &user, nil
+}
+
+var usernameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// uniqueUsername derives a free username from base, adding a numeric suffix if taken
+func uniqueUsername(base string) (string, error) {
+	base = usernameUnsafe.ReplaceAllString(base, "")
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	base = truncate(base, 24)
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		if err := database.DB.Model(&models.User{}).Unscoped().Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", errors.New("database error")
+		}
+		if count == 0 && !IsReservedUsername(candidate) {
+			return candidate, nil
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(100000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s%d", base, n.Int64())
+	}
+
+	return "", errors.New("could not choose a username, please try again")
+}
+
+// How long the frontend has to redeem a login handoff code
+const loginHandoffTTL = time.Minute
+
+// Finished provider callbacks waiting for the frontend to fetch their
+// result, keyed by a one-time code. The redirect to the frontend carries only
+// the code, so tokens stay out of browser history, proxy logs and Referer
+// headers.
+var (
+	loginHandoffMu sync.Mutex
+	loginHandoffs  = make(map[string]loginHandoff)
+)
+
+type loginHandoff struct {
+	result    map[string]interface{}
+	expiresAt time.Time
+}
+
+// StoreLoginHandoff keeps a callback's result and returns the code the
+// frontend redeems it with
+func StoreLoginHandoff(result map[string]interface{}) (string, error) {
+	code, err := randomURLToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	loginHandoffMu.Lock()
+	defer loginHandoffMu.Unlock()
+
+	now := time.Now()
+	for k, handoff := range loginHandoffs {
+		if now.After(handoff.expiresAt) {
+			delete(loginHandoffs, k)
+		}
+	}
+	loginHandoffs[code] = loginHandoff{result: result, expiresAt: now.Add(loginHandoffTTL)}
+
+	return code, nil
+}
+
+// RedeemLoginHandoff returns the result stored under code. Each code works once.
+func RedeemLoginHandoff(code string) (map[string]interface{}, error) {
+	loginHandoffMu.Lock()
+	handoff, ok := loginHandoffs[code]
+	delete(loginHandoffs, code)
+	loginHandoffMu.Unlock()
+
+	if !ok || time.Now().After(handoff.expiresAt) {
+		return nil, errors.New("login code is invalid or has expired, please log in again")
+	}
+	return handoff.result, nil
+}
+
+func randomURLToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginHandoffIsSingleUse(t *testing.T) {
+	code, err := StoreLoginHandoff(map[string]interface{}{"token": "access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RedeemLoginHandoff(code)
+	if err != nil {
+		t.Fatalf("redeem: %v", err)
+	}
+	if result["token"] != "access" {
+		t.Fatalf("result = %v", result)
+	}
+
+	if _, err := RedeemLoginHandoff(code); err == nil {
+		t.Fatal("a login code was redeemed twice")
+	}
+}
+
+func TestLoginHandoffExpires(t *testing.T) {
+	code, err := StoreLoginHandoff(map[string]interface{}{"token": "access"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loginHandoffMu.Lock()
+	handoff := loginHandoffs[code]
+	handoff.expiresAt = time.Now().Add(-time.Second)
+	loginHandoffs[code] = handoff
+	loginHandoffMu.Unlock()
+
+	if _, err := RedeemLoginHandoff(code); err == nil {
+		t.Fatal("an expired login code was accepted")
+	}
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"encoding/binary"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// How long the browser has to answer a registration or login challenge
+	passkeyCeremonyTTL = 5 * time.Minute
+	// MaxPasskeysPerUser caps how many authenticators one account can register
+	MaxPasskeysPerUser = 10
+)
+
+// ErrPasskeysDisabled is returned when WEBAUTHN_RP_ID/WEBAUTHN_RP_ORIGINS are not set
+var ErrPasskeysDisabled = errors.New("passkey login is not configured")
+
+var errPasskeyLoginFailed = errors.New("passkey login failed")
+
+var webAuthn *webauthn.WebAuthn
+
+// Challenges waiting for the authenticator's answer, keyed by ceremony ID
+var (
+	passkeyCeremoniesMu sync.Mutex
+	passkeyCeremonies   = make(map[string]passkeyCeremony)
+)
+
+type passkeyCeremony struct {
+	userID    uint // 0 for a login, where the user isn't known until the answer
+	session   webauthn.SessionData
+	expiresAt time.Time
+}
+
+// InitWebAuthn sets up the passkey relying party from config. Passkeys stay
+// disabled when it isn't configured.
+func InitWebAuthn() {
+	webAuthn = nil
+	if config.WebAuthnRPID == "" || len(config.WebAuthnRPOrigins) == 0 {
+		log.Println("⚠️ Passkeys disabled: set WEBAUTHN_RP_ID and WEBAUTHN_RP_ORIGINS to enable them")
+		return
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:                  config.WebAuthnRPID,
+		RPDisplayName:         config.WebAuthnRPName,
+		RPOrigins:             config.WebAuthnRPOrigins,
+		AttestationPreference: protocol.PreferNoAttestation,
+		// Discoverable credentials so login needs no email, and user verification
+		// (PIN or biometric) so a passkey counts as both factors
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:        protocol.ResidentKeyRequirementRequired,
+			RequireResidentKey: protocol.ResidentKeyRequired(),
+			UserVerification:   protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Enforce: true, Timeout: passkeyCeremonyTTL},
+		},
+	})
+	if err != nil {
+		log.Fatalf("❌ Invalid passkey configuration: %v", err)
+	}
+
+	webAuthn = w
+	log.Printf("✅ Passkeys enabled for %s", config.WebAuthnRPID)
+}
+
+// webAuthnUser adapts an account and its stored passkeys to webauthn.User
+type webAuthnUser struct {
+	user        models.User
+	credentials []models.WebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte          { return passkeyUserHandle(u.user.ID) }
+func (u *webAuthnUser) WebAuthnName() string        { return u.user.Email }
+func (u *webAuthnUser) WebAuthnDisplayName() string { return u.user.Username }
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, c := range u.credentials {
+		var transports []protocol.AuthenticatorTransport
+		for _, t := range strings.Split(c.Transports, ",") {
+			if t != "" {
+				transports = append(transports, protocol.AuthenticatorTransport(t))
+			}
+		}
+		credentials = append(credentials, webauthn.Credential{
+			ID:              c.CredentialID,
+			PublicKey:       c.PublicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				UserPresent:    true,
+				UserVerified:   true,
+				BackupEligible: c.BackupEligible,
+				BackupState:    c.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{AAGUID: c.AAGUID, SignCount: c.SignCount},
+		})
+	}
+	return credentials
+}
+
+// credential returns the stored passkey with the given credential ID
+func (u *webAuthnUser) credential(id []byte) *models.WebAuthnCredential {
+	for i := range u.credentials {
+		if string(u.credentials[i].CredentialID) == string(id) {
+			return &u.credentials[i]
+		}
+	}
+	return nil
+}
+
+// The user handle stored on the authenticator is the account ID, so it
+// doesn't reveal the email and survives email changes
+func passkeyUserHandle(userID uint) []byte {
+	handle := make([]byte, 8)
+	binary.BigEndian.PutUint64(handle, uint64(userID))
+	return handle
+}
+
+func loadWebAuthnUser(userID uint) (*webAuthnUser, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	var credentials []models.WebAuthnCredential
+	if err := database.DB.Where("user_id = ?", userID).Find(&credentials).Error; err != nil {
+		return nil, errors.New("database error")
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+func storePasskeyCeremony(userID uint, session *webauthn.SessionData) (string, error) {
+	id, err := randomURLToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	passkeyCeremoniesMu.Lock()
+	defer passkeyCeremoniesMu.Unlock()
+
+	now := time.Now()
+	for k, ceremony := range passkeyCeremonies {
+		if now.After(ceremony.expiresAt) {
+			delete(passkeyCeremonies, k)
+		}
+	}
+	passkeyCeremonies[id] = passkeyCeremony{
+		userID:    userID,
+		session:   *session,
+		expiresAt: now.Add(passkeyCeremonyTTL),
+	}
+
+	return id, nil
+}
+
+// takePasskeyCeremony returns a pending challenge once; each can be answered a single time
+func takePasskeyCeremony(id string, userID uint) (*webauthn.SessionData, error) {
+	passkeyCeremoniesMu.Lock()
+	ceremony, ok := passkeyCeremonies[id]
+	delete(passkeyCeremonies, id)
+	passkeyCeremoniesMu.Unlock()
+
+	if !ok || time.Now().After(ceremony.expiresAt) || ceremony.userID != userID {
+		return nil, errors.New("passkey request is invalid or has expired, please try again")
+	}
+	return &ceremony.session, nil
+}
+
+// BeginPasskeyRegistration starts adding a passkey to the user's account. The
+// returned options go to navigator.credentials.create() in the browser.
+func BeginPasskeyRegistration(userID uint) (*protocol.CredentialCreation, string, error) {
+	if webAuthn == nil {
+		return nil, "", ErrPasskeysDisabled
+	}
+
+	user, err := loadWebAuthnUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(user.credentials) >= MaxPasskeysPerUser {
+		return nil, "", errors.New("you have registered the maximum number of passkeys")
+	}
+
+	// Stop the same authenticator from being registered twice
+	var exclusions []protocol.CredentialDescriptor
+	for _, c := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	options, session, err := webAuthn.BeginRegistration(user, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		log.Printf("❌ Failed to start passkey registration: %v", err)
+		return nil, "", errors.New("failed to start passkey registration")
+	}
+
+	ceremonyID, err := storePasskeyCeremony(userID, session)
+	if err != nil {
+		return nil, "", err
+	}
+	return options, ceremonyID, nil
+}
+
+// FinishPasskeyRegistration verifies the authenticator's answer and stores the new passkey
+func FinishPasskeyRegistration(userID uint, ceremonyID, name string, response []byte) (*models.WebAuthnCredential, error) {
+	if webAuthn == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	session, err := takePasskeyCeremony(ceremonyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, errors.New("invalid passkey response")
+	}
+
+	user, err := loadWebAuthnUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.credentials) >= MaxPasskeysPerUser {
+		return nil, errors.New("you have registered the maximum number of passkeys")
+	}
+
+	credential, err := webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		log.Printf("⚠️ Passkey registration rejected for user %d: %v", userID, err)
+		return nil, errors.New("passkey could not be verified")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Passkey"
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, t := range credential.Transport {
+		transports = append(transports, string(t))
+	}
+
+	stored := models.WebAuthnCredential{
+		UserID:          userID,
+		Name:            truncate(name, 100),
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: truncate(credential.AttestationType, 32),
+		AAGUID:          credential.Authenticator.AAGUID,
+		Transports:      truncate(strings.Join(transports, ","), 100),
+		SignCount:       credential.Authenticator.SignCount,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := database.DB.Create(&stored).Error; err != nil {
+		// Unique credential ID: the authenticator is already registered
+		return nil, errors.New("this passkey is already registered")
+	}
+
+	log.Printf("✅ Passkey registered for user %d", userID)
+	return &stored, nil
+}
+
+// BeginPasskeyLogin starts a passwordless login. The browser lets the user pick
+// any passkey for this site, so no email is needed.
+func BeginPasskeyLogin() (*protocol.CredentialAssertion, string, error) {
+	if webAuthn == nil {
+		return nil, "", ErrPasskeysDisabled
+	}
+
+	options, session, err := webAuthn.BeginDiscoverableLogin()
+	if err != nil {
+		log.Printf("❌ Failed to start passkey login: %v", err)
+		return nil, "", errors.New("failed to start passkey login")
+	}
+
+	ceremonyID, err := storePasskeyCeremony(0, session)
+	if err != nil {
+		return nil, "", err
+	}
+	return options, ceremonyID, nil
+}
+
+// FinishPasskeyLogin verifies the authenticator's signature and returns the
+// account it belongs to. User verification is required by the ceremony, so
+// the caller can start a session without a separate two-factor step.
+func FinishPasskeyLogin(ceremonyID string, response []byte, info LoginAttemptInfo) (*models.User, error) {
+	if webAuthn == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	session, err := takePasskeyCeremony(ceremonyID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, errors.New("invalid passkey response")
+	}
+
+	var owner *webAuthnUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		if len(userHandle) != 8 {
+			return nil, errors.New("unknown user handle")
+		}
+		user, err := loadWebAuthnUser(uint(binary.BigEndian.Uint64(userHandle)))
+		if err != nil {
+			return nil, err
+		}
+		owner = user
+		return user, nil
+	}
+
+	credential, err := webAuthn.ValidateDiscoverableLogin(handler, *session, parsed)
+	if err != nil {
+		if owner != nil {
+			recordLoginAttempt(&owner.user, owner.user.Email, info, models.LoginInvalidPasskey)
+		}
+		log.Printf("⚠️ Passkey login rejected: %v", err)
+		return nil, errPasskeyLoginFailed
+	}
+
+	stored := owner.credential(credential.ID)
+	if stored == nil {
+		return nil, errPasskeyLoginFailed
+	}
+
+	if credential.Authenticator.CloneWarning {
+		recordLoginAttempt(&owner.user, owner.user.Email, info, models.LoginInvalidPasskey)
+		log.Printf("⚠️ Passkey %d of user %d reported a stale sign counter, possible cloned authenticator", stored.ID, owner.user.ID)
+		return nil, errors.New("this passkey can't be used, remove it and register it again")
+	}
+
+	// Only advance from the counter we validated against, so a cloned
+	// authenticator racing the real one can't both succeed
+	now := time.Now()
+	result := database.DB.Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", stored.ID, stored.SignCount).
+		Updates(map[string]interface{}{
+			"sign_count":   credential.Authenticator.SignCount,
+			"backup_state": credential.Flags.BackupState,
+			"last_used_at": now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errPasskeyLoginFailed
+	}
+
+	return &owner.user, nil
+}
+
+// ListPasskeys returns the passkeys registered to the user
+func ListPasskeys(userID uint) ([]models.WebAuthnCredential, error) {
+	var credentials []models.WebAuthnCredential
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&credentials).Error; err != nil {
+		return nil, errors.New("failed to fetch passkeys")
+	}
+	return credentials, nil
+}
+
+// DeletePasskey removes a passkey, refusing to remove the account's last way to log in
+func DeletePasskey(userID, passkeyID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+
+		var credential models.WebAuthnCredential
+		if err := tx.Where("id = ? AND user_id = ?", passkeyID, userID).First(&credential).Error; err != nil {
+			return errors.New("passkey not found")
+		}
+
+		methods, err := countLoginMethods(tx, user)
+		if err != nil {
+			return err
+		}
+		if methods <= 1 {
+			return errLastLoginMethod
+		}
+
+		if err := tx.Delete(&credential).Error; err != nil {
+			return errors.New("failed to remove passkey")
+		}
+
+		log.Printf("✅ Removed passkey %d from user %d", passkeyID, userID)
+		return nil
+	})
+}
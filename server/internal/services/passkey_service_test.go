@@ -0,0 +1,283 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+)
+
+const (
+	testRPID     = "localhost"
+	testRPOrigin = "http://localhost:5173"
+)
+
+// softAuthenticator is a software passkey: a P-256 key with a sign counter,
+// answering ceremonies the way a browser would pass them on
+type softAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	rand.Read(id)
+	return &softAuthenticator{t: t, key: key, credentialID: id}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (a *softAuthenticator) clientData(ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	data, err := json.Marshal(map[string]interface{}{
+		"type":      ceremony,
+		"challenge": challenge.String(),
+		"origin":    testRPOrigin,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return data
+}
+
+// authData builds authenticator data with user presence and verification
+// flags, plus the attested credential when attested is set
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	flags := byte(protocol.FlagUserPresent | protocol.FlagUserVerified)
+	if attested {
+		flags |= byte(protocol.FlagAttestedCredentialData)
+	}
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if !attested {
+		return data
+	}
+
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1, // P-256
+		XCoord: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	data = append(data, make([]byte, 16)...) // AAGUID
+	data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+	data = append(data, a.credentialID...)
+	return append(data, publicKey...)
+}
+
+// register answers navigator.credentials.create() with a "none" attestation
+func (a *softAuthenticator) register(options *protocol.CredentialCreation) []byte {
+	a.userHandle = options.Response.User.ID.(protocol.URLEncodedBase64)
+
+	attestation, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(true),
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return a.marshal(map[string]interface{}{
+		"clientDataJSON":    b64(a.clientData("webauthn.create", options.Response.Challenge)),
+		"attestationObject": b64(attestation),
+		"transports":        []string{"internal"},
+	})
+}
+
+// login answers navigator.credentials.get() after bumping the sign counter
+func (a *softAuthenticator) login(options *protocol.CredentialAssertion) []byte {
+	a.signCount++
+	return a.assert(options)
+}
+
+// assert signs the challenge with the current counter as is
+func (a *softAuthenticator) assert(options *protocol.CredentialAssertion) []byte {
+	authData := a.authData(false)
+	clientData := a.clientData("webauthn.get", options.Response.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatal(err)
+	}
+
+	return a.marshal(map[string]interface{}{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64(a.userHandle),
+	})
+}
+
+func (a *softAuthenticator) marshal(response map[string]interface{}) []byte {
+	data, err := json.Marshal(map[string]interface{}{
+		"id":                      b64(a.credentialID),
+		"rawId":                   b64(a.credentialID),
+		"type":                    "public-key",
+		"authenticatorAttachment": "platform",
+		"clientExtensionResults":  map[string]interface{}{},
+		"response":                response,
+	})
+	if err != nil {
+		a.t.Fatal(err)
+	}
+	return data
+}
+
+func setupPasskeys(t *testing.T) {
+	t.Helper()
+	newTestDB(t)
+
+	config.WebAuthnRPID = testRPID
+	config.WebAuthnRPName = "SocialApp"
+	config.WebAuthnRPOrigins = []string{testRPOrigin}
+	InitWebAuthn()
+	t.Cleanup(func() {
+		config.WebAuthnRPID = ""
+		config.WebAuthnRPOrigins = nil
+		webAuthn = nil
+	})
+}
+
+func registerPasskey(t *testing.T, userID uint, authenticator *softAuthenticator) *models.WebAuthnCredential {
+	t.Helper()
+
+	options, ceremonyID, err := BeginPasskeyRegistration(userID)
+	if err != nil {
+		t.Fatalf("begin registration: %v", err)
+	}
+	stored, err := FinishPasskeyRegistration(userID, ceremonyID, "Laptop", authenticator.register(options))
+	if err != nil {
+		t.Fatalf("finish registration: %v", err)
+	}
+	return stored
+}
+
+func loginWithPasskey(t *testing.T, answer func(*protocol.CredentialAssertion) []byte) (*models.User, error) {
+	t.Helper()
+
+	options, ceremonyID, err := BeginPasskeyLogin()
+	if err != nil {
+		t.Fatalf("begin login: %v", err)
+	}
+	return FinishPasskeyLogin(ceremonyID, answer(options), LoginAttemptInfo{IPAddress: "127.0.0.1"})
+}
+
+func TestPasskeyRegisterAndLogin(t *testing.T) {
+	setupPasskeys(t)
+	user := createTestUser(t, "alice")
+	authenticator := newSoftAuthenticator(t)
+
+	stored := registerPasskey(t, user.ID, authenticator)
+	if stored.Name != "Laptop" || string(stored.CredentialID) != string(authenticator.credentialID) {
+		t.Fatalf("stored passkey = %+v", stored)
+	}
+
+	for i := 1; i <= 2; i++ {
+		loggedIn, err := loginWithPasskey(t, authenticator.login)
+		if err != nil {
+			t.Fatalf("login %d: %v", i, err)
+		}
+		if loggedIn.ID != user.ID {
+			t.Fatalf("login %d returned user %d, want %d", i, loggedIn.ID, user.ID)
+		}
+	}
+
+	var credential models.WebAuthnCredential
+	database.DB.First(&credential, stored.ID)
+	if credential.SignCount != 2 || credential.LastUsedAt == nil {
+		t.Fatalf("after two logins sign_count = %d, last_used_at = %v", credential.SignCount, credential.LastUsedAt)
+	}
+}
+
+func TestPasskeyRegistrationRejectsReusedCeremony(t *testing.T) {
+	setupPasskeys(t)
+	user := createTestUser(t, "alice")
+	authenticator := newSoftAuthenticator(t)
+
+	options, ceremonyID, err := BeginPasskeyRegistration(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := authenticator.register(options)
+	if _, err := FinishPasskeyRegistration(user.ID, ceremonyID, "", response); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FinishPasskeyRegistration(user.ID, ceremonyID, "", response); err == nil {
+		t.Fatal("a registration challenge was accepted twice")
+	}
+}
+
+func TestPasskeyLoginRejectsStaleSignCounter(t *testing.T) {
+	setupPasskeys(t)
+	user := createTestUser(t, "alice")
+	authenticator := newSoftAuthenticator(t)
+	stored := registerPasskey(t, user.ID, authenticator)
+
+	authenticator.signCount = 4
+	if _, err := loginWithPasskey(t, authenticator.login); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	// A clone of the authenticator still at an older counter
+	tests := []struct {
+		name      string
+		signCount uint32
+	}{
+		{"same counter", 5},
+		{"lower counter", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator.signCount = tt.signCount
+			if _, err := loginWithPasskey(t, authenticator.assert); err == nil {
+				t.Fatal("login with a stale sign counter succeeded")
+			}
+
+			var credential models.WebAuthnCredential
+			database.DB.First(&credential, stored.ID)
+			if credential.SignCount != 5 {
+				t.Fatalf("sign_count = %d after a rejected login, want 5", credential.SignCount)
+			}
+		})
+	}
+
+	var failed int64
+	database.DB.Model(&models.LoginAttempt{}).Where("user_id = ? AND reason = ?", user.ID, models.LoginInvalidPasskey).Count(&failed)
+	if failed != int64(len(tests)) {
+		t.Fatalf("recorded %d failed passkey logins, want %d", failed, len(tests))
+	}
+}
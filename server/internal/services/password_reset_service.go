@@ -2,6 +2,7 @@ package services
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"fmt"
 	"log"
 	"math/big"
@@ -18,6 +19,16 @@ const (
 	CodeExpirationMinutes = 15
 	CodeLength            = 6
 	MaxAttempts           = 5
+
+	// Reset emails allowed per address and per client IP within ResetRequestWindow
+	ResetRequestsPerEmail = 3
+	ResetRequestsPerIP    = 10
+	ResetRequestWindow    = time.Hour
+)
+
+var (
+	resetEmailThrottle = newRequestThrottle(ResetRequestsPerEmail, ResetRequestWindow)
+	resetIPThrottle    = newRequestThrottle(ResetRequestsPerIP, ResetRequestWindow)
 )
 
 // GenerateOTP creates a secure 6-digit code
@@ -35,10 +46,21 @@ func GenerateOTP() (string, error) {
 	return code, nil
 }
 
-// InitiatePasswordReset generates OTP and sends email
-func InitiatePasswordReset(email string) error {
+// InitiatePasswordReset generates OTP and sends email.
+// Returns ErrTooManyRequests when the email or IP is over its request limit.
+func InitiatePasswordReset(email, ip string) error {
 	log.Printf("🔄 Initiating password reset for: %s", email)
 
+	// Throttle before the user lookup so the limit doesn't reveal whether the email exists
+	if !resetIPThrottle.Allow(ip) {
+		log.Printf("⚠️ Password reset throttled for IP: %s", ip)
+		return ErrTooManyRequests
+	}
+	if !resetEmailThrottle.Allow(email) {
+		log.Printf("⚠️ Password reset throttled for email: %s", email)
+		return ErrTooManyRequests
+	}
+
 	// Check if user exists
 	var user models.User
 	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
@@ -101,18 +123,20 @@ func InitiatePasswordReset(email string) error {
 	return nil
 }
 
-// VerifyResetCode validates the OTP code
+// VerifyResetCode validates the OTP code against the latest active reset for email.
+// Each wrong guess counts against the code, which is locked after MaxAttempts.
 func VerifyResetCode(email, code string) (*models.PasswordReset, error) {
 	log.Printf("🔄 Verifying reset code for: %s", email)
 
 	var reset models.PasswordReset
 
-	err := database.DB.Where("email = ? AND code = ? AND used = false", email, code).
+	err := database.DB.Where("email = ? AND used = false", email).
+		Order("created_at DESC").
 		First(&reset).Error
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			log.Printf("❌ Invalid or already used code for: %s", email)
+			log.Printf("❌ No active code for: %s", email)
 			return nil, fmt.Errorf("invalid or expired code")
 		}
 		log.Printf("❌ Database error: %v", err)
@@ -125,6 +149,28 @@ func VerifyResetCode(email, code string) (*models.PasswordReset, error) {
 		return nil, fmt.Errorf("code has expired")
 	}
 
+	// Take an attempt in the same statement that checks the limit, so
+	// parallel guesses can't all slip under it. A correct code gives it back.
+	result := database.DB.Model(&models.PasswordReset{}).
+		Where("id = ? AND attempts < ?", reset.ID, MaxAttempts).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		log.Printf("❌ Database error: %v", result.Error)
+		return nil, fmt.Errorf("database error: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		log.Printf("❌ Code locked after %d attempts for: %s", MaxAttempts, email)
+		return nil, fmt.Errorf("too many attempts, request a new code")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(reset.Code), []byte(code)) != 1 {
+		log.Printf("❌ Invalid code for: %s", email)
+		return nil, fmt.Errorf("invalid or expired code")
+	}
+
+	database.DB.Model(&models.PasswordReset{}).Where("id = ?", reset.ID).
+		UpdateColumn("attempts", gorm.Expr("attempts - 1"))
+
 	log.Printf("✅ Code verified successfully for: %s (created: %v, expires: %v)",
 		email, reset.CreatedAt, reset.ExpiresAt)
 	return &reset, nil
@@ -134,15 +180,14 @@ func VerifyResetCode(email, code string) (*models.PasswordReset, error) {
 func ResetPassword(email, code, newPassword string) error {
 	log.Printf("🔄 Resetting password for: %s", email)
 
-	// Verify the code first
-	reset, err := VerifyResetCode(email, code)
-	if err != nil {
+	// Check the new password first so a rejected password doesn't use up an attempt
+	if err := validatePasswordStrength(newPassword); err != nil {
 		return err
 	}
 
-	// Validate password strength (basic validation)
-	if len(newPassword) < 6 {
-		return fmt.Errorf("password must be at least 6 characters long")
+	reset, err := VerifyResetCode(email, code)
+	if err != nil {
+		return err
 	}
 
 	// Hash new password
@@ -169,9 +214,13 @@ func ResetPassword(email, code, newPassword string) error {
 
 	// Update user password
 	log.Printf("🔄 Updating user password in database...")
+	// Also enables password login for accounts created through a provider
 	result := tx.Model(&models.User{}).
 		Where("email = ?", email).
-		Update("password", string(hashedPassword))
+		Updates(map[string]interface{}{
+			"password":     string(hashedPassword),
+			"has_password": true,
+		})
 
 	if result.Error != nil {
 		tx.Rollback()
@@ -187,11 +236,19 @@ func ResetPassword(email, code, newPassword string) error {
 
 	// Mark code as used
 	log.Printf("🔄 Marking reset code as used...")
-	if err := tx.Model(&reset).Update("used", true).Error; err != nil {
+	marked := tx.Model(&models.PasswordReset{}).
+		Where("id = ? AND used = false", reset.ID).
+		Update("used", true)
+	if err := marked.Error; err != nil {
 		tx.Rollback()
 		log.Printf("❌ Failed to mark code as used: %v", err)
 		return fmt.Errorf("failed to mark code as used: %w", err)
 	}
+	if marked.RowsAffected == 0 {
+		// A concurrent reset already used this code
+		tx.Rollback()
+		return fmt.Errorf("invalid or expired code")
+	}
 
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
@@ -199,11 +256,23 @@ func ResetPassword(email, code, newPassword string) error {
 		return fmt.Errorf("transaction failed: %w", err)
 	}
 
+	// Log out every device and revoke access tokens; whoever had access
+	// before the reset loses it
+	var user models.User
+	if err := database.DB.Select("id").Where("email = ?", email).First(&user).Error; err == nil {
+		if err := RevokeAllSessions(user.ID, 0); err != nil {
+			log.Printf("⚠️ Warning: Could not revoke sessions: %v", err)
+		}
+		if err := RevokeAllAccessTokens(user.ID); err != nil {
+			log.Printf("⚠️ Warning: Could not revoke access tokens: %v", err)
+		}
+	}
+
 	log.Printf("✅ Password reset successfully for: %s", email)
 	return nil
 }
 
-// CleanupExpiredCodes removes old reset codes (run as cron job)
+// CleanupExpiredCodes removes old reset codes (see StartCleanupJobs)
 func CleanupExpiredCodes() error {
 	log.Printf("🔄 Cleaning up expired password reset codes...")
 
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+// createTestReset stores an active reset code for email
+func createTestReset(t *testing.T, email, code string) *models.PasswordReset {
+	t.Helper()
+
+	reset := models.PasswordReset{
+		Email:     email,
+		Code:      code,
+		ExpiresAt: time.Now().Add(CodeExpirationMinutes * time.Minute),
+		CreatedAt: time.Now(),
+	}
+	if err := database.DB.Create(&reset).Error; err != nil {
+		t.Fatalf("create reset code: %v", err)
+	}
+	return &reset
+}
+
+func TestVerifyResetCodeLockout(t *testing.T) {
+	tests := []struct {
+		name         string
+		wrongGuesses int
+		code         string
+		wantErr      string
+		wantAttempts int
+	}{
+		{name: "right code first time", code: "123456", wantAttempts: 0},
+		{name: "wrong code", code: "000000", wantErr: "invalid or expired code", wantAttempts: 1},
+		{name: "right code on the last attempt", wrongGuesses: MaxAttempts - 1, code: "123456", wantAttempts: MaxAttempts - 1},
+		{name: "right code after lockout", wrongGuesses: MaxAttempts, code: "123456", wantErr: "too many attempts", wantAttempts: MaxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			reset := createTestReset(t, "alice@example.com", "123456")
+
+			for i := 0; i < tt.wrongGuesses; i++ {
+				if _, err := VerifyResetCode("alice@example.com", "000000"); err == nil {
+					t.Fatal("a wrong code was accepted")
+				}
+			}
+
+			_, err := VerifyResetCode("alice@example.com", tt.code)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("VerifyResetCode: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("VerifyResetCode() error = %v, want %q", err, tt.wantErr)
+			}
+
+			var stored models.PasswordReset
+			database.DB.First(&stored, reset.ID)
+			if stored.Attempts != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", stored.Attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestVerifyResetCodeParallelGuesses(t *testing.T) {
+	newTestDB(t)
+	reset := createTestReset(t, "alice@example.com", "123456")
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		guessed int
+	)
+	for i := 0; i < 4*MaxAttempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := VerifyResetCode("alice@example.com", fmt.Sprintf("%06d", i))
+			if err != nil && strings.Contains(err.Error(), "invalid or expired code") {
+				mu.Lock()
+				guessed++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if guessed > MaxAttempts {
+		t.Fatalf("%d guesses were checked, want at most %d", guessed, MaxAttempts)
+	}
+	var stored models.PasswordReset
+	database.DB.First(&stored, reset.ID)
+	if stored.Attempts != MaxAttempts {
+		t.Fatalf("attempts = %d, want %d", stored.Attempts, MaxAttempts)
+	}
+}
+
+func TestInitiatePasswordResetThrottle(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		request func(i int) error
+	}{
+		// Unknown addresses skip the email, so nothing is sent
+		{name: "per email", limit: ResetRequestsPerEmail, request: func(i int) error {
+			return InitiatePasswordReset("nobody@example.com", fmt.Sprintf("10.0.0.%d", i))
+		}},
+		{name: "per IP", limit: ResetRequestsPerIP, request: func(i int) error {
+			return InitiatePasswordReset(fmt.Sprintf("nobody%d@example.com", i), "10.0.0.1")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			previousEmail, previousIP := resetEmailThrottle, resetIPThrottle
+			t.Cleanup(func() { resetEmailThrottle, resetIPThrottle = previousEmail, previousIP })
+			resetEmailThrottle = newRequestThrottle(ResetRequestsPerEmail, ResetRequestWindow)
+			resetIPThrottle = newRequestThrottle(ResetRequestsPerIP, ResetRequestWindow)
+
+			for i := 0; i < tt.limit; i++ {
+				if err := tt.request(i); err != nil {
+					t.Fatalf("request %d: %v", i+1, err)
+				}
+			}
+			if err := tt.request(tt.limit); !errors.Is(err, ErrTooManyRequests) {
+				t.Fatalf("request over the limit: error = %v, want ErrTooManyRequests", err)
+			}
+		})
+	}
+}
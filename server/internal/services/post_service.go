@@ -5,12 +5,15 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
 	"mime/multipart"
 	"strings"
+	"time"
 
 	"github.com/Bauka07/SocialApp/internal/config"
 	"github.com/Bauka07/SocialApp/internal/database"
 	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/policy"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"gorm.io/gorm"
 )
@@ -20,6 +23,7 @@ func GetAllPostsWithStats(currentUserID uint) ([]map[string]interface{}, error)
 	var posts []models.Post
 
 	if err := database.DB.
+		Scopes(visiblePosts(currentUserID)).
 		Preload("User").
 		Order("created_at DESC").
 		Find(&posts).Error; err != nil {
@@ -33,14 +37,11 @@ func GetAllPostsWithStats(currentUserID uint) ([]map[string]interface{}, error)
 		likesCount, _ := GetLikesCount(post.ID)
 
 		// Get comments count
-		commentsCount, _ := GetCommentsCount(post.ID)
+		commentsCount, _ := GetCommentsCount(post.ID, currentUserID)
 
 		// Check if current user liked
 		isLiked, _ := IsPostLikedByUser(currentUserID, post.ID)
 
-		// Clear password
-		post.User.Password = ""
-
 		result[i] = map[string]interface{}{
 			"id":             post.ID,
 			"title":          post.Title,
@@ -49,7 +50,8 @@ func GetAllPostsWithStats(currentUserID uint) ([]map[string]interface{}, error)
 			"created_at":     post.CreatedAt,
 			"updated_at":     post.UpdatedAt,
 			"user_id":        post.UserID,
-			"user":           post.User,
+			"visibility":     post.Visibility,
+			"user":           models.PostAuthorOf(post.User),
 			"likes_count":    likesCount,
 			"comments_count": commentsCount,
 			"is_liked":       isLiked,
@@ -59,15 +61,31 @@ func GetAllPostsWithStats(currentUserID uint) ([]map[string]interface{}, error)
 	return result, nil
 }
 
-// GetUserPostsWithStats gets user posts with stats
-func GetUserPostsWithStats(userID, currentUserID uint) ([]map[string]interface{}, error) {
+// GetUserPostsWithStats gets user posts with stats, newest first. Authors see
+// all of their posts, including hidden ones; anyone else sees what
+// visiblePosts allows. A pageSize of 0 returns every post.
+func GetUserPostsWithStats(userID, currentUserID uint, page, pageSize int) ([]map[string]interface{}, bool, error) {
 	var posts []models.Post
 
-	if err := database.DB.Where("user_id = ?", userID).
+	db := database.DB
+	if userID != currentUserID {
+		db = db.Scopes(visiblePosts(currentUserID))
+	}
+	if pageSize > 0 {
+		// One extra row tells whether there's another page
+		db = db.Offset(page * pageSize).Limit(pageSize + 1)
+	}
+
+	if err := db.Where("posts.user_id = ?", userID).
 		Preload("User").
 		Order("created_at DESC").
 		Find(&posts).Error; err != nil {
-		return nil, errors.New("failed to fetch posts")
+		return nil, false, errors.New("failed to fetch posts")
+	}
+
+	hasMore := pageSize > 0 && len(posts) > pageSize
+	if hasMore {
+		posts = posts[:pageSize]
 	}
 
 	// Build response with stats
@@ -77,14 +95,11 @@ func GetUserPostsWithStats(userID, currentUserID uint) ([]map[string]interface{}
 		likesCount, _ := GetLikesCount(post.ID)
 
 		// Get comments count
-		commentsCount, _ := GetCommentsCount(post.ID)
+		commentsCount, _ := GetCommentsCount(post.ID, currentUserID)
 
 		// Check if current user liked
 		isLiked, _ := IsPostLikedByUser(currentUserID, post.ID)
 
-		// Clear password
-		post.User.Password = ""
-
 		result[i] = map[string]interface{}{
 			"id":             post.ID,
 			"title":          post.Title,
@@ -93,18 +108,21 @@ func GetUserPostsWithStats(userID, currentUserID uint) ([]map[string]interface{}
 			"created_at":     post.CreatedAt,
 			"updated_at":     post.UpdatedAt,
 			"user_id":        post.UserID,
-			"user":           post.User,
+			"visibility":     post.Visibility,
+			"user":           models.PostAuthorOf(post.User),
 			"likes_count":    likesCount,
 			"comments_count": commentsCount,
 			"is_liked":       isLiked,
+			"is_hidden":      post.HiddenAt != nil || post.HeldAt != nil,
 		}
 	}
 
-	return result, nil
+	return result, hasMore, nil
 }
 
 // CreatePost creates a new post
-func CreatePost(userID uint, title, content string) (*models.Post, error) {
+// visibility defaults to public when empty.
+func CreatePost(userID uint, title, content, visibility string) (*models.Post, error) {
 	db := database.DB
 
 	// Validate input
@@ -123,17 +141,38 @@ func CreatePost(userID uint, title, content string) (*models.Post, error) {
 		return nil, errors.New("title must not exceed 200 characters")
 	}
 
+	if visibility == "" {
+		visibility = models.PostPublic
+	}
+	if !models.IsValidPostVisibility(visibility) {
+		return nil, errors.New("invalid visibility")
+	}
+
+	held, err := filterContent(filterContentPost, userID, &title, &content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create post (ImageURL will be empty by default)
 	post := models.Post{
-		Title:   title,
-		Content: content,
-		UserID:  userID,
+		Title:      title,
+		Content:    content,
+		UserID:     userID,
+		Visibility: visibility,
+	}
+	if held != nil {
+		now := time.Now()
+		post.HeldAt = &now
 	}
 
 	if err := db.Create(&post).Error; err != nil {
 		return nil, errors.New("failed to create post")
 	}
 
+	if held != nil {
+		holdForReview(filterContentPost, post.ID, userID, held)
+	}
+
 	// Load user data
 	db.Preload("User").First(&post, post.ID)
 
@@ -145,6 +184,7 @@ func GetAllPosts() ([]models.Post, error) {
 	var posts []models.Post
 
 	if err := database.DB.
+		Scopes(visiblePosts(0)).
 		Preload("User").
 		Order("created_at DESC").
 		Find(&posts).Error; err != nil {
@@ -173,13 +213,13 @@ func GetUserPosts(userID uint) ([]models.Post, error) {
 	return posts, nil
 }
 
-// GetPostByID gets a single post by ID
-func GetPostByID(postID uint) (*models.Post, error) {
+// GetPostByID gets a single post by ID as seen by viewerID (0 when anonymous)
+func GetPostByID(postID, viewerID uint) (*models.Post, error) {
 	var post models.Post
 
-	if err := database.DB.Preload("User").First(&post, postID).Error; err != nil {
+	if err := database.DB.Scopes(visiblePosts(viewerID)).Preload("User").First(&post, postID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, ErrPostNotFound
 		}
 		return nil, errors.New("failed to fetch post")
 	}
@@ -199,8 +239,11 @@ func UpdatePost(postID, userID uint, title, content string) (*models.Post, error
 		return nil, errors.New("failed to fetch post")
 	}
 
-	// Check ownership
-	if post.UserID != userID {
+	actor, err := loadActor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanEditPost(actor, &post) {
 		return nil, errors.New("you don't have permission to edit this post")
 	}
 
@@ -220,20 +263,65 @@ func UpdatePost(postID, userID uint, title, content string) (*models.Post, error
 		return nil, errors.New("title must not exceed 200 characters")
 	}
 
+	held, err := filterContent(filterContentPost, post.UserID, &title, &content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update
 	post.Title = title
 	post.Content = content
+	if held != nil && post.HeldAt == nil {
+		now := time.Now()
+		post.HeldAt = &now
+	}
 
 	if err := database.DB.Save(&post).Error; err != nil {
 		return nil, errors.New("failed to update post")
 	}
 
+	if held != nil {
+		holdForReview(filterContentPost, post.ID, post.UserID, held)
+	}
+
 	// Reload with user
 	database.DB.Preload("User").First(&post, post.ID)
 
 	return &post, nil
 }
 
+// SetPostVisibility changes who can see a post. Every read path checks the
+// setting, so the change applies from the next request on.
+func SetPostVisibility(postID, userID uint, visibility string) (*models.Post, error) {
+	if !models.IsValidPostVisibility(visibility) {
+		return nil, errors.New("invalid visibility")
+	}
+
+	var post models.Post
+	if err := database.DB.First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("post not found")
+		}
+		return nil, errors.New("failed to fetch post")
+	}
+
+	actor, err := loadActor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !policy.CanChangePostVisibility(actor, &post) {
+		return nil, errors.New("only the author can change who sees a post")
+	}
+
+	if err := database.DB.Model(&post).Update("visibility", visibility).Error; err != nil {
+		return nil, errors.New("failed to update post visibility")
+	}
+
+	database.DB.Preload("User").First(&post, post.ID)
+
+	return &post, nil
+}
+
 // DeletePost deletes a post
 func DeletePost(postID, userID uint) error {
 	var post models.Post
@@ -246,8 +334,11 @@ func DeletePost(postID, userID uint) error {
 		return errors.New("failed to fetch post")
 	}
 
-	// Check ownership
-	if post.UserID != userID {
+	actor, err := loadActor(userID)
+	if err != nil {
+		return err
+	}
+	if !policy.CanDeletePost(actor, &post) {
 		return errors.New("you don't have permission to delete this post")
 	}
 
@@ -264,6 +355,10 @@ func DeletePost(postID, userID uint) error {
 		return errors.New("failed to delete post")
 	}
 
+	if policy.IsModeratorAction(actor, post.UserID) {
+		log.Printf("🛡️ Moderator %d removed post %d by user %d", actor.ID, post.ID, post.UserID)
+	}
+
 	return nil
 }
 
@@ -278,7 +373,11 @@ func UpdatePostImage(postID, userID uint, file multipart.File) (string, error) {
 		return "", errors.New("failed to fetch post")
 	}
 
-	if post.UserID != userID {
+	actor, err := loadActor(userID)
+	if err != nil {
+		return "", err
+	}
+	if !policy.CanEditPost(actor, &post) {
 		return "", errors.New("you don't have permission to edit this post")
 	}
 
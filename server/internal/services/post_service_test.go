@@ -0,0 +1,127 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestSetPostVisibility(t *testing.T) {
+	newTestDB(t)
+	author := createTestUser(t, "author")
+	other := createTestUser(t, "other")
+	moderator := createTestUser(t, "mod")
+	database.DB.Model(moderator).Update("role", models.RoleModerator)
+
+	post := models.Post{Title: "title", Content: "content", UserID: author.ID, Visibility: models.PostPublic}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		actorID    uint
+		visibility string
+		wantErr    bool
+	}{
+		{name: "author", actorID: author.ID, visibility: models.PostCloseFriends},
+		{name: "another user", actorID: other.ID, visibility: models.PostPublic, wantErr: true},
+		{name: "moderator", actorID: moderator.ID, visibility: models.PostPublic, wantErr: true},
+		{name: "unknown visibility", actorID: author.ID, visibility: "everyone", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SetPostVisibility(post.ID, tt.actorID, tt.visibility)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetPostVisibility() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var stored models.Post
+	database.DB.First(&stored, post.ID)
+	if stored.Visibility != models.PostCloseFriends {
+		t.Fatalf("visibility = %q, want %q", stored.Visibility, models.PostCloseFriends)
+	}
+}
+
+func TestSetPostVisibilityTrimsAuthor(t *testing.T) {
+	newTestDB(t)
+	author := createTestUser(t, "author")
+	post := models.Post{Title: "title", Content: "content", UserID: author.ID, Visibility: models.PostPublic}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := SetPostVisibility(post.ID, author.ID, models.PostFollowers)
+	if err != nil {
+		t.Fatalf("SetPostVisibility: %v", err)
+	}
+	assertPublicAuthor(t, updated)
+}
+
+// assertPublicAuthor fails when a serialized post carries more of its author
+// than the public profile fields
+func assertPublicAuthor(t *testing.T, post *models.Post) {
+	t.Helper()
+
+	data, err := json.Marshal(post)
+	if err != nil {
+		t.Fatalf("marshal post: %v", err)
+	}
+	var body struct {
+		User map[string]interface{} `json:"user"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		t.Fatalf("unmarshal post: %v", err)
+	}
+
+	if body.User["username"] != post.User.Username {
+		t.Fatalf("author username = %v, want %q", body.User["username"], post.User.Username)
+	}
+	for _, field := range []string{"password", "email", "pending_email", "role", "has_password", "two_factor_enabled"} {
+		if _, ok := body.User[field]; ok {
+			t.Fatalf("post author exposes %q", field)
+		}
+	}
+}
+
+func TestPostResponsesTrimAuthor(t *testing.T) {
+	newTestDB(t)
+	author := createTestUser(t, "author")
+	viewer := createTestUser(t, "viewer")
+	post := models.Post{Title: "title", Content: "content", UserID: author.ID, Visibility: models.PostPublic}
+	if err := database.DB.Create(&post).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		fetch func() (*models.Post, error)
+	}{
+		{name: "anonymous lookup", fetch: func() (*models.Post, error) { return GetPostByID(post.ID, 0) }},
+		{name: "signed-in lookup", fetch: func() (*models.Post, error) { return GetPostByID(post.ID, viewer.ID) }},
+		{name: "update", fetch: func() (*models.Post, error) { return UpdatePost(post.ID, author.ID, "new title", "new content") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fetch()
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			assertPublicAuthor(t, got)
+		})
+	}
+
+	posts, err := GetAllPostsWithStats(viewer.ID)
+	if err != nil {
+		t.Fatalf("GetAllPostsWithStats: %v", err)
+	}
+	if _, ok := posts[0]["user"].(models.PostAuthor); !ok {
+		t.Fatalf("feed post author is %T, want models.PostAuthor", posts[0]["user"])
+	}
+}
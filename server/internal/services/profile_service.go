@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// ErrUserNotFound is returned for accounts that don't exist or that the
+// viewer can't see
+var ErrUserNotFound = errors.New("user not found")
+
+// reservedUsernames are the static routes under /users. Public profiles live
+// at /users/:username, so an account with one of these names could never be
+// looked up.
+var reservedUsernames = map[string]bool{
+	"register": true, "login": true, "logout": true, "me": true, "update": true,
+	"email": true, "password": true, "upload-image": true, "upload-cover": true,
+	"profile": true, "sessions": true, "login-history": true, "security-events": true,
+	"identities": true, "tokens": true, "passkeys": true, "2fa": true, "reports": true,
+	"privacy": true, "follow-requests": true, "followers": true, "close-friends": true,
+	"blocked": true, "muted": true,
+}
+
+// IsReservedUsername reports whether username is taken by a /users route
+func IsReservedUsername(username string) bool {
+	return reservedUsernames[strings.ToLower(username)]
+}
+
+// PublicProfile is what others see of an account. It never includes the email.
+type PublicProfile struct {
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	Location      string    `json:"location"`
+	Website       string    `json:"website"`
+	ImageURL      string    `json:"image_url"`
+	CoverImageURL string    `json:"cover_image_url"`
+	IsPrivate     bool      `json:"is_private"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	PostsCount     int64 `json:"posts_count"`
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+	LikesCount     int64 `json:"likes_count"` // likes received on their posts
+
+	// FollowStatus is the viewer's follow of this account: "", pending or accepted
+	FollowStatus string `json:"follow_status"`
+	// CanViewContent is false for a private account the viewer doesn't
+	// follow; only the basic profile is shown then
+	CanViewContent bool `json:"can_view_content"`
+}
+
+// ProfileDetails holds the profile fields to change; nil ones are left alone
+// and empty strings clear them
+type ProfileDetails struct {
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	Location    *string `json:"location"`
+	Website     *string `json:"website"`
+}
+
+// GetPublicProfile returns username's profile as seen by viewerID (0 when
+// anonymous). Shadow-banned accounts and accounts on the other side of a
+// block are reported as not found.
+func GetPublicProfile(viewerID uint, username string) (*PublicProfile, error) {
+	var user models.User
+	if err := database.DB.Scopes(VisibleUsers(viewerID)).
+		Where("username = ? AND banned_at IS NULL", username).
+		First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	profile := &PublicProfile{
+		ID:             user.ID,
+		Username:       user.Username,
+		DisplayName:    user.DisplayName,
+		Bio:            user.Bio,
+		Location:       user.Location,
+		Website:        user.Website,
+		ImageURL:       user.ImageURL,
+		CoverImageURL:  user.CoverImageURL,
+		IsPrivate:      user.IsPrivate,
+		CreatedAt:      user.CreatedAt,
+		CanViewContent: CanViewContent(viewerID, user.ID),
+	}
+
+	db := database.DB
+	db.Model(&models.Post{}).Scopes(visiblePosts(viewerID)).Where("posts.user_id = ?", user.ID).Count(&profile.PostsCount)
+	db.Model(&models.Follow{}).Where("following_id = ? AND status = ?", user.ID, models.FollowAccepted).Count(&profile.FollowersCount)
+	db.Model(&models.Follow{}).Where("follower_id = ? AND status = ?", user.ID, models.FollowAccepted).Count(&profile.FollowingCount)
+	db.Model(&models.Like{}).
+		Joins("JOIN posts ON posts.id = likes.post_id AND posts.deleted_at IS NULL").
+		Where("posts.user_id = ? AND posts.hidden_at IS NULL AND posts.held_at IS NULL", user.ID).
+		Count(&profile.LikesCount)
+
+	if viewerID != 0 && viewerID != user.ID {
+		var follow models.Follow
+		if err := db.Where("follower_id = ? AND following_id = ?", viewerID, user.ID).First(&follow).Error; err == nil {
+			profile.FollowStatus = follow.Status
+		}
+	}
+
+	return profile, nil
+}
+
+// UpdateProfileDetails changes the display name, bio, location and website
+func UpdateProfileDetails(userID uint, details ProfileDetails) (*models.User, error) {
+	updates := map[string]interface{}{}
+
+	if details.DisplayName != nil {
+		name := strings.TrimSpace(*details.DisplayName)
+		if len(name) > 50 {
+			return nil, errors.New("display name must not exceed 50 characters")
+		}
+		updates["display_name"] = name
+	}
+	if details.Bio != nil {
+		bio := strings.TrimSpace(*details.Bio)
+		if len(bio) > 300 {
+			return nil, errors.New("bio must not exceed 300 characters")
+		}
+		updates["bio"] = bio
+	}
+	if details.Location != nil {
+		location := strings.TrimSpace(*details.Location)
+		if len(location) > 100 {
+			return nil, errors.New("location must not exceed 100 characters")
+		}
+		updates["location"] = location
+	}
+	if details.Website != nil {
+		website := strings.TrimSpace(*details.Website)
+		if website != "" {
+			if len(website) > 255 {
+				return nil, errors.New("website must not exceed 255 characters")
+			}
+			u, err := url.Parse(website)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, errors.New("website must be an http or https URL")
+			}
+		}
+		updates["website"] = website
+	}
+
+	if len(updates) == 0 {
+		return nil, errors.New("no changes to save")
+	}
+
+	if err := database.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
+		return nil, errors.New("failed to save changes")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+	user.Password = ""
+	return &user, nil
+}
+
+// UploadCoverImage uploads a profile cover image to Cloudinary
+func UploadCoverImage(file multipart.File, fileName string) (string, error) {
+	ctx := context.Background()
+
+	uploadResult, err := config.Cloud.Upload.Upload(ctx, file, uploader.UploadParams{
+		Folder:         "socialapp_covers",
+		PublicID:       fileName,
+		Transformation: "c_fill,h_500,w_1500", // Wide banner crop
+		Format:         "jpg",
+		AllowedFormats: []string{"jpg", "png", "jpeg", "webp"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return uploadResult.SecureURL, nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import "testing"
+
+func TestReservedUsernames(t *testing.T) {
+	tests := []struct {
+		username string
+		reserved bool
+	}{
+		{username: "blocked", reserved: true},
+		{username: "Sessions", reserved: true},
+		{username: "me", reserved: true},
+		{username: "alice", reserved: false},
+		{username: "blocked_user", reserved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			if got := IsReservedUsername(tt.username); got != tt.reserved {
+				t.Fatalf("IsReservedUsername(%q) = %v, want %v", tt.username, got, tt.reserved)
+			}
+		})
+	}
+}
+
+func TestReservedUsernameCantBeTaken(t *testing.T) {
+	newTestDB(t)
+	user := createTestUser(t, "alice")
+
+	if _, err := UpdateUserProfile(user.ID, "blocked", ""); err == nil {
+		t.Fatal("renamed to a reserved username")
+	}
+	if name, err := uniqueUsername("blocked"); err != nil || IsReservedUsername(name) {
+		t.Fatalf("uniqueUsername(blocked) = %q, %v", name, err)
+	}
+}
@@ -0,0 +1,542 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/utils"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrNotModerator   = errors.New("you don't have permission to moderate content")
+)
+
+// ReasonInfo describes a report category for the report dialog
+type ReasonInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ListReportReasons returns every report category, sorted by name
+func ListReportReasons() []ReasonInfo {
+	reasons := make([]ReasonInfo, 0, len(models.ReportReasons))
+	for name, description := range models.ReportReasons {
+		reasons = append(reasons, ReasonInfo{Name: name, Description: description})
+	}
+	sort.Slice(reasons, func(i, j int) bool { return reasons[i].Name < reasons[j].Name })
+	return reasons
+}
+
+// CreateReport files a report against a post, comment, message or account.
+// Each user can report the same thing once.
+func CreateReport(reporterID uint, targetType string, targetID uint, reason, details string) (*models.Report, error) {
+	if _, ok := models.ReportReasons[reason]; !ok {
+		return nil, errors.New("unknown report reason")
+	}
+
+	targetUserID, err := reportTargetOwner(reporterID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if targetUserID == reporterID {
+		return nil, errors.New("you can't report yourself")
+	}
+
+	var existing int64
+	database.DB.Model(&models.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ?", reporterID, targetType, targetID).
+		Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("you have already reported this")
+	}
+
+	report := models.Report{
+		ReporterID:   reporterID,
+		TargetType:   targetType,
+		TargetID:     targetID,
+		TargetUserID: targetUserID,
+		Reason:       reason,
+		Details:      truncate(strings.TrimSpace(details), 1000),
+		Status:       models.ReportOpen,
+	}
+	if err := database.DB.Create(&report).Error; err != nil {
+		return nil, errors.New("failed to create report")
+	}
+
+	log.Printf("🚩 User %d reported %s %d (%s)", reporterID, targetType, targetID, reason)
+	return &report, nil
+}
+
+// reportTargetOwner checks the reported thing exists and the reporter can
+// see it, and returns the ID of the user responsible for it
+func reportTargetOwner(reporterID uint, targetType string, targetID uint) (uint, error) {
+	switch targetType {
+	case models.ReportTargetPost:
+		var post models.Post
+		if err := database.DB.Select("id, user_id").First(&post, targetID).Error; err != nil {
+			return 0, errors.New("post not found")
+		}
+		return post.UserID, nil
+
+	case models.ReportTargetComment:
+		var comment models.Comment
+		if err := database.DB.Select("id, user_id").First(&comment, targetID).Error; err != nil {
+			return 0, errors.New("comment not found")
+		}
+		return comment.UserID, nil
+
+	case models.ReportTargetMessage:
+		// Only the recipient of a message can report it
+		var message models.Message
+		if err := database.DB.
+			Where("id = ? AND receiver_id = ? AND deleted_for_receiver = ? AND hidden_at IS NULL", targetID, reporterID, false).
+			First(&message).Error; err != nil {
+			return 0, ErrMessageNotFound
+		}
+		return message.SenderID, nil
+
+	case models.ReportTargetUser:
+		var user models.User
+		if err := database.DB.Select("id").First(&user, targetID).Error; err != nil {
+			return 0, errors.New("user not found")
+		}
+		return user.ID, nil
+	}
+
+	return 0, errors.New("unknown report target")
+}
+
+// ListMyReports returns the reports the user has filed, newest first
+func ListMyReports(reporterID uint) ([]models.Report, error) {
+	var reports []models.Report
+	if err := database.DB.Where("reporter_id = ?", reporterID).Order("created_at DESC").Find(&reports).Error; err != nil {
+		return nil, errors.New("failed to fetch reports")
+	}
+	return reports, nil
+}
+
+// ReportQueueFilter narrows the moderation queue. Assignee is "me",
+// "unassigned" or empty for everyone.
+type ReportQueueFilter struct {
+	Status     string
+	TargetType string
+	Assignee   string
+}
+
+// ListReports returns the moderation queue, oldest first so nothing waits forever
+func ListReports(actorID uint, filter ReportQueueFilter, page, pageSize int) ([]models.Report, int64, error) {
+	db := database.DB.Model(&models.Report{})
+
+	switch filter.Status {
+	case "":
+		db = db.Where("status = ?", models.ReportOpen)
+	case models.ReportOpen, models.ReportActioned, models.ReportDismissed:
+		db = db.Where("status = ?", filter.Status)
+	case "all":
+	default:
+		return nil, 0, errors.New("unknown status filter")
+	}
+
+	if filter.TargetType != "" {
+		db = db.Where("target_type = ?", filter.TargetType)
+	}
+
+	switch filter.Assignee {
+	case "":
+	case "me":
+		db = db.Where("assignee_id = ?", actorID)
+	case "unassigned":
+		db = db.Where("assignee_id IS NULL")
+	default:
+		return nil, 0, errors.New("unknown assignee filter")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count reports")
+	}
+
+	var reports []models.Report
+	if err := db.Order("created_at ASC").Offset(page * pageSize).Limit(pageSize).Find(&reports).Error; err != nil {
+		return nil, 0, errors.New("failed to fetch reports")
+	}
+	return reports, total, nil
+}
+
+// ReportDetails is a report with the reported content and how many others
+// reported the same thing
+type ReportDetails struct {
+	models.Report
+	Target       map[string]interface{} `json:"target"`
+	ReportCount  int64                  `json:"report_count"`
+	TargetStatus string                 `json:"target_status"`
+}
+
+// GetReport returns a report with a snapshot of the reported content
+func GetReport(reportID uint) (*ReportDetails, error) {
+	var report models.Report
+	if err := database.DB.First(&report, reportID).Error; err != nil {
+		return nil, ErrReportNotFound
+	}
+
+	details := ReportDetails{Report: report, Target: map[string]interface{}{}, TargetStatus: "active"}
+	database.DB.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ?", report.TargetType, report.TargetID).
+		Count(&details.ReportCount)
+
+	switch report.TargetType {
+	case models.ReportTargetPost:
+		var post models.Post
+		if err := database.DB.Unscoped().First(&post, report.TargetID).Error; err == nil {
+			details.Target = map[string]interface{}{"title": post.Title, "content": post.Content, "image_url": post.ImageURL.String}
+			if post.DeletedAt.Valid {
+				details.TargetStatus = "deleted"
+			} else if post.HiddenAt != nil {
+				details.TargetStatus = "hidden"
+			} else if post.HeldAt != nil {
+				details.TargetStatus = "held"
+			}
+		} else {
+			details.TargetStatus = "deleted"
+		}
+
+	case models.ReportTargetComment:
+		var comment models.Comment
+		if err := database.DB.First(&comment, report.TargetID).Error; err == nil {
+			details.Target = map[string]interface{}{"content": comment.Content, "post_id": comment.PostID}
+			if comment.HiddenAt != nil {
+				details.TargetStatus = "hidden"
+			} else if comment.HeldAt != nil {
+				details.TargetStatus = "held"
+			}
+		} else {
+			details.TargetStatus = "deleted"
+		}
+
+	case models.ReportTargetMessage:
+		var message models.Message
+		if err := database.DB.First(&message, report.TargetID).Error; err == nil {
+			details.Target = map[string]interface{}{"content": message.Content, "sender_id": message.SenderID, "receiver_id": message.ReceiverID}
+			if message.DeletedForReceiver {
+				details.TargetStatus = "deleted"
+			}
+		} else {
+			details.TargetStatus = "deleted"
+		}
+	}
+
+	var user models.User
+	if err := database.DB.Select("id, username, image_url, suspended_until, banned_at").First(&user, report.TargetUserID).Error; err == nil {
+		details.Target["user"] = map[string]interface{}{"id": user.ID, "username": user.Username, "image_url": user.ImageURL}
+		if report.TargetType == models.ReportTargetUser {
+			switch {
+			case user.IsBanned():
+				details.TargetStatus = UserStatusBanned
+			case user.IsSuspended():
+				details.TargetStatus = UserStatusSuspended
+			}
+		}
+	}
+
+	return &details, nil
+}
+
+// loadModerator loads the actor and checks they can work the moderation queue
+func loadModerator(actorID uint) (*models.User, error) {
+	actor, err := loadActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.HasPermission(models.PermModerateContent) {
+		return nil, ErrNotModerator
+	}
+	return actor, nil
+}
+
+// AssignReport hands an open report to a moderator. A nil assigneeID
+// returns it to the unassigned queue.
+func AssignReport(actorID, reportID uint, assigneeID *uint) (*models.Report, error) {
+	actor, err := loadModerator(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var report models.Report
+	if err := database.DB.First(&report, reportID).Error; err != nil {
+		return nil, ErrReportNotFound
+	}
+	if !report.IsOpen() {
+		return nil, errors.New("report is already resolved")
+	}
+
+	if assigneeID != nil {
+		assignee, err := loadActor(*assigneeID)
+		if err != nil {
+			return nil, errors.New("assignee not found")
+		}
+		if !assignee.HasPermission(models.PermModerateContent) {
+			return nil, errors.New("assignee is not a moderator")
+		}
+	}
+
+	if err := database.DB.Model(&report).Update("assignee_id", assigneeID).Error; err != nil {
+		return nil, errors.New("failed to assign report")
+	}
+	report.AssigneeID = assigneeID
+
+	if assigneeID != nil {
+		log.Printf("🛡️ Moderator %d assigned report %d to %d", actor.ID, report.ID, *assigneeID)
+	} else {
+		log.Printf("🛡️ Moderator %d unassigned report %d", actor.ID, report.ID)
+	}
+	return &report, nil
+}
+
+// ResolveReportRequest is a moderator's decision on a report. Action is one
+// of the models.ReportAction* values, or "dismiss".
+type ResolveReportRequest struct {
+	Action        string `json:"action" binding:"required"`
+	Note          string `json:"note"`
+	DurationHours int    `json:"duration_hours"` // suspend_user only
+}
+
+// ReportActionDismiss closes a report without any action
+const ReportActionDismiss = "dismiss"
+
+// reportActionTargets lists which report targets each action applies to;
+// account actions apply to every target
+var reportActionTargets = map[string]string{
+	models.ReportActionHidePost:      models.ReportTargetPost,
+	models.ReportActionDeleteComment: models.ReportTargetComment,
+	models.ReportActionDeleteMessage: models.ReportTargetMessage,
+}
+
+// ResolveReport applies the moderator's decision to the reported content or
+// account, closes every open report about the same thing and notifies the
+// reporters. The caller disconnects a suspended user.
+func ResolveReport(notifier MessageNotifier, actorID, reportID uint, req ResolveReportRequest) (*models.Report, error) {
+	actor, err := loadModerator(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var report models.Report
+	if err := database.DB.First(&report, reportID).Error; err != nil {
+		return nil, ErrReportNotFound
+	}
+	if !report.IsOpen() {
+		return nil, errors.New("report is already resolved")
+	}
+
+	if target, ok := reportActionTargets[req.Action]; ok && target != report.TargetType {
+		return nil, errors.New("this action doesn't apply to a reported " + report.TargetType)
+	}
+
+	note := truncate(strings.TrimSpace(req.Note), 1000)
+	status := models.ReportActioned
+
+	switch req.Action {
+	case ReportActionDismiss:
+		status = models.ReportDismissed
+
+	case models.ReportActionNone:
+
+	case models.ReportActionApprove:
+		if err := approveHeldContent(notifier, &report); err != nil {
+			return nil, err
+		}
+		status = models.ReportDismissed
+		log.Printf("🛡️ Moderator %d approved %s %d by user %d", actor.ID, report.TargetType, report.TargetID, report.TargetUserID)
+
+	case models.ReportActionHidePost:
+		if err := database.DB.Model(&models.Post{}).
+			Where("id = ? AND hidden_at IS NULL", report.TargetID).
+			Update("hidden_at", time.Now()).Error; err != nil {
+			return nil, errors.New("failed to hide post")
+		}
+		log.Printf("🛡️ Moderator %d hid post %d by user %d", actor.ID, report.TargetID, report.TargetUserID)
+
+	case models.ReportActionDeleteComment:
+		if err := DeleteComment(report.TargetID, actor.ID); err != nil {
+			return nil, err
+		}
+
+	case models.ReportActionDeleteMessage:
+		if err := DeleteMessage(notifier, actor.ID, report.TargetID, "all"); err != nil {
+			return nil, err
+		}
+
+	case models.ReportActionWarnUser:
+		if err := warnUser(notifier, actor, &report, note); err != nil {
+			return nil, err
+		}
+
+	case models.ReportActionSuspendUser:
+		reason := note
+		if reason == "" {
+			reason = reportReasonLabel(report.Reason)
+		}
+		if _, err := SuspendReportedUser(actor.ID, report.TargetUserID, time.Duration(req.DurationHours)*time.Hour, reason); err != nil {
+			return nil, err
+		}
+
+	case models.ReportActionShadowBanUser:
+		reason := note
+		if reason == "" {
+			reason = reportReasonLabel(report.Reason)
+		}
+		if _, err := ShadowBanUser(actor.ID, report.TargetUserID, reason); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errors.New("unknown resolution action")
+	}
+
+	// Everyone who reported the same thing gets the same outcome
+	var related []models.Report
+	database.DB.Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportOpen).
+		Find(&related)
+
+	now := time.Now()
+	if err := database.DB.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, models.ReportOpen).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"resolution":      req.Action,
+			"resolution_note": note,
+			"resolved_by_id":  actor.ID,
+			"resolved_at":     now,
+		}).Error; err != nil {
+		return nil, errors.New("failed to resolve report")
+	}
+
+	for _, r := range related {
+		notifyReporter(notifier, &r, status)
+	}
+
+	log.Printf("🛡️ Moderator %d resolved report %d (%s %d) as %s: %s", actor.ID, report.ID, report.TargetType, report.TargetID, status, req.Action)
+
+	database.DB.First(&report, report.ID)
+	return &report, nil
+}
+
+// reportReasonLabel describes a report reason for the reported user
+func reportReasonLabel(reason string) string {
+	if label, ok := models.ReportReasons[reason]; ok {
+		return label
+	}
+	return "Flagged by our content filter"
+}
+
+// approveHeldContent publishes content a filter rule held for review. A held
+// message is delivered to its receiver now. A post or comment a moderator
+// also hid stays hidden.
+func approveHeldContent(notifier MessageNotifier, report *models.Report) error {
+	switch report.TargetType {
+	case models.ReportTargetPost:
+		return database.DB.Model(&models.Post{}).Where("id = ?", report.TargetID).Update("held_at", nil).Error
+
+	case models.ReportTargetComment:
+		return database.DB.Model(&models.Comment{}).Where("id = ?", report.TargetID).Update("held_at", nil).Error
+
+	case models.ReportTargetMessage:
+		var message models.Message
+		if err := database.DB.First(&message, report.TargetID).Error; err != nil {
+			return ErrMessageNotFound
+		}
+		if message.HiddenAt == nil {
+			return nil
+		}
+		if err := database.DB.Model(&message).Update("hidden_at", nil).Error; err != nil {
+			return errors.New("failed to approve message")
+		}
+
+		if IsShadowBanned(message.SenderID) {
+			return nil
+		}
+
+		database.DB.Preload("Sender").Preload("Receiver").Preload("ReplyTo").First(&message, message.ID)
+		sendEvent(notifier, message.ReceiverID, map[string]interface{}{
+			"type":    "new_message",
+			"message": message,
+		})
+		return nil
+	}
+
+	return errors.New("only posts, comments and messages can be approved")
+}
+
+// warnUser emails the author of reported content and pushes a warning to
+// their open connections
+func warnUser(notifier MessageNotifier, actor *models.User, report *models.Report, note string) error {
+	var user models.User
+	if err := database.DB.Select("id, username, email").First(&user, report.TargetUserID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	reason := reportReasonLabel(report.Reason)
+	go func(to, username string) {
+		if err := utils.SendAccountWarningEmail(to, username, reason, note); err != nil {
+			log.Printf("❌ Failed to send warning email: %v", err)
+		}
+	}(user.Email, user.Username)
+
+	sendEvent(notifier, user.ID, map[string]interface{}{
+		"type":   "account_warning",
+		"reason": report.Reason,
+		"note":   note,
+	})
+
+	log.Printf("🛡️ Moderator %d warned user %d over report %d", actor.ID, user.ID, report.ID)
+	return nil
+}
+
+// notifyReporter tells a reporter their report was handled, by email so they
+// hear about it even when offline, and live on their open connections. Which
+// action was taken stays private; the reporter only learns whether it was
+// actioned.
+func notifyReporter(notifier MessageNotifier, report *models.Report, status string) {
+	if report.ReporterID == 0 {
+		// Filed by a content filter
+		return
+	}
+
+	var reporter models.User
+	if err := database.DB.Select("id, username, email").First(&reporter, report.ReporterID).Error; err == nil {
+		go func(to, username, targetType string) {
+			if err := utils.SendReportResolvedEmail(to, username, targetType, status == models.ReportActioned); err != nil {
+				log.Printf("❌ Failed to send report resolved email: %v", err)
+			}
+		}(reporter.Email, reporter.Username, report.TargetType)
+	}
+
+	sendEvent(notifier, report.ReporterID, map[string]interface{}{
+		"type":        "report_resolved",
+		"report_id":   report.ID,
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+		"status":      status,
+	})
+}
+
+// sendEvent delivers a single event to every connection of userID
+func sendEvent(notifier MessageNotifier, userID uint, event map[string]interface{}) {
+	if notifier == nil {
+		return
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error marshaling notification: %v", err)
+		return
+	}
+	notifier.SendToUser(userID, eventJSON)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestModeratorSuspendsFromReport(t *testing.T) {
+	newTestDB(t)
+	moderator := createTestUser(t, "mod")
+	database.DB.Model(moderator).Update("role", models.RoleModerator)
+	reporter := createTestUser(t, "reporter")
+	author := createTestUser(t, "author")
+
+	report, err := CreateReport(reporter.ID, models.ReportTargetUser, author.ID, "spam", "")
+	if err != nil {
+		t.Fatalf("create report: %v", err)
+	}
+
+	// Moderators can't use the admin endpoint directly
+	if _, err := SuspendUser(moderator.ID, author.ID, MinSuspension, "spam"); err == nil {
+		t.Fatal("a moderator suspended a user outside the moderation queue")
+	}
+
+	resolved, err := ResolveReport(nil, moderator.ID, report.ID, ResolveReportRequest{
+		Action:        models.ReportActionSuspendUser,
+		DurationHours: 24,
+	})
+	if err != nil {
+		t.Fatalf("resolve report: %v", err)
+	}
+	if resolved.Status != models.ReportActioned {
+		t.Fatalf("report status = %q, want %q", resolved.Status, models.ReportActioned)
+	}
+
+	var stored models.User
+	database.DB.First(&stored, author.ID)
+	if !stored.IsSuspended() {
+		t.Fatal("the reported user was not suspended")
+	}
+}
+
+func TestApprovingHoldKeepsModeratorHide(t *testing.T) {
+	tests := []struct {
+		name       string
+		hidden     bool
+		wantHidden bool
+	}{
+		{name: "held only", hidden: false, wantHidden: false},
+		{name: "held and hidden by a moderator", hidden: true, wantHidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			moderator := createTestUser(t, "mod")
+			database.DB.Model(moderator).Update("role", models.RoleModerator)
+			author := createTestUser(t, "author")
+
+			now := time.Now()
+			post := models.Post{Title: "title", Content: "content", UserID: author.ID, Visibility: models.PostPublic, HeldAt: &now}
+			if tt.hidden {
+				post.HiddenAt = &now
+			}
+			if err := database.DB.Create(&post).Error; err != nil {
+				t.Fatal(err)
+			}
+			holdForReview(filterContentPost, post.ID, author.ID, &models.FilterRule{ID: 1, Kind: models.FilterKindWord, Pattern: "content"})
+
+			var report models.Report
+			if err := database.DB.Where("target_type = ? AND target_id = ?", models.ReportTargetPost, post.ID).First(&report).Error; err != nil {
+				t.Fatalf("held post wasn't queued: %v", err)
+			}
+
+			if _, err := ResolveReport(nil, moderator.ID, report.ID, ResolveReportRequest{Action: models.ReportActionApprove}); err != nil {
+				t.Fatalf("approve: %v", err)
+			}
+
+			var stored models.Post
+			database.DB.First(&stored, post.ID)
+			if stored.HeldAt != nil {
+				t.Fatal("the post is still held after approval")
+			}
+			if (stored.HiddenAt != nil) != tt.wantHidden {
+				t.Fatalf("hidden = %v, want %v", stored.HiddenAt != nil, tt.wantHidden)
+			}
+		})
+	}
+}
+
+func TestApprovingCommentHoldKeepsModeratorHide(t *testing.T) {
+	tests := []struct {
+		name       string
+		hidden     bool
+		wantHidden bool
+	}{
+		{name: "held only", hidden: false, wantHidden: false},
+		{name: "held and hidden by a moderator", hidden: true, wantHidden: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			moderator := createTestUser(t, "mod")
+			database.DB.Model(moderator).Update("role", models.RoleModerator)
+			author := createTestUser(t, "author")
+
+			post := models.Post{Title: "title", Content: "content", UserID: author.ID, Visibility: models.PostPublic}
+			if err := database.DB.Create(&post).Error; err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			comment := models.Comment{Content: "content", UserID: author.ID, PostID: post.ID, HeldAt: &now}
+			if tt.hidden {
+				comment.HiddenAt = &now
+			}
+			if err := database.DB.Create(&comment).Error; err != nil {
+				t.Fatal(err)
+			}
+			holdForReview(filterContentComment, comment.ID, author.ID, &models.FilterRule{ID: 1, Kind: models.FilterKindWord, Pattern: "content"})
+
+			var report models.Report
+			if err := database.DB.Where("target_type = ? AND target_id = ?", models.ReportTargetComment, comment.ID).First(&report).Error; err != nil {
+				t.Fatalf("held comment wasn't queued: %v", err)
+			}
+
+			if _, err := ResolveReport(nil, moderator.ID, report.ID, ResolveReportRequest{Action: models.ReportActionApprove}); err != nil {
+				t.Fatalf("approve: %v", err)
+			}
+
+			var stored models.Comment
+			database.DB.First(&stored, comment.ID)
+			if stored.HeldAt != nil {
+				t.Fatal("the comment is still held after approval")
+			}
+			if (stored.HiddenAt != nil) != tt.wantHidden {
+				t.Fatalf("hidden = %v, want %v", stored.HiddenAt != nil, tt.wantHidden)
+			}
+		})
+	}
+}
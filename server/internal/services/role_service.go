@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/policy"
+)
+
+// loadActor loads the user performing an action, with what policy checks need
+func loadActor(userID uint) (*models.User, error) {
+	var actor models.User
+	if err := database.DB.Select("id, username, role").First(&actor, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &actor, nil
+}
+
+// SetUserRole changes another user's role
+func SetUserRole(actorID, targetID uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, errors.New("unknown role")
+	}
+
+	actor, err := loadActor(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	var target models.User
+	if err := database.DB.First(&target, targetID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !policy.CanChangeRole(actor, &target) {
+		return nil, errors.New("you don't have permission to change this user's role")
+	}
+
+	if err := database.DB.Model(&target).Update("role", role).Error; err != nil {
+		return nil, errors.New("failed to update role")
+	}
+	target.Role = role
+
+	log.Printf("🛡️ User %d changed the role of user %d to %s", actor.ID, target.ID, role)
+	return &target, nil
+}
+
+// BootstrapAdmins gives the admin role to the accounts listed in ADMIN_EMAILS,
+// so a fresh deployment has someone who can assign roles. Only verified
+// addresses count, otherwise anyone could register a listed email before its
+// owner does and take the role.
+func BootstrapAdmins() error {
+	if len(config.AdminEmails) == 0 {
+		return nil
+	}
+
+	result := database.DB.Model(&models.User{}).
+		Where("LOWER(email) IN ? AND email_verified = ? AND role <> ?", config.AdminEmails, true, models.RoleAdmin).
+		Update("role", models.RoleAdmin)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ Granted admin role to %d account(s) from ADMIN_EMAILS: %s", result.RowsAffected, strings.Join(config.AdminEmails, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+)
+
+func TestBootstrapAdmins(t *testing.T) {
+	tests := []struct {
+		name      string
+		verified  bool
+		wantAdmin bool
+	}{
+		{name: "verified email", verified: true, wantAdmin: true},
+		{name: "unverified email", verified: false, wantAdmin: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			user := createTestUser(t, "owner")
+			database.DB.Model(user).Update("email_verified", tt.verified)
+
+			previous := config.AdminEmails
+			config.AdminEmails = []string{"owner@example.com"}
+			t.Cleanup(func() { config.AdminEmails = previous })
+
+			if err := BootstrapAdmins(); err != nil {
+				t.Fatalf("BootstrapAdmins: %v", err)
+			}
+
+			var stored models.User
+			database.DB.First(&stored, user.ID)
+			if isAdmin := stored.Role == models.RoleAdmin; isAdmin != tt.wantAdmin {
+				t.Fatalf("role = %q, want admin %v", stored.Role, tt.wantAdmin)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/config"
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+)
+
+// SessionTokens is the token pair returned on login and refresh
+type SessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int // access token lifetime in seconds
+	SessionID    uint
+}
+
+// StartSession creates a session for the user and issues an access/refresh token pair.
+// Every login ends here, so it also records the successful attempt in the login history.
+func StartSession(user models.User, userAgent, ip string) (*SessionTokens, error) {
+	// Every login path ends here, so restricted accounts can't get in through any of them
+	if err := middleware.CheckAccountStatus(&user); err != nil {
+		recordLoginAttempt(&user, user.Email, LoginAttemptInfo{IPAddress: ip, UserAgent: userAgent}, models.LoginAccountRestricted)
+		return nil, err
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hashToken(refreshToken),
+		Device:           describeDevice(userAgent),
+		UserAgent:        truncate(userAgent, 255),
+		IPAddress:        truncate(ip, 45),
+		LastUsedAt:       now,
+		ExpiresAt:        now.Add(config.RefreshTokenTTL),
+	}
+
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	accessToken, err := middleware.CreateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	recordLoginAttempt(&user, user.Email, LoginAttemptInfo{IPAddress: ip, UserAgent: userAgent}, models.LoginSucceeded)
+
+	return &SessionTokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(config.AccessTokenTTL.Seconds()),
+		SessionID:    session.ID,
+	}, nil
+}
+
+// RefreshSession rotates the refresh token and issues a new access token.
+// Presenting an already-rotated refresh token revokes the whole session,
+// since it means the token was copied.
+func RefreshSession(refreshToken, userAgent, ip string) (*SessionTokens, error) {
+	db := database.DB
+	hash := hashToken(refreshToken)
+
+	var session models.Session
+	if err := db.Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("failed to fetch session")
+		}
+
+		// Token reuse detection
+		if err := db.Where("previous_token_hash = ?", hash).First(&session).Error; err == nil {
+			log.Printf("⚠️ Refresh token reuse detected for session %d (user %d), revoking", session.ID, session.UserID)
+			revokeSessions(db.Where("id = ?", session.ID))
+			RecordAudit(models.AuditRefreshReused, AuditInfo{IPAddress: ip, UserAgent: userAgent}, session.UserID,
+				map[string]interface{}{"session_id": session.ID})
+		}
+		return nil, errors.New("invalid refresh token")
+	}
+
+	if !session.IsActive() {
+		return nil, errors.New("session expired or revoked")
+	}
+
+	var user models.User
+	if err := db.First(&user, session.UserID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if err := middleware.CheckAccountStatus(&user); err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	result := db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, hash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  hashToken(newRefreshToken),
+			"previous_token_hash": hash,
+			"user_agent":          truncate(userAgent, 255),
+			"ip_address":          truncate(ip, 45),
+			"last_used_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return nil, errors.New("failed to rotate refresh token")
+	}
+	if result.RowsAffected == 0 {
+		// Lost a race with a concurrent refresh using the same token
+		return nil, errors.New("invalid refresh token")
+	}
+
+	accessToken, err := middleware.CreateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SessionTokens{
+		AccessToken:  accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int(config.AccessTokenTTL.Seconds()),
+		SessionID:    session.ID,
+	}, nil
+}
+
+// ListSessions returns the user's active sessions, most recently used first
+func ListSessions(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	if err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, errors.New("failed to fetch sessions")
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes one of the user's sessions
+func RevokeSession(userID, sessionID uint) error {
+	result := revokeSessions(database.DB.Where("id = ? AND user_id = ?", sessionID, userID))
+	if result.Error != nil {
+		return errors.New("failed to revoke session")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// RevokeAllSessions logs the user out everywhere except exceptSessionID (0 = no exception)
+func RevokeAllSessions(userID, exceptSessionID uint) error {
+	query := database.DB.Where("user_id = ?", userID)
+	if exceptSessionID != 0 {
+		query = query.Where("id != ?", exceptSessionID)
+	}
+
+	result := revokeSessions(query)
+	if result.Error != nil {
+		return errors.New("failed to revoke sessions")
+	}
+
+	log.Printf("✅ Revoked %d sessions for user %d", result.RowsAffected, userID)
+	return nil
+}
+
+// revokeSessions marks the sessions matched by query as revoked
+func revokeSessions(query *gorm.DB) *gorm.DB {
+	return query.Model(&models.Session{}).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now())
+}
+
+// CleanupExpiredSessions removes sessions that can no longer be used (see StartCleanupJobs)
+func CleanupExpiredSessions() error {
+	result := database.DB.Where(
+		"expires_at < ? OR revoked_at < ?",
+		time.Now(),
+		time.Now().Add(-7*24*time.Hour),
+	).Delete(&models.Session{})
+
+	if result.Error != nil {
+		return fmt.Errorf("cleanup failed: %w", result.Error)
+	}
+	return nil
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// describeDevice builds a short human-readable label like "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	os := "Unknown OS"
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		os = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	}
+
+	if browser == "Unknown browser" && os == "Unknown OS" {
+		return truncate(userAgent, 100)
+	}
+	return browser + " on " + os
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
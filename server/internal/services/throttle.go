@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTooManyRequests is returned when a caller exceeds a request throttle
+var ErrTooManyRequests = errors.New("too many requests, please try again later")
+
+// requestThrottle allows at most limit hits per key within a sliding window.
+// State is in memory, so limits are per server instance.
+type requestThrottle struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	hits      map[string][]time.Time
+	lastSweep time.Time
+}
+
+func newRequestThrottle(limit int, window time.Duration) *requestThrottle {
+	return &requestThrottle{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// Allow records a hit for key and reports whether it is within the limit.
+// Rejected hits are not recorded.
+func (t *requestThrottle) Allow(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-t.window)
+
+	if now.Sub(t.lastSweep) > t.window {
+		for k, hits := range t.hits {
+			if len(hits) == 0 || hits[len(hits)-1].Before(cutoff) {
+				delete(t.hits, k)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	hits := t.hits[key]
+	for len(hits) > 0 && hits[0].Before(cutoff) {
+		hits = hits[1:]
+	}
+
+	if len(hits) >= t.limit {
+		t.hits[key] = hits
+		return false
+	}
+
+	t.hits[key] = append(hits, now)
+	return true
+}
@@ -0,0 +1,412 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/middleware"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	totpDigits        = 6
+	totpPeriod        = 30 // seconds
+	totpSkew          = 1  // accept one step before/after for clock drift
+	recoveryCodeCount = 10
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorSetup is returned when enrollment starts
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// BeginTwoFactorSetup generates a new TOTP secret for the user. It stays
+// pending until ConfirmTwoFactor succeeds.
+func BeginTwoFactorSetup(userID uint) (*TwoFactorSetup, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+	secret := base32NoPadding.EncodeToString(raw)
+
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"two_factor_secret":    secret,
+		"two_factor_last_step": 0,
+	}).Error; err != nil {
+		return nil, errors.New("failed to save secret")
+	}
+
+	return &TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: provisioningURI(user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables 2FA once the user proves their authenticator works,
+// and returns a fresh set of recovery codes (shown to the user only once)
+func ConfirmTwoFactor(userID uint, code string) ([]string, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, errors.New("start two-factor setup first")
+	}
+
+	if err := checkTOTP(&user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("two_factor_enabled", true).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, errors.New("failed to enable two-factor authentication")
+	}
+
+	log.Printf("✅ Two-factor authentication enabled for user %d", user.ID)
+	return codes, nil
+}
+
+// DisableTwoFactor turns 2FA off. Requires the account password and a current
+// TOTP or recovery code so a hijacked session alone cannot remove it. Accounts
+// created through Google or GitHub have no password, so for them the code is
+// the only proof.
+func DisableTwoFactor(userID uint, password, code string) error {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if user.HasPassword {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+			return errors.New("password is incorrect")
+		}
+	}
+
+	if err := VerifySecondFactor(&user, code); err != nil {
+		return err
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_factor_enabled":   false,
+			"two_factor_secret":    "",
+			"two_factor_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		return errors.New("failed to disable two-factor authentication")
+	}
+
+	log.Printf("✅ Two-factor authentication disabled for user %d", user.ID)
+	return nil
+}
+
+// RegenerateRecoveryCodes invalidates the old recovery codes and issues new ones
+func RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := checkTOTP(&user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, errors.New("failed to generate recovery codes")
+	}
+
+	return codes, nil
+}
+
+// VerifySecondFactor accepts either a TOTP code or an unused recovery code
+func VerifySecondFactor(user *models.User, code string) error {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if code == "" {
+		return errors.New("verification code is required")
+	}
+
+	if len(code) == totpDigits {
+		return checkTOTP(user, code)
+	}
+
+	return useRecoveryCode(user.ID, code)
+}
+
+// checkTOTP validates a code against the user's secret and records the time
+// step so the same code cannot be used twice
+func checkTOTP(user *models.User, code string) error {
+	step, ok := validateTOTP(user.TwoFactorSecret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return errors.New("invalid verification code")
+	}
+
+	// Conditional update wins only once per step, even with concurrent requests
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return errors.New("failed to verify code")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("verification code already used")
+	}
+
+	user.TwoFactorLastStep = step
+	return nil
+}
+
+func useRecoveryCode(userID uint, code string) error {
+	var codes []models.RecoveryCode
+	if err := database.DB.Where("user_id = ? AND used_at IS NULL", userID).Find(&codes).Error; err != nil {
+		return errors.New("failed to verify code")
+	}
+
+	normalized := strings.ToUpper(strings.ReplaceAll(code, "-", ""))
+	for _, rc := range codes {
+		if bcrypt.CompareHashAndPassword([]byte(rc.CodeHash), []byte(normalized)) != nil {
+			continue
+		}
+
+		result := database.DB.Model(&models.RecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", rc.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return errors.New("verification code already used")
+		}
+
+		log.Printf("⚠️ Recovery code used for user %d", userID)
+		return nil
+	}
+
+	return errors.New("invalid verification code")
+}
+
+// replaceRecoveryCodes deletes existing codes and stores new hashed ones
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		plain := base32NoPadding.EncodeToString(raw) // 8 characters
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(plain), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+
+		codes[i] = plain[:4] + "-" + plain[4:]
+		records[i] = models.RecoveryCode{UserID: userID, CodeHash: string(hash)}
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// validateTOTP checks code against the steps around now and returns the matching step
+func validateTOTP(secret, code string, now time.Time) (int64, bool) {
+	if secret == "" || len(code) != totpDigits {
+		return 0, false
+	}
+
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// totpCode computes the RFC 6238 code (HMAC-SHA1, 6 digits) for a time step
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// provisioningURI builds the otpauth:// URI authenticator apps read from a QR code
+func provisioningURI(accountName, secret string) string {
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = "SocialApp"
+	}
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprintf("%d", totpDigits))
+	params.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + accountName)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Failed code attempts per login challenge. A new challenge needs the password
+// again, so capping attempts per challenge bounds guessing.
+const maxChallengeAttempts = 5
+
+var (
+	challengeAttemptsMu sync.Mutex
+	challengeAttempts   = make(map[string]challengeAttempt)
+)
+
+type challengeAttempt struct {
+	count     int
+	expiresAt time.Time
+}
+
+// CreateLoginChallenge issues the short-lived token returned after a correct
+// password when the account has 2FA enabled
+func CreateLoginChallenge(user models.User) (string, error) {
+	return middleware.CreatePurposeToken(user, middleware.PurposeTwoFactor, middleware.ChallengeTokenTTL)
+}
+
+// CompleteLoginChallenge exchanges a challenge token and a TOTP/recovery code for the user.
+// Wrong codes count as failed logins for the account.
+func CompleteLoginChallenge(challengeToken, code string, info LoginAttemptInfo) (*models.User, error) {
+	claims, err := middleware.ParsePurposeToken(challengeToken, middleware.PurposeTwoFactor)
+	if err != nil {
+		return nil, errors.New("login challenge is invalid or has expired")
+	}
+
+	key := hashToken(challengeToken)
+	if !recordChallengeAttempt(key, claims.ExpiresAt.Time) {
+		return nil, errors.New("too many attempts, please log in again")
+	}
+
+	userID, err := claims.UserIDUint()
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := checkLoginAllowed(user.Email, info); err != nil {
+		recordLoginAttempt(&user, user.Email, info, models.LoginBlocked)
+		return nil, err
+	}
+
+	if err := VerifySecondFactor(&user, code); err != nil {
+		recordLoginFailure(&user, user.Email, info, models.LoginInvalidSecondStep)
+		return nil, err
+	}
+
+	challengeAttemptsMu.Lock()
+	// Challenge is spent; block reuse until it expires
+	challengeAttempts[key] = challengeAttempt{count: maxChallengeAttempts, expiresAt: claims.ExpiresAt.Time}
+	challengeAttemptsMu.Unlock()
+
+	return &user, nil
+}
+
+// recordChallengeAttempt counts an attempt and reports whether it is allowed
+func recordChallengeAttempt(key string, expiresAt time.Time) bool {
+	challengeAttemptsMu.Lock()
+	defer challengeAttemptsMu.Unlock()
+
+	now := time.Now()
+	for k, a := range challengeAttempts {
+		if now.After(a.expiresAt) {
+			delete(challengeAttempts, k)
+		}
+	}
+
+	attempt := challengeAttempts[key]
+	if attempt.count >= maxChallengeAttempts {
+		return false
+	}
+	attempt.count++
+	attempt.expiresAt = expiresAt
+	challengeAttempts[key] = attempt
+	return true
+}
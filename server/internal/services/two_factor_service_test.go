@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// enableTestTwoFactor turns 2FA on for user and returns the decoded TOTP key
+func enableTestTwoFactor(t *testing.T, user *models.User) []byte {
+	t.Helper()
+
+	setup, err := BeginTwoFactorSetup(user.ID)
+	if err != nil {
+		t.Fatalf("begin setup: %v", err)
+	}
+	key, err := base32NoPadding.DecodeString(setup.Secret)
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+
+	// Confirm with the previous step so the current one is still unused
+	previous := time.Now().Unix()/totpPeriod - 1
+	if _, err := ConfirmTwoFactor(user.ID, totpCode(key, previous)); err != nil {
+		t.Fatalf("confirm setup: %v", err)
+	}
+	return key
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		hasPassword bool
+		password    string
+		wrongCode   bool
+		wantErr     bool
+	}{
+		{name: "password account with password and code", hasPassword: true, password: "correct horse"},
+		{name: "password account with wrong password", hasPassword: true, password: "battery staple", wantErr: true},
+		{name: "password account without password", hasPassword: true, wantErr: true},
+		{name: "passwordless account with code only", hasPassword: false},
+		{name: "passwordless account with wrong code", hasPassword: false, wrongCode: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestDB(t)
+			user := createTestUser(t, "alice")
+			database.DB.Model(user).Updates(map[string]interface{}{
+				"password":     string(hash),
+				"has_password": tt.hasPassword,
+			})
+			key := enableTestTwoFactor(t, user)
+
+			code := totpCode(key, time.Now().Unix()/totpPeriod)
+			if tt.wrongCode {
+				code = "000000"
+				if totpCode(key, time.Now().Unix()/totpPeriod) == code {
+					code = "111111"
+				}
+			}
+
+			err := DisableTwoFactor(user.ID, tt.password, code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DisableTwoFactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var stored models.User
+			database.DB.First(&stored, user.ID)
+			if stored.TwoFactorEnabled == !tt.wantErr {
+				t.Fatalf("two_factor_enabled = %v after DisableTwoFactor (err %v)", stored.TwoFactorEnabled, err)
+			}
+		})
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	// RFC 6238 appendix B secret, truncated to the 6 digits the app uses
+	secret := base32NoPadding.EncodeToString([]byte("12345678901234567890"))
+	at := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		code   string
+		at     time.Time
+		wantOK bool
+	}{
+		{name: "current step", code: "287082", at: at, wantOK: true},
+		{name: "one step of drift", code: "287082", at: at.Add(totpPeriod * time.Second), wantOK: true},
+		{name: "two steps of drift", code: "287082", at: at.Add(2 * totpPeriod * time.Second), wantOK: false},
+		{name: "wrong code", code: "287083", at: at, wantOK: false},
+		{name: "wrong length", code: "28708", at: at, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := validateTOTP(secret, tt.code, tt.at); ok != tt.wantOK {
+				t.Fatalf("validateTOTP(%q) ok = %v, want %v", tt.code, ok, tt.wantOK)
+			}
+		})
+	}
+}
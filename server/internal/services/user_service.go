@@ -13,6 +13,7 @@ import (
 
 	"github.com/Bauka07/SocialApp/internal/config"
 	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/middleware"
 	"github.com/Bauka07/SocialApp/internal/models"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"golang.org/x/crypto/bcrypt"
@@ -30,6 +31,9 @@ func REH(user *models.User) error {
 	if len(user.Username) < 3 || len(user.Username) > 30 {
 		return fmt.Errorf("username must be between 3 and 30 characters")
 	}
+	if IsReservedUsername(user.Username) {
+		return fmt.Errorf("username already registered")
+	}
 
 	// Email validation
 	if user.Email == "" {
@@ -57,7 +61,7 @@ func REH(user *models.User) error {
 	return nil
 }
 
-func LEH(user *models.User) (*models.User, error) {
+func LEH(user *models.User, info LoginAttemptInfo) (*models.User, error) {
 	if user.Email == "" {
 		return nil, fmt.Errorf("Emails is required")
 	}
@@ -68,15 +72,45 @@ func LEH(user *models.User) (*models.User, error) {
 		return nil, fmt.Errorf("Invalid email format")
 	}
 	var existingUser models.User
+	found := true
 	if err := database.DB.Where("email = ?", user.Email).First(&existingUser).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("User not fount")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Database error: %v", err)
 		}
+		found = false
+	}
+
+	var account *models.User
+	if found {
+		account = &existingUser
+	}
+
+	if err := checkLoginAllowed(user.Email, info); err != nil {
+		recordLoginAttempt(account, user.Email, info, models.LoginBlocked)
+		return nil, err
+	}
 
-		return nil, fmt.Errorf("Database error: %v", err)
+	if !found {
+		// Spend the same time as a real password check so response timing doesn't reveal the account
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(user.Password))
+		recordLoginFailure(nil, user.Email, info, models.LoginInvalidCredentials)
+		return nil, ErrInvalidCredentials
 	}
-	if err := bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(user.Password)); err != nil {
-		return nil, fmt.Errorf("Invalid password")
+	if err := bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(user.Password)); err != nil || !existingUser.HasPassword {
+		recordLoginFailure(account, user.Email, info, models.LoginInvalidCredentials)
+		return nil, ErrInvalidCredentials
+	}
+	if err := middleware.CheckAccountStatus(&existingUser); err != nil {
+		recordLoginAttempt(account, user.Email, info, models.LoginAccountRestricted)
+		return nil, err
+	}
+	if !existingUser.EmailVerified {
+		recordLoginAttempt(account, user.Email, info, models.LoginEmailUnverified)
+		return nil, fmt.Errorf("Please verify your email before logging in")
+	}
+	if existingUser.TwoFactorEnabled {
+		// The login only succeeds once the second step is completed
+		recordLoginAttempt(account, user.Email, info, models.LoginSecondStepRequired)
 	}
 
 	return &existingUser, nil
@@ -110,7 +144,8 @@ func DeleteUserImage(publicID string) error {
 	return err
 }
 
-// UpdateUserProfile - Can update username OR email independently
+// UpdateUserProfile - Can update username OR email independently.
+// A new email is only stored as pending; see RequestEmailChange.
 func UpdateUserProfile(userID uint, newUsername, newEmail string) (*models.User, error) {
 	db := database.DB
 
@@ -127,9 +162,13 @@ func UpdateUserProfile(userID uint, newUsername, newEmail string) (*models.User,
 	newEmail = strings.TrimSpace(newEmail)
 
 	changed := false
+	pendingEmail := ""
 
 	// Update USERNAME if provided and different
 	if newUsername != "" && newUsername != user.Username {
+		if IsReservedUsername(newUsername) {
+			return nil, errors.New("username already taken")
+		}
 		// Check if username already exists (excluding current user)
 		var existing models.User
 		err := db.Where("username = ? AND id != ?", newUsername, userID).First(&existing).Error
@@ -162,26 +201,34 @@ func UpdateUserProfile(userID uint, newUsername, newEmail string) (*models.User,
 			// Some other database error
 			return nil, err
 		}
-		// Email is available
-		user.Email = newEmail
-		changed = true
+		// Email is available, but stays pending until the new address is confirmed
+		pendingEmail = newEmail
 	}
 
 	// Save only if something changed
-	if !changed {
+	if !changed && pendingEmail == "" {
 		return nil, errors.New("no changes to save")
 	}
 
-	if err := db.Save(&user).Error; err != nil {
-		return nil, errors.New("failed to save changes")
+	if changed {
+		if err := db.Save(&user).Error; err != nil {
+			return nil, errors.New("failed to save changes")
+		}
+	}
+
+	if pendingEmail != "" {
+		if err := RequestEmailChange(&user, pendingEmail); err != nil {
+			return nil, err
+		}
 	}
 
 	user.Password = "" // Hide password
 	return &user, nil
 }
 
-// UpdateUserPassword - Password change logic
-func UpdateUserPassword(userID uint, oldPassword, newPassword string) error {
+// UpdateUserPassword - Password change logic.
+// Revokes every session except currentSessionID so other devices must log in again.
+func UpdateUserPassword(userID, currentSessionID uint, oldPassword, newPassword string) error {
 	db := database.DB
 
 	var user models.User
@@ -219,6 +266,15 @@ func UpdateUserPassword(userID uint, oldPassword, newPassword string) error {
 		return errors.New("failed to update password")
 	}
 
+	if err := RevokeAllSessions(userID, currentSessionID); err != nil {
+		return err
+	}
+
+	// Scripts holding a token the old password's owner created lose access too
+	if err := RevokeAllAccessTokens(userID); err != nil {
+		return err
+	}
+
 	return nil
 }
 
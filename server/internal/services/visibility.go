@@ -0,0 +1,135 @@
+package services
+
+import (
+	"github.com/Bauka07/SocialApp/internal/database"
+	"github.com/Bauka07/SocialApp/internal/models"
+	"gorm.io/gorm"
+)
+
+// Query scopes deciding what a viewer gets to see. Content hidden by
+// moderators is left out for everyone; content by shadow-banned accounts is
+// left out for everyone but its author, who keeps seeing it as usual; posts
+// by private accounts are left out for anyone but approved followers; and
+// two accounts where one blocked the other don't see each other at all.
+// Each post's own visibility setting narrows its audience further.
+// viewerID is 0 for anonymous requests.
+
+// shadowBannedUserIDs selects the IDs of shadow-banned accounts
+func shadowBannedUserIDs() *gorm.DB {
+	return database.DB.Model(&models.User{}).Select("id").Where("shadow_banned_at IS NOT NULL")
+}
+
+// visibleAuthors leaves out rows whose column (e.g. "posts.user_id") points
+// at a shadow-banned account other than the viewer
+func visibleAuthors(viewerID uint, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("("+column+" = ? OR "+column+" NOT IN (?))", viewerID, shadowBannedUserIDs())
+	}
+}
+
+// notBlocked leaves out rows whose column points at an account the viewer
+// blocked or was blocked by
+func notBlocked(viewerID uint, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		blocked := database.DB.Model(&models.UserBlock{}).Select("blocked_id").Where("blocker_id = ?", viewerID)
+		blockedBy := database.DB.Model(&models.UserBlock{}).Select("blocker_id").Where("blocked_id = ?", viewerID)
+		return db.Where(column+" NOT IN (?) AND "+column+" NOT IN (?)", blocked, blockedBy)
+	}
+}
+
+// followersOnly leaves out rows whose column points at a private account
+// the viewer doesn't follow
+func followersOnly(viewerID uint, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		private := database.DB.Model(&models.User{}).Select("id").
+			Where("is_private = ? AND id <> ? AND id NOT IN (?)", true, viewerID, followedBy(viewerID))
+		return db.Where(column+" NOT IN (?)", private)
+	}
+}
+
+// followedBy selects the IDs of the accounts viewerID is an approved follower of
+func followedBy(viewerID uint) *gorm.DB {
+	return database.DB.Model(&models.Follow{}).Select("following_id").
+		Where("follower_id = ? AND status = ?", viewerID, models.FollowAccepted)
+}
+
+// postAudience leaves out posts whose visibility setting excludes the viewer:
+// followers posts for non-followers, close friends posts for anyone off the
+// author's list, and only-me posts for everyone but the author
+func postAudience(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		closeFriendOf := database.DB.Model(&models.CloseFriend{}).Select("user_id").Where("friend_id = ?", viewerID)
+		return db.Where("(posts.user_id = ? OR posts.visibility = ? OR "+
+			"(posts.visibility = ? AND posts.user_id IN (?)) OR "+
+			"(posts.visibility = ? AND posts.user_id IN (?)))",
+			viewerID, models.PostPublic,
+			models.PostFollowers, followedBy(viewerID),
+			models.PostCloseFriends, closeFriendOf)
+	}
+}
+
+// notMuted leaves out rows whose column points at an account the viewer muted
+func notMuted(viewerID uint, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == 0 {
+			return db
+		}
+		return db.Where(column+" NOT IN (?)",
+			database.DB.Model(&models.UserMute{}).Select("muted_id").Where("muter_id = ?", viewerID))
+	}
+}
+
+// visiblePosts leaves out posts hidden by moderators or held by a content
+// filter, written by shadow-banned accounts other than the viewer, by private
+// accounts the viewer doesn't follow, on the other side of a block, or not
+// shared with the viewer
+func visiblePosts(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = visibleAuthors(viewerID, "posts.user_id")(db.Where("posts.hidden_at IS NULL AND posts.held_at IS NULL"))
+		db = followersOnly(viewerID, "posts.user_id")(db)
+		db = postAudience(viewerID)(db)
+		return notBlocked(viewerID, "posts.user_id")(db)
+	}
+}
+
+// feedPosts is visiblePosts without the viewer's muted accounts
+func feedPosts(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return notMuted(viewerID, "posts.user_id")(visiblePosts(viewerID)(db))
+	}
+}
+
+// visibleComments is visiblePosts for comments, except that a comment held
+// by a content filter stays visible to its author
+func visibleComments(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("comments.hidden_at IS NULL AND (comments.held_at IS NULL OR comments.user_id = ?)", viewerID)
+		db = visibleAuthors(viewerID, "comments.user_id")(db)
+		return notBlocked(viewerID, "comments.user_id")(db)
+	}
+}
+
+// VisibleMessages leaves out messages the viewer received from shadow-banned
+// accounts. Held messages are filtered separately, since their senders
+// still see them.
+func VisibleMessages(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return visibleAuthors(viewerID, "messages.sender_id")
+}
+
+// VisibleUsers leaves shadow-banned accounts, and accounts on the other side
+// of a block, out of user search
+func VisibleUsers(viewerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return notBlocked(viewerID, "users.id")(visibleAuthors(viewerID, "users.id")(db))
+	}
+}
+
+// IsShadowBanned reports whether the account's activity is hidden from others
+func IsShadowBanned(userID uint) bool {
+	var count int64
+	database.DB.Model(&models.User{}).Where("id = ? AND shadow_banned_at IS NOT NULL", userID).Count(&count)
+	return count > 0
+}
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"time"
+)
+
+// accountEmailHTML wraps account emails in the same orange SocialApp layout as the reset email
+func accountEmailHTML(title, greeting, intro, highlight, footnote string) string {
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<title>%s - SocialApp</title>
+</head>
+<body style="margin:0;padding:40px 20px;background:linear-gradient(135deg,#f97316 0%%,#fb923c 100%%);font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,Arial,sans-serif;line-height:1.6;">
+	<div style="max-width:600px;margin:0 auto;background:white;border-radius:20px;overflow:hidden;box-shadow:0 20px 60px rgba(0,0,0,0.3);">
+		<div style="background:linear-gradient(135deg,#f97316 0%%,#fb923c 100%%);padding:40px;text-align:center;">
+			<h1 style="color:white;font-size:28px;font-weight:800;margin:0;">%s</h1>
+		</div>
+		<div style="padding:40px;color:#334155;">
+			<p style="font-size:18px;font-weight:600;margin:0 0 16px;">%s</p>
+			<p style="margin:0 0 24px;">%s</p>
+			%s
+			<p style="margin:24px 0 0;font-size:14px;color:#64748b;">%s</p>
+		</div>
+		<div style="background:#1e293b;padding:24px;text-align:center;">
+			<div style="color:white;font-size:18px;font-weight:700;">SocialApp</div>
+			<p style="color:#94a3b8;font-size:13px;margin:0;">This is an automated security email. Please do not reply.</p>
+		</div>
+	</div>
+</body>
+</html>
+	`, html.EscapeString(title), html.EscapeString(title), html.EscapeString(greeting),
+		html.EscapeString(intro), highlight, html.EscapeString(footnote))
+}
+
+// codeBlock renders a one-time code in large monospace type
+func codeBlock(code string) string {
+	return fmt.Sprintf(`<div style="text-align:center;background:#fff7ed;border:2px dashed #fb923c;border-radius:12px;padding:24px;font-size:36px;font-weight:800;letter-spacing:8px;color:#ea580c;font-family:monospace;">%s</div>`,
+		html.EscapeString(code))
+}
+
+// SendVerificationEmail sends the code that confirms ownership of an email address
+func SendVerificationEmail(to string, username string, code string) error {
+	log.Printf("📧 Sending verification email to: %s", to)
+
+	htmlBody := accountEmailHTML(
+		"Verify your email",
+		fmt.Sprintf("Hello %s,", username),
+		"Use the code below to confirm this email address for your SocialApp account.",
+		codeBlock(code),
+		"This code will expire in 24 hours. If you didn't request this, you can ignore this email.",
+	)
+
+	textBody := fmt.Sprintf(`
+Hello %s,
+
+Use the code below to confirm this email address for your SocialApp account:
+
+Verification Code: %s
+
+This code will expire in 24 hours.
+
+If you didn't request this, please ignore this email.
+
+Best regards,
+SocialApp Team
+	`, username, code)
+
+	if err := sendSMTP(to, "✉️ Verify your email - SocialApp", textBody, htmlBody); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Verification email sent successfully to: %s", to)
+	return nil
+}
+
+// SendAccountLockedEmail warns the owner that repeated failed logins locked their account
+func SendAccountLockedEmail(to string, username string, ip string, lockout time.Duration) error {
+	log.Printf("📧 Sending account locked email to: %s", to)
+
+	minutes := int(lockout.Minutes())
+
+	htmlBody := accountEmailHTML(
+		"Sign-in attempts blocked",
+		fmt.Sprintf("Hello %s,", username),
+		fmt.Sprintf("We noticed several failed attempts to sign in to your SocialApp account, most recently from IP address %s. Sign-in has been paused for %d minutes.", ip, minutes),
+		"",
+		"If this was you, wait and try again, or reset your password. If it wasn't, we recommend resetting your password and enabling two-factor authentication.",
+	)
+
+	textBody := fmt.Sprintf(`
+Hello %s,
+
+We noticed several failed attempts to sign in to your SocialApp account,
+most recently from IP address %s. Sign-in has been paused for %d minutes.
+
+If this was you, wait and try again, or reset your password.
+If it wasn't, we recommend resetting your password and enabling two-factor authentication.
+
+Best regards,
+SocialApp Team
+	`, username, ip, minutes)
+
+	if err := sendSMTP(to, "🔒 Sign-in attempts blocked - SocialApp", textBody, htmlBody); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Account locked email sent successfully to: %s", to)
+	return nil
+}
+
+// SendAccountWarningEmail tells a user a moderator found their content broke the rules
+func SendAccountWarningEmail(to string, username string, reason string, note string) error {
+	log.Printf("📧 Sending account warning email to: %s", to)
+
+	footnote := "Repeated violations can lead to your account being suspended or banned."
+
+	intro := fmt.Sprintf("A moderator reviewed a report about your activity on SocialApp and found it breaks our community rules (%s).", reason)
+	highlight := ""
+	textNote := ""
+	if note != "" {
+		highlight = fmt.Sprintf(`<div style="background:#fff7ed;border-left:4px solid #fb923c;border-radius:8px;padding:16px;color:#7c2d12;">%s</div>`,
+			html.EscapeString(note))
+		textNote = "\nModerator note: " + note + "\n"
+	}
+
+	htmlBody := accountEmailHTML(
+		"Community rules warning",
+		fmt.Sprintf("Hello %s,", username),
+		intro,
+		highlight,
+		footnote,
+	)
+
+	textBody := fmt.Sprintf(`
+Hello %s,
+
+%s
+%s
+%s
+
+Best regards,
+SocialApp Team
+	`, username, intro, textNote, footnote)
+
+	if err := sendSMTP(to, "⚠️ Community rules warning - SocialApp", textBody, htmlBody); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Account warning email sent successfully to: %s", to)
+	return nil
+}
+
+// SendReportResolvedEmail tells a reporter a moderator reviewed their report.
+// Which action was taken stays private; only whether it was actioned is shared.
+func SendReportResolvedEmail(to string, username string, targetType string, actioned bool) error {
+	log.Printf("📧 Sending report resolved email to: %s", to)
+
+	intro := fmt.Sprintf("A moderator reviewed the %s you reported and found it doesn't break our community rules.", targetType)
+	if actioned {
+		intro = fmt.Sprintf("A moderator reviewed the %s you reported and took action. Thank you for helping keep SocialApp safe.", targetType)
+	}
+	footnote := "You can see all your reports and their status in the app."
+
+	htmlBody := accountEmailHTML(
+		"Your report was reviewed",
+		fmt.Sprintf("Hello %s,", username),
+		intro,
+		"",
+		footnote,
+	)
+
+	textBody := fmt.Sprintf(`
+Hello %s,
+
+%s
+
+%s
+
+Best regards,
+SocialApp Team
+	`, username, intro, footnote)
+
+	if err := sendSMTP(to, "Your report was reviewed - SocialApp", textBody, htmlBody); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Report resolved email sent successfully to: %s", to)
+	return nil
+}
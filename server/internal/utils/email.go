@@ -18,35 +18,6 @@ import (
 func SendPasswordResetEmail(to string, username string, code string) error {
 	log.Printf("📧 Preparing to send email to: %s", to)
 
-	// Get SMTP credentials from environment
-	smtpHost := os.Getenv("SMTP_HOST")
-	smtpPortStr := os.Getenv("SMTP_PORT")
-	smtpUser := os.Getenv("SMTP_USER")
-	smtpPass := os.Getenv("SMTP_PASSWORD")
-
-	// Validate environment variables
-	if smtpHost == "" {
-		return fmt.Errorf("SMTP_HOST not set in environment")
-	}
-	if smtpUser == "" {
-		return fmt.Errorf("SMTP_USER not set in environment")
-	}
-	if smtpPass == "" {
-		return fmt.Errorf("SMTP_PASSWORD not set in environment")
-	}
-
-	// Parse SMTP port (default to 587 if not set or invalid)
-	smtpPort := 587
-	if smtpPortStr != "" {
-		if port, err := strconv.Atoi(smtpPortStr); err == nil {
-			smtpPort = port
-		} else {
-			log.Printf("⚠️ Invalid SMTP_PORT '%s', using default 587", smtpPortStr)
-		}
-	}
-
-	log.Printf("📧 SMTP Config - Host: %s, Port: %d, User: %s", smtpHost, smtpPort, smtpUser)
-
 	// HTML email body
 	htmlBody := fmt.Sprintf(`
 <!DOCTYPE html>
@@ -242,11 +213,50 @@ Best regards,
 SocialApp Team
 	`, username, code)
 
+	if err := sendSMTP(to, "🔐 Password Reset Code - SocialApp", textBody, htmlBody); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Password reset email sent successfully to: %s", to)
+	return nil
+}
+
+// sendSMTP delivers a multipart (text + HTML) email through the configured SMTP server
+func sendSMTP(to, subject, textBody, htmlBody string) error {
+	// Get SMTP credentials from environment
+	smtpHost := os.Getenv("SMTP_HOST")
+	smtpPortStr := os.Getenv("SMTP_PORT")
+	smtpUser := os.Getenv("SMTP_USER")
+	smtpPass := os.Getenv("SMTP_PASSWORD")
+
+	// Validate environment variables
+	if smtpHost == "" {
+		return fmt.Errorf("SMTP_HOST not set in environment")
+	}
+	if smtpUser == "" {
+		return fmt.Errorf("SMTP_USER not set in environment")
+	}
+	if smtpPass == "" {
+		return fmt.Errorf("SMTP_PASSWORD not set in environment")
+	}
+
+	// Parse SMTP port (default to 587 if not set or invalid)
+	smtpPort := 587
+	if smtpPortStr != "" {
+		if port, err := strconv.Atoi(smtpPortStr); err == nil {
+			smtpPort = port
+		} else {
+			log.Printf("⚠️ Invalid SMTP_PORT '%s', using default 587", smtpPortStr)
+		}
+	}
+
+	log.Printf("📧 SMTP Config - Host: %s, Port: %d, User: %s", smtpHost, smtpPort, smtpUser)
+
 	// Create email message
 	m := gomail.NewMessage()
 	m.SetHeader("From", smtpUser)
 	m.SetHeader("To", to)
-	m.SetHeader("Subject", "🔐 Password Reset Code - SocialApp")
+	m.SetHeader("Subject", subject)
 	m.SetBody("text/plain", textBody)
 	m.AddAlternative("text/html", htmlBody)
 
@@ -271,7 +281,6 @@ SocialApp Team
 		return fmt.Errorf("failed to send email via SMTP: %w", err)
 	}
 
-	log.Printf("✅ Password reset email sent successfully to: %s", to)
 	return nil
 }
 
@@ -3,10 +3,11 @@ package websocket
 import (
 	"encoding/json"
 	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/Bauka07/SocialApp/internal/database"
-	"github.com/Bauka07/SocialApp/internal/models"
+	"github.com/Bauka07/SocialApp/internal/services"
 	"github.com/gorilla/websocket"
 )
 
@@ -22,6 +23,20 @@ type Client struct {
 	conn   *websocket.Conn
 	send   chan []byte
 	UserID uint
+
+	// The hub can close send (e.g. DisconnectUser) while ReadPump is still
+	// answering frames, so every send and the close go through sendMu
+	sendMu sync.Mutex
+	closed bool
+
+	// Rate limiting
+	limits      RateLimitConfig
+	connLimiter *rateLimiter
+	userLimiter *rateLimiter
+
+	// Read at connect and kept current by Hub.SetShadowBanned, so typing
+	// frames don't hit the database
+	shadowBanned atomic.Bool
 }
 
 type WebSocketMessage struct {
@@ -32,11 +47,50 @@ type WebSocketMessage struct {
 }
 
 func NewClient(hub *Hub, conn *websocket.Conn, userID uint) *Client {
-	return &Client{
-		hub:    hub,
-		conn:   conn,
-		send:   make(chan []byte, 256),
-		UserID: userID,
+	limits := currentRateLimits()
+	client := &Client{
+		hub:         hub,
+		conn:        conn,
+		send:        make(chan []byte, 256),
+		UserID:      userID,
+		limits:      limits,
+		connLimiter: newRateLimiter(limits),
+		userLimiter: hub.userRateLimiter(userID),
+	}
+	client.shadowBanned.Store(services.IsShadowBanned(userID))
+	return client
+}
+
+// User returns the ID of the connected user
+func (c *Client) User() uint {
+	return c.UserID
+}
+
+// Send queues a message for the write pump without blocking. It returns
+// false once the client has been closed.
+func (c *Client) Send(message []byte) bool {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops the write pump, which then closes the connection
+func (c *Client) Close() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
 	}
 }
 
@@ -46,6 +100,7 @@ func (c *Client) ReadPump() {
 		c.conn.Close()
 	}()
 
+	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
@@ -61,12 +116,28 @@ func (c *Client) ReadPump() {
 			break
 		}
 
+		// Byte budget is charged before parsing so oversized junk is throttled too
+		size := float64(len(message))
+		if !c.connLimiter.bytes.allow(size) || !c.userLimiter.bytes.allow(size) {
+			if !c.rejectOverLimit("bytes") {
+				break
+			}
+			continue
+		}
+
 		var wsMsg WebSocketMessage
 		if err := json.Unmarshal(message, &wsMsg); err != nil {
 			log.Printf("Error unmarshaling message: %v", err)
 			continue
 		}
 
+		if !c.connLimiter.bucketFor(wsMsg.Type).allow(1) || !c.userLimiter.bucketFor(wsMsg.Type).allow(1) {
+			if !c.rejectOverLimit(wsMsg.Type) {
+				break
+			}
+			continue
+		}
+
 		switch wsMsg.Type {
 		case "send_message":
 			c.handleSendMessage(wsMsg)
@@ -80,7 +151,32 @@ func (c *Client) ReadPump() {
 	}
 }
 
+// rejectOverLimit answers an over-limit frame with an error and returns false
+// once the client has exceeded the violation budget and has been disconnected.
+func (c *Client) rejectOverLimit(kind string) bool {
+	log.Printf("⚠️ Rate limit exceeded by user %d (%s)", c.UserID, kind)
+
+	if c.userLimiter.violations.record(c.limits) {
+		log.Printf("❌ Disconnecting user %d: too many rate limit violations", c.UserID)
+		closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "rate limit exceeded")
+		c.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
+		return false
+	}
+
+	errorResponse := map[string]interface{}{
+		"type":  "error",
+		"error": "Rate limit exceeded, slow down",
+	}
+	errorJSON, _ := json.Marshal(errorResponse)
+	c.Send(errorJSON)
+	return true
+}
+
 func (c *Client) handleTyping(wsMsg WebSocketMessage) {
+	if c.shadowBanned.Load() || services.IsBlockedBetween(c.UserID, wsMsg.ReceiverID) {
+		return
+	}
+
 	response := map[string]interface{}{
 		"type":    "typing",
 		"user_id": c.UserID,
@@ -91,6 +187,10 @@ func (c *Client) handleTyping(wsMsg WebSocketMessage) {
 }
 
 func (c *Client) handleStopTyping(wsMsg WebSocketMessage) {
+	if c.shadowBanned.Load() || services.IsBlockedBetween(c.UserID, wsMsg.ReceiverID) {
+		return
+	}
+
 	response := map[string]interface{}{
 		"type":    "stop_typing",
 		"user_id": c.UserID,
@@ -142,106 +242,15 @@ func (c *Client) WritePump() {
 }
 
 func (c *Client) handleSendMessage(wsMsg WebSocketMessage) {
-	// FIXED: Add validation
-	if wsMsg.Content == "" {
-		log.Printf("❌ Empty message content from user %d", c.UserID)
-		return
-	}
-
-	if wsMsg.ReceiverID == 0 {
-		log.Printf("❌ Invalid receiver ID from user %d", c.UserID)
-		return
-	}
-
-	// FIXED: Prevent sending messages to self
-	if wsMsg.ReceiverID == c.UserID {
-		log.Printf("❌ User %d tried to send message to themselves", c.UserID)
-		return
-	}
-
-	message := models.Message{
-		Content:    wsMsg.Content,
-		SenderID:   c.UserID,
-		ReceiverID: wsMsg.ReceiverID,
-		IsRead:     false,
-		ReplyToID:  wsMsg.ReplyToID,
-	}
-
-	// If replying to a message, verify it exists and isn't deleted
-	if wsMsg.ReplyToID != nil {
-		var replyToMsg models.Message
-		if err := database.DB.First(&replyToMsg, *wsMsg.ReplyToID).Error; err != nil {
-			log.Printf("❌ Error finding reply message: %v", err)
-			return
-		}
-
-		// Check if message is deleted for either user
-		if (replyToMsg.SenderID == c.UserID && replyToMsg.DeletedForSender) ||
-			(replyToMsg.ReceiverID == c.UserID && replyToMsg.DeletedForReceiver) {
-			log.Printf("❌ Cannot reply to deleted message")
-			return
-		}
-
-		// Verify the reply message is part of this conversation
-		if !((replyToMsg.SenderID == c.UserID && replyToMsg.ReceiverID == wsMsg.ReceiverID) ||
-			(replyToMsg.SenderID == wsMsg.ReceiverID && replyToMsg.ReceiverID == c.UserID)) {
-			log.Printf("❌ Reply message not part of conversation")
-			return
-		}
-	}
-
-	// FIXED: Add transaction and better error handling
-	tx := database.DB.Begin()
-	if tx.Error != nil {
-		log.Printf("❌ Error starting transaction: %v", tx.Error)
-		return
-	}
-
-	if err := tx.Create(&message).Error; err != nil {
-		tx.Rollback()
-		log.Printf("❌ Error saving message: %v", err)
+	if _, err := services.SendMessage(c.hub, c.UserID, wsMsg.ReceiverID, wsMsg.Content, wsMsg.ReplyToID); err != nil {
+		log.Printf("❌ User %d failed to send message: %v", c.UserID, err)
 
 		// Send error to sender
 		errorResponse := map[string]interface{}{
 			"type":  "error",
-			"error": "Failed to send message",
+			"error": err.Error(),
 		}
 		errorJSON, _ := json.Marshal(errorResponse)
-		c.send <- errorJSON
-		return
-	}
-
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		log.Printf("❌ Error committing transaction: %v", err)
-		return
-	}
-
-	// Load relations (including reply_to)
-	database.DB.Preload("Sender").Preload("Receiver").Preload("ReplyTo").First(&message, message.ID)
-
-	log.Printf("✅ Message saved: ID=%d, From=%d, To=%d", message.ID, message.SenderID, message.ReceiverID)
-
-	// Prepare response
-	response := map[string]interface{}{
-		"type":    "new_message",
-		"message": message,
+		c.Send(errorJSON)
 	}
-
-	responseJSON, err := json.Marshal(response)
-	if err != nil {
-		log.Printf("❌ Error marshaling response: %v", err)
-		return
-	}
-
-	// Send to sender (confirmation)
-	select {
-	case c.send <- responseJSON:
-		log.Printf("✅ Confirmation sent to sender %d", c.UserID)
-	default:
-		log.Printf("⚠️ Could not send confirmation to sender %d (channel full)", c.UserID)
-	}
-
-	// Send to receiver if online
-	c.hub.SendToUser(wsMsg.ReceiverID, responseJSON)
 }
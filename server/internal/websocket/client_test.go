@@ -0,0 +1,22 @@
+package websocket
+
+import "testing"
+
+func TestClientSendAfterDisconnect(t *testing.T) {
+	h := NewHub()
+	client := &Client{UserID: 1, send: make(chan []byte, 1)}
+	h.clients[1] = map[Subscriber]bool{client: true}
+
+	// DisconnectUser closes the client from the hub while its ReadPump may
+	// still be answering frames
+	h.removeClient(client)
+
+	if client.Send([]byte(`{"type":"error"}`)) {
+		t.Fatal("send after close should report failure")
+	}
+	if !client.closed {
+		t.Fatal("client should be marked closed")
+	}
+	// ReadPump's own unregister closes again once it exits
+	client.Close()
+}
@@ -4,88 +4,117 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"time"
+
+	"github.com/Bauka07/SocialApp/internal/services"
 )
 
+// limiterIdleTTL is how long a user's rate limiter is kept after their last
+// connection closes. It is well past the time the buckets take to refill and
+// the violation window, so reconnecting never gets a user a fresh budget.
+const limiterIdleTTL = 10 * time.Minute
+
+// Subscriber is anything that can receive hub events for a user.
+// WebSocket clients and SSE streams both implement it.
+type Subscriber interface {
+	// User returns the ID of the user the subscriber belongs to
+	User() uint
+	// Send queues a message without blocking and reports whether it was queued
+	Send(message []byte) bool
+	// Close releases the subscriber; the hub calls it exactly once
+	Close()
+}
+
 type Hub struct {
-	// Registered clients (userID -> Client)
-	clients map[uint]*Client
+	// Registered subscribers (userID -> set of subscribers).
+	// A user may be connected from several tabs or transports at once.
+	clients map[uint]map[Subscriber]bool
 
 	// Register requests from clients
-	register chan *Client
+	register chan Subscriber
 
 	// Unregister requests from clients
-	unregister chan *Client
+	unregister chan Subscriber
 
 	// Broadcast messages to all clients
 	broadcast chan []byte
 
+	// Per-user rate limiters, shared by every connection of the same user
+	// and kept across reconnects until idle for limiterIdleTTL
+	limiters map[uint]*rateLimiter
+
 	// Mutex for thread-safe operations
 	mu sync.RWMutex
 }
 
 func NewHub() *Hub {
 	return &Hub{
-		clients:    make(map[uint]*Client),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		clients:    make(map[uint]map[Subscriber]bool),
+		register:   make(chan Subscriber),
+		unregister: make(chan Subscriber),
 		broadcast:  make(chan []byte),
+		limiters:   make(map[uint]*rateLimiter),
 	}
 }
 
 func (h *Hub) Run() {
+	sweep := time.NewTicker(limiterIdleTTL)
+	defer sweep.Stop()
+
 	for {
 		select {
 		case client := <-h.register:
+			userID := client.User()
 			h.mu.Lock()
-			h.clients[client.UserID] = client
+			firstConnection := len(h.clients[userID]) == 0
+			if h.clients[userID] == nil {
+				h.clients[userID] = make(map[Subscriber]bool)
+			}
+			h.clients[userID][client] = true
 			h.mu.Unlock()
-			log.Printf("✅ Client registered: UserID %d", client.UserID)
+			log.Printf("✅ Client registered: UserID %d", userID)
 
 			// Notify all clients that user is online
-			h.NotifyUserStatus(client.UserID, true)
+			if firstConnection {
+				h.NotifyUserStatus(userID, true)
+			}
 
 		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
-				close(client.send)
-			}
-			h.mu.Unlock()
-			log.Printf("❌ Client unregistered: UserID %d", client.UserID)
+			userID := client.User()
+			lastConnection := h.removeClient(client)
+			log.Printf("❌ Client unregistered: UserID %d", userID)
 
 			// Notify all clients that user is offline
-			h.NotifyUserStatus(client.UserID, false)
+			if lastConnection {
+				h.NotifyUserStatus(userID, false)
+			}
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
-			for _, client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client.UserID)
-				}
-			}
-			h.mu.RUnlock()
+			h.sendToAll(message)
+
+		case now := <-sweep.C:
+			h.sweepLimiters(now)
 		}
 	}
 }
 
-// SendToUser sends a message to a specific user
+// SendToUser sends a message to every connection of a specific user
 func (h *Hub) SendToUser(userID uint, message []byte) {
 	h.mu.RLock()
-	client, ok := h.clients[userID]
-	h.mu.RUnlock()
+	defer h.mu.RUnlock()
 
-	if ok {
-		select {
-		case client.send <- message:
+	subscribers, ok := h.clients[userID]
+	if !ok || len(subscribers) == 0 {
+		log.Printf("⚠️ User %d is offline, message not sent", userID)
+		return
+	}
+
+	for client := range subscribers {
+		if client.Send(message) {
 			log.Printf("✅ Message sent to user %d", userID)
-		default:
+		} else {
 			log.Printf("❌ Failed to send message to user %d (channel full)", userID)
 		}
-	} else {
-		log.Printf("⚠️ User %d is offline, message not sent", userID)
 	}
 }
 
@@ -93,11 +122,47 @@ func (h *Hub) SendToUser(userID uint, message []byte) {
 func (h *Hub) IsUserOnline(userID uint) bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	_, ok := h.clients[userID]
-	return ok
+	return len(h.clients[userID]) > 0
+}
+
+// removeClient closes and forgets a client, reporting whether it was the
+// user's last connection. The user's rate limiter stays (see sweepLimiters).
+func (h *Hub) removeClient(client Subscriber) bool {
+	userID := client.User()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[userID][client]; !ok {
+		return false
+	}
+	delete(h.clients[userID], client)
+	client.Close()
+
+	if len(h.clients[userID]) > 0 {
+		return false
+	}
+	delete(h.clients, userID)
+	if limiter, ok := h.limiters[userID]; ok {
+		limiter.lastUsed = time.Now()
+	}
+	return true
 }
 
-// NotifyUserStatus notifies all clients about a user's online status
+// sweepLimiters drops the rate limiters of users who have had no connection
+// open for limiterIdleTTL
+func (h *Hub) sweepLimiters(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for userID, limiter := range h.limiters {
+		if len(h.clients[userID]) == 0 && now.Sub(limiter.lastUsed) > limiterIdleTTL {
+			delete(h.limiters, userID)
+		}
+	}
+}
+
+// NotifyUserStatus notifies all clients about a user's online status, except
+// users on the other side of a block
 func (h *Hub) NotifyUserStatus(userID uint, online bool) {
 	message := map[string]interface{}{
 		"type":    "user_status",
@@ -105,7 +170,13 @@ func (h *Hub) NotifyUserStatus(userID uint, online bool) {
 		"online":  online,
 	}
 
-	h.BroadcastJSON(message)
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("❌ Error marshaling user status: %v", err)
+		return
+	}
+
+	h.sendToAllExcept(jsonData, services.BlockedUserIDs(userID))
 }
 
 // BroadcastJSON broadcasts a JSON message to all connected clients
@@ -116,25 +187,98 @@ func (h *Hub) BroadcastJSON(data interface{}) {
 		return
 	}
 
+	h.sendToAll(jsonData)
+}
+
+func (h *Hub) sendToAll(message []byte) {
+	h.sendToAllExcept(message, nil)
+}
+
+// sendToAllExcept sends a message to every connected user not in skip
+func (h *Hub) sendToAllExcept(message []byte, skip map[uint]bool) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	for userID, client := range h.clients {
-		select {
-		case client.send <- jsonData:
-			// Message sent successfully
-		default:
-			log.Printf("⚠️ Failed to broadcast to user %d (channel full)", userID)
+	for userID, subscribers := range h.clients {
+		if skip[userID] {
+			continue
+		}
+		for client := range subscribers {
+			if !client.Send(message) {
+				log.Printf("⚠️ Failed to broadcast to user %d (channel full)", userID)
+			}
 		}
 	}
 }
 
+// userRateLimiter returns the rate limiter for a user, creating it on first use.
+// The same limiter is handed to every connection and reconnection of the
+// user until sweepLimiters drops it.
+func (h *Hub) userRateLimiter(userID uint) *rateLimiter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	limiter, ok := h.limiters[userID]
+	if !ok {
+		limiter = newRateLimiter(currentRateLimits())
+		h.limiters[userID] = limiter
+	}
+	limiter.lastUsed = time.Now()
+	return limiter
+}
+
 // Register adds a client to the hub
-func (h *Hub) Register(client *Client) {
+func (h *Hub) Register(client Subscriber) {
 	h.register <- client
 }
 
 // Unregister removes a client from the hub
-func (h *Hub) Unregister(client *Client) {
+func (h *Hub) Unregister(client Subscriber) {
 	h.unregister <- client
 }
+
+// SetShadowBanned updates the cached shadow-ban flag on a user's open
+// WebSocket connections
+func (h *Hub) SetShadowBanned(userID uint, banned bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for subscriber := range h.clients[userID] {
+		if client, ok := subscriber.(*Client); ok {
+			client.shadowBanned.Store(banned)
+		}
+	}
+}
+
+// DisconnectUser closes every WebSocket and SSE connection of a user, after
+// sending them an event saying why (e.g. the account was suspended)
+func (h *Hub) DisconnectUser(userID uint, reason string) {
+	h.mu.RLock()
+	subscribers := make([]Subscriber, 0, len(h.clients[userID]))
+	for client := range h.clients[userID] {
+		subscribers = append(subscribers, client)
+	}
+	h.mu.RUnlock()
+
+	if len(subscribers) == 0 {
+		return
+	}
+
+	notice, err := json.Marshal(map[string]interface{}{
+		"type":   "disconnected",
+		"reason": reason,
+	})
+	if err != nil {
+		log.Printf("❌ Error marshaling disconnect notice: %v", err)
+	}
+
+	// Queued events are still written before the connection closes
+	for _, client := range subscribers {
+		if notice != nil {
+			client.Send(notice)
+		}
+		h.Unregister(client)
+	}
+
+	log.Printf("🔌 Disconnected %d connection(s) of user %d: %s", len(subscribers), userID, reason)
+}
@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetShadowBanned(t *testing.T) {
+	h := NewHub()
+	target := &Client{UserID: 1}
+	otherTab := &Client{UserID: 1}
+	bystander := &Client{UserID: 2}
+	h.clients[1] = map[Subscriber]bool{target: true, otherTab: true}
+	h.clients[2] = map[Subscriber]bool{bystander: true}
+
+	h.SetShadowBanned(1, true)
+	if !target.shadowBanned.Load() || !otherTab.shadowBanned.Load() {
+		t.Fatal("every connection of the user should be marked shadow-banned")
+	}
+	if bystander.shadowBanned.Load() {
+		t.Fatal("other users should not be marked shadow-banned")
+	}
+
+	h.SetShadowBanned(1, false)
+	if target.shadowBanned.Load() || otherTab.shadowBanned.Load() {
+		t.Fatal("lifting the shadow-ban should clear the flag")
+	}
+
+	// Users without open connections are a no-op
+	h.SetShadowBanned(3, true)
+}
+
+func TestLimiterSurvivesReconnect(t *testing.T) {
+	h := NewHub()
+	first := &Client{UserID: 1, send: make(chan []byte)}
+	second := &Client{UserID: 1, send: make(chan []byte)}
+	h.clients[1] = map[Subscriber]bool{first: true, second: true}
+	limiter := h.userRateLimiter(1)
+
+	if h.removeClient(first) {
+		t.Fatal("removing one of two connections should not be the last")
+	}
+	if !h.removeClient(second) {
+		t.Fatal("removing the only connection should be the last")
+	}
+	if _, ok := h.clients[1]; ok {
+		t.Fatal("user should be dropped with the last connection")
+	}
+
+	// A client that was already removed is ignored
+	if h.removeClient(second) {
+		t.Fatal("removing a client twice should not report a last connection")
+	}
+
+	if h.userRateLimiter(1) != limiter {
+		t.Fatal("reconnecting should get the same limiter back")
+	}
+}
+
+func TestSweepLimiters(t *testing.T) {
+	tests := []struct {
+		name      string
+		idle      time.Duration
+		connected bool
+		kept      bool
+	}{
+		{name: "recently disconnected", idle: time.Minute, kept: true},
+		{name: "idle past ttl", idle: limiterIdleTTL + time.Minute, kept: false},
+		{name: "connected and quiet", idle: limiterIdleTTL + time.Minute, connected: true, kept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub()
+			limiter := h.userRateLimiter(1)
+			limiter.lastUsed = time.Now().Add(-tt.idle)
+			if tt.connected {
+				h.clients[1] = map[Subscriber]bool{&Client{UserID: 1}: true}
+			}
+
+			h.sweepLimiters(time.Now())
+
+			if _, ok := h.limiters[1]; ok != tt.kept {
+				t.Fatalf("limiter kept = %v, want %v", ok, tt.kept)
+			}
+		})
+	}
+}
+
+func TestViolationsSurviveReconnect(t *testing.T) {
+	h := NewHub()
+	cfg := RateLimitConfig{MaxViolations: 2, ViolationWindow: time.Minute}
+
+	// Each connection records a violation against the shared user limiter
+	for i := 0; i < cfg.MaxViolations; i++ {
+		if h.userRateLimiter(1).violations.record(cfg) {
+			t.Fatalf("violation %d should still be within the budget", i+1)
+		}
+	}
+	if !h.userRateLimiter(1).violations.record(cfg) {
+		t.Fatal("a new connection should not reset the violation count")
+	}
+}
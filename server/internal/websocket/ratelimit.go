@@ -0,0 +1,184 @@
+package websocket
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig holds the per-user and per-connection thresholds for
+// inbound WebSocket traffic. A rate of 0 disables that particular limit.
+type RateLimitConfig struct {
+	MessagesPerSecond float64
+	MessageBurst      int
+	TypingPerSecond   float64
+	TypingBurst       int
+	BytesPerSecond    float64
+	ByteBurst         int
+
+	// Number of over-limit frames tolerated within ViolationWindow before
+	// the connection is closed.
+	MaxViolations   int
+	ViolationWindow time.Duration
+}
+
+var (
+	rateLimitOnce sync.Once
+	rateLimits    RateLimitConfig
+)
+
+// LoadRateLimitConfig reads WebSocket rate limits from environment variables
+func LoadRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		MessagesPerSecond: envFloat("WS_MESSAGES_PER_SECOND", 2),
+		MessageBurst:      envInt("WS_MESSAGE_BURST", 10),
+		TypingPerSecond:   envFloat("WS_TYPING_PER_SECOND", 5),
+		TypingBurst:       envInt("WS_TYPING_BURST", 20),
+		BytesPerSecond:    envFloat("WS_BYTES_PER_SECOND", 16*1024),
+		ByteBurst:         envInt("WS_BYTE_BURST", 64*1024),
+		MaxViolations:     envInt("WS_MAX_VIOLATIONS", 20),
+		ViolationWindow:   time.Duration(envInt("WS_VIOLATION_WINDOW_SECONDS", 60)) * time.Second,
+	}
+}
+
+// currentRateLimits loads the config lazily so values from .env are picked up
+// even though the hub itself is created before main runs.
+func currentRateLimits() RateLimitConfig {
+	rateLimitOnce.Do(func() {
+		rateLimits = LoadRateLimitConfig()
+		log.Printf("✅ WebSocket rate limits: %.1f msg/s (burst %d), %.1f typing/s (burst %d), %.0f B/s (burst %d)",
+			rateLimits.MessagesPerSecond, rateLimits.MessageBurst,
+			rateLimits.TypingPerSecond, rateLimits.TypingBurst,
+			rateLimits.BytesPerSecond, rateLimits.ByteBurst)
+	})
+	return rateLimits
+}
+
+func envFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			return f
+		}
+		log.Printf("⚠️ Invalid %s '%s', using default %v", key, v, def)
+	}
+	return def
+}
+
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i >= 0 {
+			return i
+		}
+		log.Printf("⚠️ Invalid %s '%s', using default %d", key, v, def)
+	}
+	return def
+}
+
+// tokenBucket is a simple thread-safe token bucket. A nil bucket allows everything.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	capacity := float64(burst)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &tokenBucket{
+		rate:     rate,
+		capacity: capacity,
+		tokens:   capacity,
+		last:     time.Now(),
+	}
+}
+
+// allow takes n tokens from the bucket if they are available
+func (b *tokenBucket) allow(n float64) bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	// Frames larger than the whole bucket can never pass, so cap the cost
+	// at capacity and let them through only when the bucket is full.
+	if n > b.capacity {
+		n = b.capacity
+	}
+	if b.tokens < n {
+		return false
+	}
+	b.tokens -= n
+	return true
+}
+
+// rateLimiter groups the buckets for one scope (a user or a connection)
+type rateLimiter struct {
+	messages *tokenBucket
+	typing   *tokenBucket
+	bytes    *tokenBucket
+
+	// Over-limit frames are counted per user, so reconnecting does not reset
+	// the count either
+	violations violationTracker
+
+	// When the user last connected or disconnected; guarded by the hub's mutex
+	lastUsed time.Time
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	return &rateLimiter{
+		messages: newTokenBucket(cfg.MessagesPerSecond, cfg.MessageBurst),
+		typing:   newTokenBucket(cfg.TypingPerSecond, cfg.TypingBurst),
+		bytes:    newTokenBucket(cfg.BytesPerSecond, cfg.ByteBurst),
+		lastUsed: time.Now(),
+	}
+}
+
+// bucketFor returns the event bucket used for a message type
+func (l *rateLimiter) bucketFor(msgType string) *tokenBucket {
+	switch msgType {
+	case "typing", "stop_typing":
+		return l.typing
+	default:
+		return l.messages
+	}
+}
+
+// violationTracker counts over-limit frames inside a sliding window
+type violationTracker struct {
+	mu          sync.Mutex
+	count       int
+	windowStart time.Time
+}
+
+// record registers a violation and reports whether the limit has been exceeded
+func (v *violationTracker) record(cfg RateLimitConfig) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(v.windowStart) > cfg.ViolationWindow {
+		v.windowStart = now
+		v.count = 0
+	}
+	v.count++
+	return cfg.MaxViolations > 0 && v.count > cfg.MaxViolations
+}
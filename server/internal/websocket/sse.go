@@ -0,0 +1,40 @@
+package websocket
+
+// SSEClient is a Server-Sent Events subscriber. It receives the same hub
+// events as a WebSocket client, but is read-only: sending goes through REST.
+type SSEClient struct {
+	UserID uint
+	events chan []byte
+}
+
+func NewSSEClient(userID uint) *SSEClient {
+	return &SSEClient{
+		UserID: userID,
+		events: make(chan []byte, 256),
+	}
+}
+
+// User returns the ID of the subscribed user
+func (s *SSEClient) User() uint {
+	return s.UserID
+}
+
+// Send queues an event for the stream without blocking
+func (s *SSEClient) Send(message []byte) bool {
+	select {
+	case s.events <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close ends the event stream
+func (s *SSEClient) Close() {
+	close(s.events)
+}
+
+// Events returns the channel the HTTP handler streams from
+func (s *SSEClient) Events() <-chan []byte {
+	return s.events
+}